@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
 	"regexp"
 	"strings"
 
@@ -22,11 +23,13 @@ import (
 )
 
 func NewChatCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "chat [message]",
 		Short: "Chat with Golem",
 		RunE:  runChat,
 	}
+	cmd.Flags().String("agent", config.DefaultAgent, "Agent profile to chat with")
+	return cmd
 }
 
 type (
@@ -90,6 +93,7 @@ type model struct {
 	err           error
 	loop          *agent.Loop
 	ctx           context.Context
+	approval      *approvalRequestMsg
 }
 
 func initialModel(ctx context.Context, loop *agent.Loop) model {
@@ -152,6 +156,10 @@ func (m model) Init() tea.Cmd {
 
 type responseMsg string
 
+// announceMsg is a message the agent sends on its own, such as a background
+// sub-agent result
+type announceMsg string
+
 type toolStartMsg struct {
 	name string
 	args string
@@ -169,6 +177,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		vpCmd tea.Cmd
 	)
 
+	if key, ok := msg.(tea.KeyMsg); ok && m.approval != nil {
+		return m.handleApprovalKey(key)
+	}
+
 	m.textarea, tiCmd = m.textarea.Update(msg)
 	m.viewport, vpCmd = m.viewport.Update(msg)
 
@@ -228,20 +240,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case responseMsg:
 		m.loading = false
-		content := string(msg)
-		var viewContent string
-		thinkRendered, mainRendered, hasThink := renderResponseParts(content, m.renderer)
-		if hasThink {
-			thinkRendered = indentLines(thinkRendered, "  ")
-			viewContent = "\n\n" + m.thinkingStyle.Render("💭 Thinking:\n"+thinkRendered) +
-				"\n\n" + m.aiStyle.Render("Golem: ") + mainRendered
-		} else {
-			viewContent = "\n\n" + m.aiStyle.Render("Golem: ") + mainRendered
-		}
+		m.appendResponse(string(msg))
 
-		m.history.WriteString(viewContent)
-		m.viewport.SetContent(m.history.String())
-		m.viewport.GotoBottom()
+	case announceMsg:
+		m.appendResponse(string(msg))
 
 	case toolStartMsg:
 		content := fmt.Sprintf("🛠️  Executing tool: %s\n", msg.name)
@@ -265,6 +267,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.SetContent(m.history.String())
 		m.viewport.GotoBottom()
 
+	case approvalRequestMsg:
+		m.approval = &msg
+		content := fmt.Sprintf("⚠️  Approval required: %s (%s)\n%s",
+			msg.req.Tool, msg.req.Reason, truncateApprovalArgs(msg.req.Args))
+		m.history.WriteString("\n" + m.toolStyle.Render(content))
+		m.viewport.SetContent(m.history.String())
+		m.viewport.GotoBottom()
+
+	case approvalExpiredMsg:
+		if m.approval != nil && m.approval.req.ID == msg.id {
+			m.approval = nil
+			m.history.WriteString("\n" + m.toolStyle.Render("⌛ Approval timed out"))
+			m.viewport.SetContent(m.history.String())
+			m.viewport.GotoBottom()
+		}
+
 	case errMsg:
 		m.loading = false
 		m.err = msg
@@ -274,12 +292,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(tiCmd, vpCmd)
 }
 
+func (m *model) appendResponse(content string) {
+	var viewContent string
+	thinkRendered, mainRendered, hasThink := renderResponseParts(content, m.renderer)
+	if hasThink {
+		thinkRendered = indentLines(thinkRendered, "  ")
+		viewContent = "\n\n" + m.thinkingStyle.Render("💭 Thinking:\n"+thinkRendered) +
+			"\n\n" + m.aiStyle.Render("Golem: ") + mainRendered
+	} else {
+		viewContent = "\n\n" + m.aiStyle.Render("Golem: ") + mainRendered
+	}
+
+	m.history.WriteString(viewContent)
+	m.viewport.SetContent(m.history.String())
+	m.viewport.GotoBottom()
+}
+
 func (m model) View() string {
 	var spinnerView string
 	if m.loading {
 		spinnerView = m.spinner.View() + " Thinking..."
 	}
 	helpView := m.helpStyle.Render("  Esc: quit • Enter: send")
+	if m.approval != nil {
+		helpView = m.helpStyle.Render("  y: approve • n: deny • a: always allow")
+	}
 	return fmt.Sprintf(
 		"%s\n%s\n%s\n%s",
 		m.viewport.View(),
@@ -295,6 +332,12 @@ func runChat(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if cmd != nil && cmd.Flags().Changed("agent") {
+		name, _ := cmd.Flags().GetString("agent")
+		if cfg, err = cfg.ForAgent(name); err != nil {
+			return err
+		}
+	}
 
 	// Disable logging for TUI
 	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
@@ -313,8 +356,10 @@ func runChat(cmd *cobra.Command, args []string) error {
 	if err := loop.RegisterDefaultTools(cfg); err != nil {
 		return fmt.Errorf("failed to register tools: %w", err)
 	}
+	defer loop.Close()
 
 	if len(args) > 0 {
+		loop.SetApprover("cli", &promptApprover{in: os.Stdin, out: os.Stdout})
 		message := strings.Join(args, " ")
 		resp, err := loop.ProcessDirect(ctx, message)
 		if err != nil {
@@ -327,12 +372,18 @@ func runChat(cmd *cobra.Command, args []string) error {
 	p := tea.NewProgram(initialModel(ctx, loop), tea.WithAltScreen())
 
 	// Set callbacks
+	loop.SetApprover("cli", &tuiApprover{program: p})
 	loop.OnToolStart = func(name, args string) {
 		p.Send(toolStartMsg{name: name, args: args})
 	}
 	loop.OnToolFinish = func(name, result string, err error) {
 		p.Send(toolFinishMsg{name: name, result: result, err: err})
 	}
+	go func() {
+		for msg := range msgBus.Outbound() {
+			p.Send(announceMsg(msg.Content))
+		}
+	}()
 
 	if _, err := p.Run(); err != nil {
 		return err
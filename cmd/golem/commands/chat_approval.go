@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/MEKXH/golem/internal/tools"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxApprovalArgsView = 300
+
+type approvalRequestMsg struct {
+	req   *tools.ApprovalRequest
+	reply chan tools.ApprovalDecision
+}
+
+type approvalExpiredMsg struct {
+	id string
+}
+
+// tuiApprover shows approval prompts in the chat UI
+type tuiApprover struct {
+	program *tea.Program
+}
+
+func (a *tuiApprover) RequestApproval(ctx context.Context, req *tools.ApprovalRequest) (tools.ApprovalDecision, error) {
+	reply := make(chan tools.ApprovalDecision, 1)
+	a.program.Send(approvalRequestMsg{req: req, reply: reply})
+	select {
+	case d := <-reply:
+		return d, nil
+	case <-ctx.Done():
+		a.program.Send(approvalExpiredMsg{id: req.ID})
+		return tools.ApprovalDenied, ctx.Err()
+	}
+}
+
+// promptApprover asks on the terminal, used for one-shot chat messages
+type promptApprover struct {
+	in  io.Reader
+	out io.Writer
+}
+
+func (a *promptApprover) RequestApproval(ctx context.Context, req *tools.ApprovalRequest) (tools.ApprovalDecision, error) {
+	fmt.Fprintf(a.out, "\nApproval required: %s (%s)\n%s\nApprove? [y]es / [n]o / [a]lways: ",
+		req.Tool, req.Reason, truncateApprovalArgs(req.Args))
+
+	answer := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(a.in).ReadString('\n')
+		answer <- line
+	}()
+
+	select {
+	case line := <-answer:
+		return parseApprovalAnswer(line), nil
+	case <-ctx.Done():
+		fmt.Fprintln(a.out, "\nApproval timed out.")
+		return tools.ApprovalDenied, ctx.Err()
+	}
+}
+
+func parseApprovalAnswer(s string) tools.ApprovalDecision {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "y", "yes":
+		return tools.ApprovalApproved
+	case "a", "always":
+		return tools.ApprovalAlwaysAllow
+	default:
+		return tools.ApprovalDenied
+	}
+}
+
+func truncateApprovalArgs(args string) string {
+	if len(args) > maxApprovalArgsView {
+		return args[:maxApprovalArgsView] + "..."
+	}
+	return args
+}
+
+func (m model) handleApprovalKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var decision tools.ApprovalDecision
+	switch key.String() {
+	case "y", "Y":
+		decision = tools.ApprovalApproved
+	case "a", "A":
+		decision = tools.ApprovalAlwaysAllow
+	case "n", "N":
+		decision = tools.ApprovalDenied
+	case "ctrl+c", "esc":
+		m.approval.reply <- tools.ApprovalDenied
+		m.approval = nil
+		return m, tea.Quit
+	default:
+		return m, nil
+	}
+
+	m.approval.reply <- decision
+	m.approval = nil
+
+	label := "❌ Denied"
+	switch decision {
+	case tools.ApprovalApproved:
+		label = "✅ Approved"
+	case tools.ApprovalAlwaysAllow:
+		label = "✅ Always allowed for this rule in this session"
+	}
+	m.history.WriteString("\n" + m.toolStyle.Render(label))
+	m.viewport.SetContent(m.history.String())
+	m.viewport.GotoBottom()
+	return m, nil
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/tools"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestChatModel_ApprovalPrompt(t *testing.T) {
+	m := initialModel(context.Background(), nil)
+	reply := make(chan tools.ApprovalDecision, 1)
+
+	updated, _ := m.Update(approvalRequestMsg{
+		req:   &tools.ApprovalRequest{ID: "1", Tool: "exec", Args: `{"command":"ls"}`, Reason: "tool exec requires approval"},
+		reply: reply,
+	})
+	m = updated.(model)
+	if m.approval == nil {
+		t.Fatal("expected pending approval")
+	}
+	if !strings.Contains(m.View(), "y: approve") {
+		t.Fatalf("expected approval help, got: %s", m.View())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m = updated.(model)
+	if m.approval == nil {
+		t.Fatal("unrelated key must not resolve approval")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = updated.(model)
+	if m.approval != nil {
+		t.Fatal("expected approval resolved")
+	}
+	if got := <-reply; got != tools.ApprovalAlwaysAllow {
+		t.Fatalf("expected always allow, got %v", got)
+	}
+	if m.textarea.Value() != "" {
+		t.Fatalf("approval keys must not reach the input, got %q", m.textarea.Value())
+	}
+}
+
+func TestPromptApprover_ParsesAnswer(t *testing.T) {
+	var out bytes.Buffer
+	a := &promptApprover{in: strings.NewReader("yes\n"), out: &out}
+
+	got, err := a.RequestApproval(context.Background(), &tools.ApprovalRequest{Tool: "exec", Args: "{}"})
+	if err != nil {
+		t.Fatalf("RequestApproval error: %v", err)
+	}
+	if got != tools.ApprovalApproved {
+		t.Fatalf("expected approved, got %v", got)
+	}
+	if !strings.Contains(out.String(), "Approval required: exec") {
+		t.Fatalf("expected prompt, got: %s", out.String())
+	}
+	if parseApprovalAnswer("") != tools.ApprovalDenied {
+		t.Fatal("expected empty answer to deny")
+	}
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/cron"
+    "github.com/spf13/cobra"
+)
+
+func NewCronCmd() *cobra.Command {
+    cmd := &cobra.Command{
+        Use:   "cron",
+        Short: "Manage scheduled tasks",
+        Long:  "Manage scheduled tasks. Tasks fire while 'golem run' is running and are delivered to their chat.",
+    }
+
+    var job cron.Job
+    var in string
+    add := &cobra.Command{
+        Use:   "add <message>",
+        Short: "Schedule a task",
+        Args:  cobra.MinimumNArgs(1),
+        RunE: func(cmd *cobra.Command, args []string) error {
+            job.Message = strings.Join(args, " ")
+            return runCronAdd(job, in)
+        },
+    }
+    add.Flags().StringVar(&job.Channel, "channel", "telegram", "Channel to deliver to")
+    add.Flags().StringVar(&job.ChatID, "chat", "", "Chat ID to deliver to")
+    add.Flags().StringVar(&job.Name, "name", "", "Short label")
+    add.Flags().StringVar(&job.Cron, "cron", "", "Cron expression, e.g. \"0 9 * * 1-5\"")
+    add.Flags().StringVar(&job.At, "at", "", "One-shot time, RFC 3339 or \"YYYY-MM-DD HH:MM\"")
+    add.Flags().StringVar(&in, "in", "", "One-shot delay from now, e.g. 20m")
+    add.Flags().StringVar(&job.Every, "every", "", "Repeat interval, e.g. 1h")
+    add.Flags().StringVar(&job.Timezone, "tz", "", "IANA timezone (defaults to cron.timezone)")
+    _ = add.MarkFlagRequired("chat")
+
+    cmd.AddCommand(
+        &cobra.Command{
+            Use:   "list",
+            Short: "List scheduled tasks",
+            Args:  cobra.NoArgs,
+            RunE:  runCronList,
+        },
+        add,
+        &cobra.Command{
+            Use:   "remove <id>",
+            Short: "Cancel a scheduled task",
+            Args:  cobra.ExactArgs(1),
+            RunE:  runCronRemove,
+        },
+    )
+    return cmd
+}
+
+func cronStore() (*cron.Store, *config.Config, error) {
+    cfg, err := config.Load()
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to load config: %w", err)
+    }
+    workspacePath, err := cfg.WorkspacePathChecked()
+    if err != nil {
+        return nil, nil, fmt.Errorf("invalid workspace: %w", err)
+    }
+    return cron.NewStore(workspacePath), cfg, nil
+}
+
+func runCronList(cmd *cobra.Command, args []string) error {
+    store, _, err := cronStore()
+    if err != nil {
+        return err
+    }
+    jobs, err := store.List()
+    if err != nil {
+        return err
+    }
+    if len(jobs) == 0 {
+        fmt.Println("No scheduled tasks")
+        return nil
+    }
+    for _, j := range jobs {
+        name := ""
+        if j.Name != "" {
+            name = " " + j.Name
+        }
+        fmt.Printf("%s%s [%s] %s, next %s: %s\n",
+            j.ID, name, j.SessionKey(), j.Describe(), j.NextRun.Format(time.DateTime), j.Message)
+    }
+    return nil
+}
+
+func runCronAdd(job cron.Job, in string) error {
+    store, cfg, err := cronStore()
+    if err != nil {
+        return err
+    }
+    if job.Timezone == "" {
+        job.Timezone = cfg.Cron.Timezone
+    }
+    if in != "" {
+        if job.At != "" {
+            return fmt.Errorf("use either --at or --in")
+        }
+        if job.At, err = cron.AtDelay(in, time.Now()); err != nil {
+            return err
+        }
+    }
+    added, err := store.Add(job)
+    if err != nil {
+        return err
+    }
+    fmt.Printf("Scheduled %s (%s), next run %s\n", added.ID, added.Describe(), added.NextRun.Format(time.DateTime))
+    if !cfg.Cron.Enabled {
+        fmt.Println("Scheduled tasks are disabled; set cron.enabled for 'golem run' to fire them.")
+    }
+    return nil
+}
+
+func runCronRemove(cmd *cobra.Command, args []string) error {
+    store, _, err := cronStore()
+    if err != nil {
+        return err
+    }
+    job, err := store.Remove(args[0], "")
+    if err != nil {
+        return err
+    }
+    fmt.Printf("Removed %s: %s\n", job.ID, job.Message)
+    return nil
+}
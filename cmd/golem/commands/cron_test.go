@@ -0,0 +1,51 @@
+package commands
+
+import (
+    "strings"
+    "testing"
+
+    "github.com/MEKXH/golem/internal/cron"
+)
+
+func TestCronCommands_AddListRemove(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    var id string
+    output := captureOutput(t, func() {
+        if err := runCronList(nil, nil); err != nil {
+            t.Fatalf("runCronList error: %v", err)
+        }
+        job := cron.Job{Message: "Post the weekly report", Channel: "telegram", ChatID: "99", Cron: "0 9 * * mon", Timezone: "UTC"}
+        if err := runCronAdd(job, ""); err != nil {
+            t.Fatalf("runCronAdd error: %v", err)
+        }
+        if err := runCronAdd(cron.Job{Message: "x", Channel: "telegram", ChatID: "99"}, "5m"); err != nil {
+            t.Fatalf("runCronAdd error: %v", err)
+        }
+        store, _, _ := cronStore()
+        jobs, _ := store.List()
+        id = jobs[len(jobs)-1].ID
+        if err := runCronList(nil, nil); err != nil {
+            t.Fatalf("runCronList error: %v", err)
+        }
+        if err := runCronRemove(nil, []string{id}); err != nil {
+            t.Fatalf("runCronRemove error: %v", err)
+        }
+    })
+
+    for _, want := range []string{
+        "No scheduled tasks",
+        "Scheduled ",
+        "[telegram:99] cron 0 9 * * mon (UTC)",
+        "Removed " + id + ": Post the weekly report",
+    } {
+        if !strings.Contains(output, want) {
+            t.Fatalf("expected %q in output, got: %s", want, output)
+        }
+    }
+    if err := runCronAdd(cron.Job{Message: "x", Channel: "telegram", ChatID: "99", At: "2030-01-01 09:00"}, "5m"); err == nil {
+        t.Fatal("expected --at with --in to fail")
+    }
+}
@@ -50,10 +50,11 @@ func runInit(cmd *cobra.Command, args []string) error {
     }
 
     workspaceFiles := map[string]string{
-        "IDENTITY.md": "# Identity\n\nYou are Golem, a helpful AI assistant.",
-        "SOUL.md":     "# Soul\n\nBe helpful, concise, and proactive.",
-        "USER.md":     "# User\n\nInformation about the user goes here.",
-        "AGENTS.md":   "# Agents\n\nAgent-specific instructions go here.",
+        "IDENTITY.md":  "# Identity\n\nYou are Golem, a helpful AI assistant.",
+        "SOUL.md":      "# Soul\n\nBe helpful, concise, and proactive.",
+        "USER.md":      "# User\n\nInformation about the user goes here.",
+        "AGENTS.md":    "# Agents\n\nAgent-specific instructions go here.",
+        "HEARTBEAT.md": "# Heartbeat\n\n<!-- Standing tasks checked on every heartbeat (heartbeat.enabled), e.g.\n- Check logs/app.log for new errors\n- Summarize new files in inbox/\n-->\n",
     }
 
     for name, content := range workspaceFiles {
@@ -0,0 +1,88 @@
+package commands
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "log/slog"
+    "os"
+    "os/signal"
+    "syscall"
+
+    "github.com/MEKXH/golem/internal/agent"
+    "github.com/MEKXH/golem/internal/bus"
+    "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/provider"
+    "github.com/spf13/cobra"
+)
+
+func NewMCPCmd() *cobra.Command {
+    cmd := &cobra.Command{
+        Use:   "mcp",
+        Short: "Model Context Protocol integration",
+    }
+    serve := &cobra.Command{
+        Use:   "serve",
+        Short: "Serve Golem's tools and an ask_golem tool to MCP clients over stdio",
+        Args:  cobra.NoArgs,
+        RunE:  runMCPServe,
+    }
+    serve.Flags().String("agent", config.DefaultAgent, "Agent profile to serve")
+    cmd.AddCommand(serve)
+    return cmd
+}
+
+func runMCPServe(cmd *cobra.Command, args []string) error {
+    ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+    defer cancel()
+
+    // stdout carries the protocol, so logs go to stderr
+    slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+    cfg, err := config.Load()
+    if err != nil {
+        return fmt.Errorf("failed to load config: %w", err)
+    }
+    if cmd != nil && cmd.Flags().Changed("agent") {
+        name, _ := cmd.Flags().GetString("agent")
+        if cfg, err = cfg.ForAgent(name); err != nil {
+            return err
+        }
+    }
+    return serveMCP(ctx, cfg, os.Stdin, os.Stdout)
+}
+
+// serveMCP runs the MCP server on r and w until r ends
+func serveMCP(ctx context.Context, cfg *config.Config, r io.Reader, w io.Writer) error {
+    model, err := provider.NewChatModel(ctx, cfg)
+    if err != nil {
+        slog.Warn("no model configured, ask_golem will not answer", "error", err)
+    }
+    msgBus := bus.NewMessageBus(10)
+    loop, err := agent.NewLoop(cfg, msgBus, model)
+    if err != nil {
+        return fmt.Errorf("invalid workspace: %w", err)
+    }
+    defer loop.Close()
+    if err := loop.RegisterDefaultTools(cfg); err != nil {
+        return err
+    }
+
+    // Nobody is listening for background results here, so they are only logged
+    go func() {
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case msg := <-msgBus.Outbound():
+                slog.Info("undelivered message", "chat", msg.ChatID, "content", msg.Content)
+            }
+        }
+    }()
+
+    server, err := loop.MCPServer(ctx)
+    if err != nil {
+        return err
+    }
+    return server.ServeStdio(ctx, r, w)
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+
+    "github.com/MEKXH/golem/internal/config"
+)
+
+func TestServeMCP_AnswersOverStdio(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    input := strings.Join([]string{
+        `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+        `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+        `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+        "",
+    }, "\n")
+    var out bytes.Buffer
+    if err := serveMCP(context.Background(), config.DefaultConfig(), strings.NewReader(input), &out); err != nil {
+        t.Fatalf("serveMCP error: %v", err)
+    }
+
+    lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+    if len(lines) != 2 {
+        t.Fatalf("expected two replies, got:\n%s", out.String())
+    }
+    for _, want := range []string{`"serverInfo":{"name":"golem"`, `"name":"ask_golem"`, `"name":"read_file"`} {
+        if !strings.Contains(out.String(), want) {
+            t.Errorf("expected %q in output:\n%s", want, out.String())
+        }
+    }
+}
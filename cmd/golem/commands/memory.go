@@ -0,0 +1,60 @@
+package commands
+
+import (
+    "context"
+    "fmt"
+    "os/signal"
+    "syscall"
+
+    "github.com/MEKXH/golem/internal/agent"
+    "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/session"
+    "github.com/spf13/cobra"
+)
+
+func NewMemoryCmd() *cobra.Command {
+    cmd := &cobra.Command{
+        Use:   "memory",
+        Short: "Manage long-term memory",
+    }
+    cmd.AddCommand(&cobra.Command{
+        Use:   "rebuild",
+        Short: "Re-embed memory, notes and sessions into the semantic index",
+        Args:  cobra.NoArgs,
+        RunE:  runMemoryRebuild,
+    })
+    return cmd
+}
+
+func runMemoryRebuild(cmd *cobra.Command, args []string) error {
+    ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+    defer cancel()
+
+    cfg, err := config.Load()
+    if err != nil {
+        return fmt.Errorf("failed to load config: %w", err)
+    }
+    workspacePath, err := cfg.WorkspacePathChecked()
+    if err != nil {
+        return fmt.Errorf("invalid workspace: %w", err)
+    }
+
+    store, err := session.OpenStore(cfg.Sessions.Store, workspacePath)
+    if err != nil {
+        return err
+    }
+    defer store.Close()
+    idx, err := agent.NewMemoryIndex(cfg, workspacePath, store)
+    if err != nil {
+        return err
+    }
+    if err := idx.Rebuild(ctx); err != nil {
+        return fmt.Errorf("rebuild failed: %w", err)
+    }
+
+    fmt.Printf("Indexed %d items into %s\n", idx.Len(), idx.Path())
+    if !cfg.Memory.Semantic.Enabled {
+        fmt.Println("Semantic memory is disabled; set memory.semantic.enabled to use the index.")
+    }
+    return nil
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/memory"
+)
+
+func TestMemoryRebuild_IndexesWorkspace(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    configPath := config.ConfigPath()
+    if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+        t.Fatalf("MkdirAll: %v", err)
+    }
+    raw := `{"memory": {"semantic": {"enabled": true, "provider": "hash"}}}`
+    if err := os.WriteFile(configPath, []byte(raw), 0644); err != nil {
+        t.Fatalf("WriteFile: %v", err)
+    }
+    cfg, _ := config.Load()
+    store := memory.NewStore(filepath.Join(cfg.WorkspacePath(), "memory"))
+    if _, err := store.Save("Likes green tea", nil); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := store.Save("Lives in Porto", nil); err != nil {
+        t.Fatal(err)
+    }
+
+    output := captureOutput(t, func() {
+        if err := runMemoryRebuild(nil, nil); err != nil {
+            t.Fatalf("runMemoryRebuild error: %v", err)
+        }
+    })
+    if !strings.Contains(output, "Indexed 2 items") {
+        t.Fatalf("expected both entries indexed, got: %s", output)
+    }
+    if _, err := os.Stat(filepath.Join(cfg.WorkspacePath(), filepath.FromSlash(memory.IndexFile))); err != nil {
+        t.Fatalf("expected index file: %v", err)
+    }
+}
@@ -17,6 +17,12 @@ func NewRootCmd() *cobra.Command {
         NewChatCmd(),
         NewRunCmd(),
         NewStatusCmd(),
+        NewMemoryCmd(),
+        NewSkillsCmd(),
+        NewCronCmd(),
+        NewToolsCmd(),
+        NewMCPCmd(),
+        NewSessionsCmd(),
     )
 
     return cmd
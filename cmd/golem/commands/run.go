@@ -6,12 +6,16 @@ import (
     "log/slog"
     "os/signal"
     "syscall"
+    "time"
 
     "github.com/MEKXH/golem/internal/agent"
     "github.com/MEKXH/golem/internal/bus"
     "github.com/MEKXH/golem/internal/channel"
     "github.com/MEKXH/golem/internal/channel/telegram"
     "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/cron"
+    "github.com/MEKXH/golem/internal/gateway"
+    "github.com/MEKXH/golem/internal/heartbeat"
     "github.com/MEKXH/golem/internal/provider"
     "github.com/spf13/cobra"
 )
@@ -25,7 +29,7 @@ func NewRunCmd() *cobra.Command {
         RunE:  runServer,
     }
 
-    cmd.Flags().IntVarP(&port, "port", "p", 18790, "Server port")
+    cmd.Flags().IntVarP(&port, "port", "p", 18790, "Gateway port (overrides gateway.port)")
     return cmd
 }
 
@@ -37,39 +41,170 @@ func runServer(cmd *cobra.Command, args []string) error {
     if err != nil {
         return fmt.Errorf("failed to load config: %w", err)
     }
+    if cmd != nil && cmd.Flags().Changed("port") {
+        cfg.Gateway.Port, _ = cmd.Flags().GetInt("port")
+    }
 
     msgBus := bus.NewMessageBus(100)
 
-    model, err := provider.NewChatModel(ctx, cfg)
-    if err != nil {
-        slog.Warn("no model configured", "error", err)
+    loops, err := newAgents(ctx, cfg, msgBus)
+    for _, l := range loops {
+        defer l.Close()
     }
-
-    loop, err := agent.NewLoop(cfg, msgBus, model)
     if err != nil {
-        return fmt.Errorf("invalid workspace: %w", err)
+        return err
     }
-    if err := loop.RegisterDefaultTools(cfg); err != nil {
+    router, err := agent.NewRouter(msgBus, loops, agentRoutes(cfg))
+    if err != nil {
         return err
     }
-    go loop.Run(ctx)
+    routerDone := make(chan struct{})
+    go func() {
+        defer close(routerDone)
+        if err := router.Run(ctx); err != nil && ctx.Err() == nil {
+            slog.Error("agent router error", "error", err)
+        }
+    }()
 
     chanMgr := channel.NewManager(msgBus)
+    gw := gateway.New(&cfg.Gateway)
+    gatewayNeeded := cfg.Gateway.Enabled
 
     if cfg.Channels.Telegram.Enabled {
         tg := telegram.New(&cfg.Channels.Telegram, msgBus)
         chanMgr.Register(tg)
+        for _, l := range loops {
+            l.SetApprover(tg.Name(), tg)
+        }
+        if tg.WebhookEnabled() {
+            gw.Handle(tg.WebhookPath(), tg)
+            gatewayNeeded = true
+        }
+    }
+
+    // Bind before channels start so a webhook is never registered for a
+    // port nothing listens on
+    if gatewayNeeded {
+        if err := startGateway(ctx, gw); err != nil {
+            return err
+        }
     }
 
     chanMgr.StartAll(ctx)
     go chanMgr.RouteOutbound(ctx)
 
+    if cfg.Cron.Enabled {
+        // One scheduler per workspace; jobs name the agent that scheduled them
+        started := make(map[string]bool)
+        for _, l := range loops {
+            if started[l.WorkspacePath()] {
+                continue
+            }
+            started[l.WorkspacePath()] = true
+            scheduler := cron.NewScheduler(cron.NewStore(l.WorkspacePath()), func(job cron.Job) {
+                msgBus.PublishInbound(job.Inbound())
+            })
+            go scheduler.Run(ctx)
+        }
+    }
+
+    if cfg.Heartbeat.Enabled && cfg.Heartbeat.Interval > 0 {
+        go newHeartbeat(cfg, loops[config.DefaultAgent], msgBus).Run(ctx)
+    }
+
     fmt.Printf("Golem server running. Press Ctrl+C to stop.\n")
 
     <-ctx.Done()
 
     slog.Info("shutting down")
     chanMgr.StopAll(context.Background())
+    // Let agents finish their turns before the deferred Close calls run
+    <-routerDone
 
     return nil
 }
+
+// startGateway binds the gateway address and serves in the background
+func startGateway(ctx context.Context, gw *gateway.Server) error {
+    ln, err := gw.Listen()
+    if err != nil {
+        return err
+    }
+    go func() {
+        if err := gw.Serve(ctx, ln); err != nil {
+            slog.Error("gateway error", "error", err)
+        }
+    }()
+    return nil
+}
+
+// newAgents builds the default agent and one per profile in agents.profiles,
+// each with its own model, workspace and tools. Loops built before an error
+// are returned so the caller can close them.
+func newAgents(ctx context.Context, cfg *config.Config, msgBus *bus.MessageBus) (map[string]*agent.Loop, error) {
+    loops := make(map[string]*agent.Loop)
+    for _, name := range cfg.AgentNames() {
+        agentCfg, err := cfg.ForAgent(name)
+        if err != nil {
+            return loops, err
+        }
+        model, err := provider.NewChatModel(ctx, agentCfg)
+        if err != nil {
+            slog.Warn("no model configured", "agent", name, "error", err)
+        }
+        loop, err := agent.NewLoop(agentCfg, msgBus, model)
+        if err != nil {
+            return loops, fmt.Errorf("agent %s: invalid workspace: %w", name, err)
+        }
+        loops[name] = loop
+        if err := loop.RegisterDefaultTools(agentCfg); err != nil {
+            return loops, fmt.Errorf("agent %s: %w", name, err)
+        }
+    }
+    return loops, nil
+}
+
+func agentRoutes(cfg *config.Config) []agent.Route {
+    routes := make([]agent.Route, 0, len(cfg.Agents.Routes))
+    for _, r := range cfg.Agents.Routes {
+        routes = append(routes, agent.Route{
+            Agent:    r.Agent,
+            Channel:  r.Channel,
+            ChatID:   r.ChatID,
+            SenderID: r.SenderID,
+            Prefix:   r.Prefix,
+        })
+    }
+    return routes
+}
+
+// newHeartbeat runs HEARTBEAT.md in its own session and forwards reports to
+// the configured chat, or only logs them when none is set
+func newHeartbeat(cfg *config.Config, loop *agent.Loop, msgBus *bus.MessageBus) *heartbeat.Heartbeat {
+    hb := cfg.Heartbeat
+    return heartbeat.New(cfg.WorkspacePath(), time.Duration(hb.Interval)*time.Second,
+        func(ctx context.Context, prompt string) (string, error) {
+            resp, err := loop.ProcessMessage(ctx, &bus.InboundMessage{
+                Channel:   "heartbeat",
+                ChatID:    "main",
+                SenderID:  "heartbeat",
+                Content:   prompt,
+                Timestamp: time.Now(),
+            })
+            if err != nil {
+                return "", err
+            }
+            return resp.Content, nil
+        },
+        func(report string) {
+            if hb.Channel == "" || hb.ChatID == "" {
+                slog.Info("heartbeat report", "content", report)
+                return
+            }
+            msgBus.PublishOutbound(&bus.OutboundMessage{
+                Channel: hb.Channel,
+                ChatID:  hb.ChatID,
+                Content: report,
+            })
+        })
+}
@@ -2,6 +2,10 @@ package commands
 
 import (
     "context"
+    "net"
+    "os"
+    "path/filepath"
+    "strings"
     "testing"
 
     "github.com/MEKXH/golem/internal/agent"
@@ -9,6 +13,7 @@ import (
     "github.com/MEKXH/golem/internal/channel"
     "github.com/MEKXH/golem/internal/channel/telegram"
     "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/heartbeat"
     "github.com/MEKXH/golem/internal/provider"
 )
 
@@ -38,3 +43,106 @@ func TestRunCommand_WiresComponents(t *testing.T) {
         t.Fatalf("expected no channels registered")
     }
 }
+
+func TestRunCommand_BuildsAgentPerProfile(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    cfg := config.DefaultConfig()
+    opsWorkspace := filepath.Join(tmpDir, "ops")
+    cfg.Agents.Profiles = map[string]config.AgentProfile{
+        "ops": {Workspace: opsWorkspace, Tools: []string{"read_file"}},
+    }
+    cfg.Agents.Routes = []config.AgentRouteConfig{{Agent: "ops", Channel: "telegram", ChatID: "-100"}}
+
+    msgBus := bus.NewMessageBus(10)
+    loops, err := newAgents(context.Background(), cfg, msgBus)
+    for _, l := range loops {
+        defer l.Close()
+    }
+    if err != nil {
+        t.Fatalf("newAgents error: %v", err)
+    }
+    if len(loops) != 2 || loops["ops"].WorkspacePath() != opsWorkspace || loops[config.DefaultAgent].WorkspacePath() != cfg.WorkspacePath() {
+        t.Fatalf("unexpected agents %v", loops)
+    }
+
+    router, err := agent.NewRouter(msgBus, loops, agentRoutes(cfg))
+    if err != nil {
+        t.Fatalf("NewRouter error: %v", err)
+    }
+    if name, _ := router.Route(&bus.InboundMessage{Channel: "telegram", ChatID: "-100", Content: "status"}); name != "ops" {
+        t.Fatalf("expected the ops chat to go to ops, got %s", name)
+    }
+
+    cfg.Agents.Routes = append(cfg.Agents.Routes, config.AgentRouteConfig{Agent: "sales"})
+    if _, err := agent.NewRouter(msgBus, loops, agentRoutes(cfg)); err == nil {
+        t.Fatal("expected a route to an unknown agent to fail")
+    }
+}
+
+func TestRunCommand_HeartbeatReportsToChat(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    cfg := config.DefaultConfig()
+    cfg.Heartbeat.ChatID = "42"
+    if err := os.MkdirAll(cfg.WorkspacePath(), 0755); err != nil {
+        t.Fatalf("MkdirAll: %v", err)
+    }
+    tasks := "- Check the backups\n"
+    if err := os.WriteFile(filepath.Join(cfg.WorkspacePath(), heartbeat.FileName), []byte(tasks), 0644); err != nil {
+        t.Fatalf("WriteFile: %v", err)
+    }
+
+    msgBus := bus.NewMessageBus(10)
+    loop, err := agent.NewLoop(cfg, msgBus, nil)
+    if err != nil {
+        t.Fatalf("NewLoop error: %v", err)
+    }
+    defer loop.Close()
+
+    // Without a model the reply is not HEARTBEAT_OK, so it is reported
+    report, err := newHeartbeat(cfg, loop, msgBus).Beat(context.Background())
+    if err != nil || report == "" {
+        t.Fatalf("expected a report, got %q %v", report, err)
+    }
+    select {
+    case msg := <-msgBus.Outbound():
+        if msg.Channel != "telegram" || msg.ChatID != "42" || msg.Content != report {
+            t.Fatalf("unexpected outbound %+v", msg)
+        }
+    default:
+        t.Fatal("expected the report on the bus")
+    }
+}
+
+func TestRunCommand_FailsWhenWebhookGatewayCannotBind(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    busy, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("Listen: %v", err)
+    }
+    defer busy.Close()
+
+    cfg := config.DefaultConfig()
+    cfg.Gateway.Host = "127.0.0.1"
+    cfg.Gateway.Port = busy.Addr().(*net.TCPAddr).Port
+    cfg.Channels.Telegram.Enabled = true
+    cfg.Channels.Telegram.Token = "test-token"
+    cfg.Channels.Telegram.Webhook.Enabled = true
+    cfg.Channels.Telegram.Webhook.URL = "https://bot.example.com/hooks/tg"
+    if err := config.Save(cfg); err != nil {
+        t.Fatalf("Save: %v", err)
+    }
+
+    err = runServer(nil, nil)
+    if err == nil || !strings.Contains(err.Error(), "gateway listen failed") {
+        t.Fatalf("expected the bind error to be returned, got %v", err)
+    }
+}
@@ -0,0 +1,317 @@
+package commands
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/session"
+    "github.com/charmbracelet/glamour"
+    "github.com/charmbracelet/lipgloss"
+    "github.com/spf13/cobra"
+)
+
+func NewSessionsCmd() *cobra.Command {
+    cmd := &cobra.Command{
+        Use:   "sessions",
+        Short: "Inspect, export and clean up stored conversations",
+    }
+    cmd.PersistentFlags().String("agent", config.DefaultAgent, "Agent profile whose sessions to use")
+
+    var filter session.Filter
+    list := &cobra.Command{
+        Use:   "list",
+        Short: "List sessions, most recently active first",
+        Args:  cobra.NoArgs,
+        RunE: func(cmd *cobra.Command, args []string) error {
+            return runSessionsList(cmd, filter)
+        },
+    }
+    list.Flags().StringVar(&filter.Channel, "channel", "", "Only sessions of this channel")
+    list.Flags().IntVar(&filter.Limit, "limit", 0, "Show at most this many sessions")
+
+    var last int
+    show := &cobra.Command{
+        Use:   "show <key>",
+        Short: "Print a session transcript",
+        Args:  cobra.ExactArgs(1),
+        RunE: func(cmd *cobra.Command, args []string) error {
+            return runSessionsShow(cmd, args[0], last)
+        },
+    }
+    show.Flags().IntVar(&last, "last", 20, "Show the last n messages (0 for all)")
+
+    var format, output string
+    export := &cobra.Command{
+        Use:   "export <key>",
+        Short: "Export a session as Markdown, JSON or HTML",
+        Args:  cobra.ExactArgs(1),
+        RunE: func(cmd *cobra.Command, args []string) error {
+            return runSessionsExport(cmd, args[0], format, output)
+        },
+    }
+    export.Flags().StringVar(&format, "format", "", "md, json or html (defaults to the output file extension, else md)")
+    export.Flags().StringVarP(&output, "output", "o", "", "Write to this file instead of stdout")
+
+    var olderThan string
+    var dryRun bool
+    prune := &cobra.Command{
+        Use:   "prune",
+        Short: "Delete sessions with no activity for a while",
+        Args:  cobra.NoArgs,
+        RunE: func(cmd *cobra.Command, args []string) error {
+            return runSessionsPrune(cmd, olderThan, dryRun)
+        },
+    }
+    prune.Flags().StringVar(&olderThan, "older-than", "", "Age of the last activity, e.g. 30d, 2w or 12h")
+    prune.Flags().BoolVar(&dryRun, "dry-run", false, "Only list the sessions that would be deleted")
+    _ = prune.MarkFlagRequired("older-than")
+
+    cmd.AddCommand(
+        list,
+        show,
+        export,
+        &cobra.Command{
+            Use:   "delete <key>...",
+            Short: "Delete sessions",
+            Args:  cobra.MinimumNArgs(1),
+            RunE:  runSessionsDelete,
+        },
+        prune,
+    )
+    return cmd
+}
+
+// sessionManager opens the session store of the selected agent's workspace
+func sessionManager(cmd *cobra.Command) (*session.Manager, error) {
+    cfg, err := config.Load()
+    if err != nil {
+        return nil, fmt.Errorf("failed to load config: %w", err)
+    }
+    if cmd != nil && cmd.Flags().Changed("agent") {
+        name, _ := cmd.Flags().GetString("agent")
+        if cfg, err = cfg.ForAgent(name); err != nil {
+            return nil, err
+        }
+    }
+    workspacePath, err := cfg.WorkspacePathChecked()
+    if err != nil {
+        return nil, fmt.Errorf("invalid workspace: %w", err)
+    }
+    store, err := session.OpenStore(cfg.Sessions.Store, workspacePath)
+    if err != nil {
+        return nil, err
+    }
+    return session.NewManagerWithStore(store), nil
+}
+
+func commandContext(cmd *cobra.Command) context.Context {
+    if cmd != nil && cmd.Context() != nil {
+        return cmd.Context()
+    }
+    return context.Background()
+}
+
+// sessionNotFound names the session in ErrNotFound errors
+func sessionNotFound(key string, err error) error {
+    if errors.Is(err, session.ErrNotFound) {
+        return fmt.Errorf("no session %q (see 'golem sessions list')", key)
+    }
+    return err
+}
+
+func runSessionsList(cmd *cobra.Command, filter session.Filter) error {
+    mgr, err := sessionManager(cmd)
+    if err != nil {
+        return err
+    }
+    defer mgr.Close()
+    infos, err := mgr.List(commandContext(cmd), filter)
+    if err != nil {
+        return err
+    }
+    if len(infos) == 0 {
+        fmt.Println("No sessions")
+        return nil
+    }
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "KEY\tMESSAGES\tLAST ACTIVITY\tSIZE")
+    for _, info := range infos {
+        fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", info.Key, info.Messages, info.Updated.Local().Format(time.DateTime), formatSize(info.Size))
+    }
+    return w.Flush()
+}
+
+func runSessionsShow(cmd *cobra.Command, key string, last int) error {
+    mgr, err := sessionManager(cmd)
+    if err != nil {
+        return err
+    }
+    defer mgr.Close()
+    ctx := commandContext(cmd)
+    info, err := mgr.Get(ctx, key)
+    if err != nil {
+        return sessionNotFound(key, err)
+    }
+    msgs, err := mgr.Tail(ctx, key, last)
+    if err != nil {
+        return sessionNotFound(key, err)
+    }
+
+    var renderer markdownRenderer
+    if r, err := glamour.NewTermRenderer(glamour.WithAutoStyle(), glamour.WithWordWrap(100)); err == nil {
+        renderer = r
+    }
+    header := lipgloss.NewStyle().Bold(true)
+    roleStyles := map[string]lipgloss.Style{
+        "user":      lipgloss.NewStyle().Foreground(lipgloss.Color("5")),
+        "assistant": lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+    }
+    fmt.Println(header.Render(fmt.Sprintf("Session %s", info.Key)))
+    fmt.Printf("%d messages, last activity %s\n", info.Messages, info.Updated.Local().Format(time.DateTime))
+    if len(msgs) < info.Messages {
+        fmt.Printf("(showing the last %d)\n", len(msgs))
+    }
+    for _, msg := range msgs {
+        label := fmt.Sprintf("%s · %s", session.RoleLabel(msg.Role), msg.Timestamp.Local().Format(time.DateTime))
+        fmt.Printf("\n%s\n", roleStyles[msg.Role].Render(label))
+        content := strings.TrimSpace(msg.Content)
+        if msg.Role == "assistant" {
+            lines := strings.Split(strings.Trim(renderMarkdown(renderer, content), "\n"), "\n")
+            for i, line := range lines {
+                lines[i] = strings.TrimRight(line, " ")
+            }
+            content = strings.Join(lines, "\n")
+        }
+        fmt.Println(content)
+    }
+    return nil
+}
+
+func runSessionsExport(cmd *cobra.Command, key, format, output string) error {
+    if format == "" {
+        format = session.FormatMarkdown
+        if ext := filepath.Ext(output); ext != "" {
+            format = ext
+        }
+    }
+    mgr, err := sessionManager(cmd)
+    if err != nil {
+        return err
+    }
+    defer mgr.Close()
+    ctx := commandContext(cmd)
+    info, err := mgr.Get(ctx, key)
+    if err != nil {
+        return sessionNotFound(key, err)
+    }
+    msgs, err := mgr.Messages(ctx, key, 0, 0)
+    if err != nil {
+        return sessionNotFound(key, err)
+    }
+    transcript := session.Transcript{Session: info, Messages: msgs}
+
+    var buf bytes.Buffer
+    if err := session.Export(&buf, transcript, format); err != nil {
+        return err
+    }
+    if output == "" {
+        _, err = os.Stdout.Write(buf.Bytes())
+        return err
+    }
+    if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+        return err
+    }
+    fmt.Printf("Exported %d messages of %s to %s\n", len(msgs), key, output)
+    return nil
+}
+
+func runSessionsDelete(cmd *cobra.Command, args []string) error {
+    mgr, err := sessionManager(cmd)
+    if err != nil {
+        return err
+    }
+    defer mgr.Close()
+    for _, key := range args {
+        if err := mgr.Delete(commandContext(cmd), key); err != nil {
+            return sessionNotFound(key, err)
+        }
+        fmt.Printf("Deleted session %s\n", key)
+    }
+    return nil
+}
+
+func runSessionsPrune(cmd *cobra.Command, olderThan string, dryRun bool) error {
+    age, err := parseAge(olderThan)
+    if err != nil {
+        return err
+    }
+    mgr, err := sessionManager(cmd)
+    if err != nil {
+        return err
+    }
+    defer mgr.Close()
+    ctx := commandContext(cmd)
+    cutoff := time.Now().Add(-age)
+
+    var infos []session.Info
+    if dryRun {
+        infos, err = mgr.List(ctx, session.Filter{UpdatedBefore: cutoff})
+    } else {
+        infos, err = mgr.Prune(ctx, cutoff)
+    }
+    if err != nil {
+        return err
+    }
+    verb := "Deleted"
+    if dryRun {
+        verb = "Would delete"
+    }
+    for _, info := range infos {
+        fmt.Printf("%s %s (last activity %s)\n", verb, info.Key, info.Updated.Local().Format(time.DateTime))
+    }
+    fmt.Printf("%s %d sessions with no activity since %s\n", verb, len(infos), cutoff.Format(time.DateTime))
+    return nil
+}
+
+// parseAge reads a duration that may also be given in days (30d) or weeks (2w)
+func parseAge(s string) (time.Duration, error) {
+    s = strings.TrimSpace(s)
+    unit := time.Duration(0)
+    switch {
+    case strings.HasSuffix(s, "d"):
+        unit = 24 * time.Hour
+    case strings.HasSuffix(s, "w"):
+        unit = 7 * 24 * time.Hour
+    }
+    if unit != 0 {
+        n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+        if err == nil && n > 0 {
+            return time.Duration(n * float64(unit)), nil
+        }
+    } else if d, err := time.ParseDuration(s); err == nil && d > 0 {
+        return d, nil
+    }
+    return 0, fmt.Errorf("invalid age %q: use e.g. 30d, 2w or 12h", s)
+}
+
+func formatSize(n int64) string {
+    const unit = 1024
+    if n < unit {
+        return fmt.Sprintf("%d B", n)
+    }
+    div, exp := int64(unit), 0
+    for m := n / unit; m >= unit; m /= unit {
+        div *= unit
+        exp++
+    }
+    return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/session"
+)
+
+func TestSessionsCommands(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    store := session.NewJSONLStore(filepath.Join(config.DefaultConfig().WorkspacePath(), "sessions"))
+    now := time.Now()
+    seed := func(key string, at time.Time, contents ...string) {
+        var msgs []*session.Message
+        for i, c := range contents {
+            role := "user"
+            if i%2 == 1 {
+                role = "assistant"
+            }
+            msgs = append(msgs, &session.Message{Role: role, Content: c, Timestamp: at})
+        }
+        if err := store.Append(context.Background(), key, msgs); err != nil {
+            t.Fatal(err)
+        }
+    }
+    seed("telegram:42", now.Add(-time.Hour), "What's on today?", "A **standup** at 10.")
+    seed("cli:direct", now.Add(-60*24*time.Hour), "old question", "old answer")
+
+    exported := filepath.Join(tmpDir, "standup.html")
+    output := captureOutput(t, func() {
+        if err := runSessionsList(nil, session.Filter{}); err != nil {
+            t.Fatalf("runSessionsList error: %v", err)
+        }
+        if err := runSessionsShow(nil, "telegram:42", 1); err != nil {
+            t.Fatalf("runSessionsShow error: %v", err)
+        }
+        if err := runSessionsExport(nil, "telegram:42", "", ""); err != nil {
+            t.Fatalf("runSessionsExport error: %v", err)
+        }
+        if err := runSessionsExport(nil, "telegram:42", "", exported); err != nil {
+            t.Fatalf("runSessionsExport error: %v", err)
+        }
+        if err := runSessionsPrune(nil, "30d", true); err != nil {
+            t.Fatalf("runSessionsPrune error: %v", err)
+        }
+        if err := runSessionsPrune(nil, "30d", false); err != nil {
+            t.Fatalf("runSessionsPrune error: %v", err)
+        }
+        if err := runSessionsDelete(nil, []string{"telegram:42"}); err != nil {
+            t.Fatalf("runSessionsDelete error: %v", err)
+        }
+        if err := runSessionsList(nil, session.Filter{}); err != nil {
+            t.Fatalf("runSessionsList error: %v", err)
+        }
+    })
+
+    for _, want := range []string{
+        "KEY          MESSAGES  LAST ACTIVITY",
+        "telegram:42  2",
+        "cli:direct   2",
+        "2 messages, last activity",
+        "(showing the last 1)",
+        "standup",
+        "# Session telegram:42",
+        "## User · ",
+        "Exported 2 messages of telegram:42 to " + exported,
+        "Would delete cli:direct",
+        "Deleted 1 sessions with no activity since",
+        "Deleted session telegram:42",
+        "No sessions",
+    } {
+        if !strings.Contains(output, want) {
+            t.Errorf("expected %q in output:\n%s", want, output)
+        }
+    }
+    if data, err := os.ReadFile(exported); err != nil || !strings.Contains(string(data), "<!DOCTYPE html>") {
+        t.Fatalf("expected an HTML export, got %v", err)
+    }
+    if err := runSessionsShow(nil, "telegram:42", 0); err == nil || !strings.Contains(err.Error(), `no session "telegram:42"`) {
+        t.Fatalf("expected a missing session error, got %v", err)
+    }
+}
+
+func TestParseAge(t *testing.T) {
+    for input, want := range map[string]time.Duration{
+        "30d":  30 * 24 * time.Hour,
+        "2w":   14 * 24 * time.Hour,
+        "12h":  12 * time.Hour,
+        "1.5d": 36 * time.Hour,
+    } {
+        if got, err := parseAge(input); err != nil || got != want {
+            t.Errorf("parseAge(%q) = %v, %v; want %v", input, got, err, want)
+        }
+    }
+    for _, input := range []string{"", "d", "-3d", "soon"} {
+        if _, err := parseAge(input); err == nil {
+            t.Errorf("expected parseAge(%q) to fail", input)
+        }
+    }
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+    "context"
+    "fmt"
+    "path/filepath"
+
+    "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/skills"
+    "github.com/spf13/cobra"
+)
+
+func NewSkillsCmd() *cobra.Command {
+    cmd := &cobra.Command{
+        Use:   "skills",
+        Short: "Manage workspace skills",
+    }
+    cmd.AddCommand(
+        &cobra.Command{
+            Use:   "list",
+            Short: "List installed skills",
+            Args:  cobra.NoArgs,
+            RunE:  runSkillsList,
+        },
+        &cobra.Command{
+            Use:   "add <path|git-url>",
+            Short: "Install a skill from a folder or git repository",
+            Args:  cobra.ExactArgs(1),
+            RunE:  runSkillsAdd,
+        },
+        &cobra.Command{
+            Use:   "remove <name>",
+            Short: "Remove an installed skill",
+            Args:  cobra.ExactArgs(1),
+            RunE:  runSkillsRemove,
+        },
+    )
+    return cmd
+}
+
+func skillsLoader() (*skills.Loader, error) {
+    cfg, err := config.Load()
+    if err != nil {
+        return nil, fmt.Errorf("failed to load config: %w", err)
+    }
+    workspacePath, err := cfg.WorkspacePathChecked()
+    if err != nil {
+        return nil, fmt.Errorf("invalid workspace: %w", err)
+    }
+    return skills.NewLoader(filepath.Join(workspacePath, "skills")), nil
+}
+
+func runSkillsList(cmd *cobra.Command, args []string) error {
+    loader, err := skillsLoader()
+    if err != nil {
+        return err
+    }
+    list, err := loader.List()
+    if err != nil {
+        return err
+    }
+    if len(list) == 0 {
+        fmt.Printf("No skills installed in %s\n", loader.Dir())
+        return nil
+    }
+    for _, s := range list {
+        fmt.Printf("%s: %s\n", s.Name, s.Description)
+        if s.WhenToUse != "" {
+            fmt.Printf("  Use when: %s\n", s.WhenToUse)
+        }
+    }
+    return nil
+}
+
+func runSkillsAdd(cmd *cobra.Command, args []string) error {
+    loader, err := skillsLoader()
+    if err != nil {
+        return err
+    }
+    ctx := context.Background()
+    if cmd != nil {
+        ctx = cmd.Context()
+    }
+    skill, err := loader.Add(ctx, args[0])
+    if err != nil {
+        return err
+    }
+    fmt.Printf("Installed skill %s into %s\n", skill.Name, skill.Dir)
+    return nil
+}
+
+func runSkillsRemove(cmd *cobra.Command, args []string) error {
+    loader, err := skillsLoader()
+    if err != nil {
+        return err
+    }
+    skill, err := loader.Remove(args[0])
+    if err != nil {
+        return err
+    }
+    fmt.Printf("Removed skill %s\n", skill.Name)
+    return nil
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestSkillsCommands_AddListRemove(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    src := filepath.Join(tmpDir, "weather")
+    if err := os.MkdirAll(src, 0755); err != nil {
+        t.Fatalf("MkdirAll: %v", err)
+    }
+    content := "---\nname: weather\ndescription: Look up forecasts\nwhen_to_use: The user asks about weather\n---\nUse web_search.\n"
+    if err := os.WriteFile(filepath.Join(src, "SKILL.md"), []byte(content), 0644); err != nil {
+        t.Fatalf("WriteFile: %v", err)
+    }
+
+    output := captureOutput(t, func() {
+        if err := runSkillsList(nil, nil); err != nil {
+            t.Fatalf("runSkillsList error: %v", err)
+        }
+        if err := runSkillsAdd(nil, []string{src}); err != nil {
+            t.Fatalf("runSkillsAdd error: %v", err)
+        }
+        if err := runSkillsList(nil, nil); err != nil {
+            t.Fatalf("runSkillsList error: %v", err)
+        }
+        if err := runSkillsRemove(nil, []string{"weather"}); err != nil {
+            t.Fatalf("runSkillsRemove error: %v", err)
+        }
+    })
+
+    for _, want := range []string{
+        "No skills installed",
+        "Installed skill weather",
+        "weather: Look up forecasts\n  Use when: The user asks about weather",
+        "Removed skill weather",
+    } {
+        if !strings.Contains(output, want) {
+            t.Fatalf("expected %q in output, got: %s", want, output)
+        }
+    }
+}
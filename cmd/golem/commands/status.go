@@ -4,8 +4,10 @@ import (
     "fmt"
     "os"
     "strings"
+    "time"
 
     "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/tools"
     "github.com/spf13/cobra"
 )
 
@@ -72,5 +74,21 @@ func runStatus(cmd *cobra.Command, args []string) error {
     fmt.Println("\nChannels:")
     fmt.Printf("  Telegram: %v\n", cfg.Channels.Telegram.Enabled)
 
+    fmt.Println("\nBackground Processes:")
+    procs, err := tools.ReadProcessStates(workspacePath)
+    if err != nil {
+        fmt.Printf("  Error: %v\n", err)
+    } else if len(procs) == 0 {
+        fmt.Println("  None")
+    }
+    for _, p := range procs {
+        state := "running"
+        if !p.Running {
+            state = fmt.Sprintf("exited (%d)", p.ExitCode)
+        }
+        fmt.Printf("  %s [%s] pid %d %s, started %s: %s\n",
+            p.ID, p.Session, p.PID, state, p.StartedAt.Format(time.DateTime), p.Command)
+    }
+
     return nil
 }
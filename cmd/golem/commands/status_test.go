@@ -29,6 +29,9 @@ func TestStatusCommand_PrintsConfig(t *testing.T) {
     if !strings.Contains(output, "Mode: default") {
         t.Fatalf("expected workspace mode line, got: %s", output)
     }
+    if !strings.Contains(output, "Background Processes:\n  None") {
+        t.Fatalf("expected empty background process list, got: %s", output)
+    }
 }
 
 func TestStatusCommand_InvalidWorkspaceModeReturnsError(t *testing.T) {
@@ -0,0 +1,105 @@
+package commands
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "log/slog"
+    "sort"
+    "strings"
+
+    "github.com/MEKXH/golem/internal/agent"
+    "github.com/MEKXH/golem/internal/bus"
+    "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/tools"
+    "github.com/spf13/cobra"
+)
+
+func NewToolsCmd() *cobra.Command {
+    cmd := &cobra.Command{
+        Use:   "tools",
+        Short: "List the tools available to the agent, including plugins and MCP server tools",
+        Args:  cobra.NoArgs,
+        RunE:  runTools,
+    }
+    cmd.Flags().String("agent", config.DefaultAgent, "Agent profile whose tools to list")
+    return cmd
+}
+
+func runTools(cmd *cobra.Command, args []string) error {
+    cfg, err := config.Load()
+    if err != nil {
+        return fmt.Errorf("failed to load config: %w", err)
+    }
+    if cmd != nil && cmd.Flags().Changed("agent") {
+        name, _ := cmd.Flags().GetString("agent")
+        if cfg, err = cfg.ForAgent(name); err != nil {
+            return err
+        }
+    }
+
+    // Connection problems are reported below as missing servers
+    logger := slog.Default()
+    slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+    defer slog.SetDefault(logger)
+
+    loop, err := agent.NewLoop(cfg, bus.NewMessageBus(1), nil)
+    if err != nil {
+        return fmt.Errorf("invalid workspace: %w", err)
+    }
+    defer loop.Close()
+    if err := loop.RegisterDefaultTools(cfg); err != nil {
+        return err
+    }
+
+    var builtin, plugin, external []string
+    servers := make(map[string]int)
+    for _, t := range loop.Tools().List() {
+        info, err := t.Info(context.Background())
+        if err != nil {
+            return err
+        }
+        line := fmt.Sprintf("  %s: %s", info.Name, firstLine(info.Desc))
+        if server, ok := tools.MCPServer(t); ok {
+            external = append(external, line)
+            servers[server]++
+            continue
+        }
+        if _, ok := tools.PluginDir(t); ok {
+            plugin = append(plugin, line)
+            continue
+        }
+        builtin = append(builtin, line)
+    }
+    sort.Strings(builtin)
+    sort.Strings(plugin)
+    sort.Strings(external)
+
+    fmt.Printf("Built-in tools (%d):\n%s\n", len(builtin), strings.Join(builtin, "\n"))
+    if len(plugin) > 0 {
+        fmt.Printf("\nPlugin tools (%d):\n%s\n", len(plugin), strings.Join(plugin, "\n"))
+    }
+    if len(cfg.Tools.MCP.Servers) == 0 {
+        return nil
+    }
+    fmt.Printf("\nMCP tools (%d):\n", len(external))
+    if len(external) > 0 {
+        fmt.Println(strings.Join(external, "\n"))
+    }
+    names := make([]string, 0, len(cfg.Tools.MCP.Servers))
+    for name := range cfg.Tools.MCP.Servers {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        if servers[name] == 0 {
+            fmt.Printf("  (server %s is unavailable or has no tools)\n", name)
+        }
+    }
+    return nil
+}
+
+func firstLine(s string) string {
+    line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+    return line
+}
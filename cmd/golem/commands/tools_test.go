@@ -0,0 +1,45 @@
+package commands
+
+import (
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/mcp/mcptest"
+)
+
+func TestToolsCommand_ListsBuiltinAndMCPTools(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    srv := httptest.NewServer(mcptest.NewServer())
+    defer srv.Close()
+
+    cfg := config.DefaultConfig()
+    cfg.Tools.MCP.Servers = map[string]config.MCPServerConfig{
+        "fake": {URL: srv.URL},
+        "down": {URL: "http://127.0.0.1:1/mcp"},
+    }
+    if err := config.Save(cfg); err != nil {
+        t.Fatalf("Save: %v", err)
+    }
+
+    output := captureOutput(t, func() {
+        if err := runTools(nil, nil); err != nil {
+            t.Fatalf("runTools error: %v", err)
+        }
+    })
+    for _, want := range []string{
+        "Built-in tools",
+        "  read_file: ",
+        "MCP tools (4):",
+        "  fake__echo: Echo the text back",
+        "server down is unavailable",
+    } {
+        if !strings.Contains(output, want) {
+            t.Errorf("expected %q in output:\n%s", want, output)
+        }
+    }
+}
@@ -4,9 +4,11 @@ import (
     "os"
 
     "github.com/MEKXH/golem/cmd/golem/commands"
+    "github.com/MEKXH/golem/internal/sandbox"
 )
 
 func main() {
+    sandbox.Init()
     if err := commands.NewRootCmd().Execute(); err != nil {
         os.Exit(1)
     }
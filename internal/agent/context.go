@@ -1,52 +1,148 @@
 package agent
 
 import (
+    "context"
+    "fmt"
+    "log/slog"
     "os"
     "path/filepath"
     "strings"
 
     "github.com/cloudwego/eino/schema"
+    "github.com/MEKXH/golem/internal/memory"
     "github.com/MEKXH/golem/internal/session"
+    "github.com/MEKXH/golem/internal/skills"
 )
 
 // ContextBuilder builds LLM context
 type ContextBuilder struct {
     workspacePath string
+    promptFiles   []string
+    memoryIndex   *memory.Index
+    recallK       int
 }
 
+// bootstrapFiles are read from the workspace into the system prompt unless
+// prompt files are set
+var bootstrapFiles = []string{"IDENTITY.md", "SOUL.md", "USER.md", "TOOLS.md", "AGENTS.md"}
+
 // NewContextBuilder creates a context builder
 func NewContextBuilder(workspacePath string) *ContextBuilder {
     return &ContextBuilder{workspacePath: workspacePath}
 }
 
-// BuildSystemPrompt assembles the system prompt
+// SetPromptFiles replaces the bootstrap files with the given files, read
+// relative to the workspace unless absolute
+func (c *ContextBuilder) SetPromptFiles(files []string) {
+    c.promptFiles = files
+}
+
+// SetMemoryIndex switches from including all of MEMORY.md to recalling the
+// topK memories and past conversation turns most relevant to each message
+func (c *ContextBuilder) SetMemoryIndex(idx *memory.Index, topK int) {
+    c.memoryIndex = idx
+    c.recallK = topK
+}
+
+// BuildSystemPrompt assembles the system prompt with the whole long-term memory
 func (c *ContextBuilder) BuildSystemPrompt() string {
+    return c.buildSystemPrompt(c.longTermMemory())
+}
+
+func (c *ContextBuilder) buildSystemPrompt(memorySection string) string {
     var parts []string
 
     parts = append(parts, c.coreIdentity())
 
-    bootstrapFiles := []string{"IDENTITY.md", "SOUL.md", "USER.md", "TOOLS.md", "AGENTS.md"}
-    for _, name := range bootstrapFiles {
+    files := bootstrapFiles
+    if len(c.promptFiles) > 0 {
+        files = c.promptFiles
+    }
+    for _, name := range files {
         if content := c.readWorkspaceFile(name); content != "" {
-            parts = append(parts, "## "+strings.TrimSuffix(name, ".md")+"\n"+content)
+            title := filepath.Base(name)
+            parts = append(parts, "## "+strings.TrimSuffix(title, filepath.Ext(title))+"\n"+content)
         }
     }
 
-    if mem := c.readWorkspaceFile(filepath.Join("memory", "MEMORY.md")); mem != "" {
-        parts = append(parts, "## Long-term Memory\n"+mem)
+    if section := c.skillsSection(); section != "" {
+        parts = append(parts, section)
+    }
+
+    if memorySection != "" {
+        parts = append(parts, memorySection)
+    }
+
+    if notes := memory.NewStore(filepath.Join(c.workspacePath, "memory")).RecentNotes(2); len(notes) > 0 {
+        parts = append(parts, "## Recent Notes\n"+strings.Join(notes, "\n\n"))
     }
 
     return strings.Join(parts, "\n\n")
 }
 
+// skillsSection lists installed skills in one line each; load_skill
+// returns the full instructions when one is needed
+func (c *ContextBuilder) skillsSection() string {
+    list, err := skills.NewLoader(filepath.Join(c.workspacePath, "skills")).List()
+    if err != nil || len(list) == 0 {
+        return ""
+    }
+    var b strings.Builder
+    b.WriteString("## Skills\n")
+    b.WriteString("Call load_skill with the skill name to read its instructions before doing a task it covers.\n")
+    for _, s := range list {
+        fmt.Fprintf(&b, "\n- %s: %s", s.Name, s.Description)
+        if s.WhenToUse != "" {
+            fmt.Fprintf(&b, " (use when: %s)", s.WhenToUse)
+        }
+    }
+    return b.String()
+}
+
+func (c *ContextBuilder) longTermMemory() string {
+    if mem := c.readWorkspaceFile(filepath.Join("memory", memory.FileName)); mem != "" {
+        return "## Long-term Memory\n" + mem
+    }
+    return ""
+}
+
+// relevantMemories recalls indexed memories for the message, falling back to
+// the whole memory file if the index cannot be searched
+func (c *ContextBuilder) relevantMemories(ctx context.Context, sessionKey, current string) string {
+    recalls, err := c.memoryIndex.Search(ctx, current, c.recallK, sessionKey)
+    if err != nil {
+        slog.Warn("memory recall failed, using MEMORY.md", "error", err)
+        return c.longTermMemory()
+    }
+    if len(recalls) == 0 {
+        return ""
+    }
+    var b strings.Builder
+    b.WriteString("## Relevant Memories\n")
+    b.WriteString("Retrieved from long-term memory, notes and earlier conversations; they may be out of date.\n")
+    for _, r := range recalls {
+        label := r.Kind
+        if r.Date != "" {
+            label += " " + r.Date
+        }
+        text := strings.ReplaceAll(r.Text, "\n", "\n  ")
+        fmt.Fprintf(&b, "\n- [%s] %s", label, text)
+    }
+    return b.String()
+}
+
 func (c *ContextBuilder) coreIdentity() string {
     return `You are Golem, a personal AI assistant.
 You have access to tools for file operations, shell commands, and more.
-Be helpful, concise, and proactive. Use tools when needed to accomplish tasks.`
+Be helpful, concise, and proactive. Use tools when needed to accomplish tasks.
+Save lasting facts about the user with memory_save and check memory_search before asking for something you may already know.`
 }
 
 func (c *ContextBuilder) readWorkspaceFile(name string) string {
-    path := filepath.Join(c.workspacePath, name)
+    path := name
+    if !filepath.IsAbs(path) {
+        path = filepath.Join(c.workspacePath, name)
+    }
     data, err := os.ReadFile(path)
     if err != nil {
         return ""
@@ -54,13 +150,33 @@ func (c *ContextBuilder) readWorkspaceFile(name string) string {
     return strings.TrimSpace(string(data))
 }
 
-// BuildMessages constructs the full message list
-func (c *ContextBuilder) BuildMessages(history []*session.Message, current string, media []string) []*schema.Message {
+// BuildSubAgentMessages starts a fresh conversation for a delegated task
+func (c *ContextBuilder) BuildSubAgentMessages(task string) []*schema.Message {
+    prompt := c.buildSystemPrompt("") + `
+
+## Sub-agent
+You are a sub-agent working on one task delegated by the main agent. You cannot ask the user questions.
+Your final reply goes back to the main agent: make it a concise, self-contained summary of what you did and found.`
+    return []*schema.Message{
+        {Role: schema.System, Content: prompt},
+        {Role: schema.User, Content: task},
+    }
+}
+
+// BuildMessages constructs the full message list. With a memory index the
+// system prompt carries memories relevant to current rather than MEMORY.md.
+func (c *ContextBuilder) BuildMessages(ctx context.Context, sessionKey string, history []*session.Message, current string, media []string) []*schema.Message {
     messages := make([]*schema.Message, 0, len(history)+2)
 
+    memorySection := ""
+    if c.memoryIndex != nil {
+        memorySection = c.relevantMemories(ctx, sessionKey, current)
+    } else {
+        memorySection = c.longTermMemory()
+    }
     messages = append(messages, &schema.Message{
         Role:    schema.System,
-        Content: c.BuildSystemPrompt(),
+        Content: c.buildSystemPrompt(memorySection),
     })
 
     for _, h := range history {
@@ -2,19 +2,44 @@ package agent
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/cron"
+	"github.com/MEKXH/golem/internal/mcp"
+	"github.com/MEKXH/golem/internal/memory"
+	"github.com/MEKXH/golem/internal/plugins"
+	"github.com/MEKXH/golem/internal/sandbox"
 	"github.com/MEKXH/golem/internal/session"
+	"github.com/MEKXH/golem/internal/skills"
 	"github.com/MEKXH/golem/internal/tools"
+	"github.com/MEKXH/golem/internal/webfetch"
+	"github.com/MEKXH/golem/internal/websearch"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
 )
 
+// mcpConnectTimeout bounds starting an MCP server and listing its tools
+const mcpConnectTimeout = 30 * time.Second
+
+// historyMessages is how many recent session messages each prompt includes
+const historyMessages = 50
+
+// ResetCommand starts a chat over: it ends the session and forgets its history
+const ResetCommand = "/reset"
+
 // Loop is the main agent processing loop
 type Loop struct {
+	name          string
 	bus           *bus.MessageBus
 	model         model.ChatModel
 	tools         *tools.Registry
@@ -22,6 +47,12 @@ type Loop struct {
 	context       *ContextBuilder
 	maxIterations int
 	workspacePath string
+	processes     *tools.ProcessManager
+	fetchCache    *tools.FetchCache
+	mcpClients    []*mcp.Client
+	// turn runs one turn at a time; besides serve, the heartbeat and MCP
+	// clients start turns that share the loop's tools and sessions
+	turn sync.Mutex
 
 	OnToolStart  func(name, args string)
 	OnToolFinish func(name, result string, err error)
@@ -33,28 +64,57 @@ func NewLoop(cfg *config.Config, msgBus *bus.MessageBus, chatModel model.ChatMod
 	if err != nil {
 		return nil, err
 	}
+	contextBuilder := NewContextBuilder(workspacePath)
+	contextBuilder.SetPromptFiles(cfg.Agents.Defaults.PromptFiles)
+	store, err := session.OpenStore(cfg.Sessions.Store, workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Memory.Semantic.Enabled {
+		idx, err := NewMemoryIndex(cfg, workspacePath, store)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		contextBuilder.SetMemoryIndex(idx, cfg.Memory.Semantic.TopK)
+	}
 	return &Loop{
 		bus:           msgBus,
 		model:         chatModel,
 		tools:         tools.NewRegistry(),
-		sessions:      session.NewManager(workspacePath),
-		context:       NewContextBuilder(workspacePath),
+		sessions:      session.NewManagerWithStore(store),
+		context:       contextBuilder,
 		maxIterations: cfg.Agents.Defaults.MaxToolIterations,
 		workspacePath: workspacePath,
+		processes:     tools.NewProcessManager(workspacePath, cfg.Tools.Exec.MaxBackground),
 	}, nil
 }
 
 // RegisterDefaultTools registers all built-in tools
 func (l *Loop) RegisterDefaultTools(cfg *config.Config) error {
+	execOpts, err := l.execOptions(cfg.Tools.Exec)
+	if err != nil {
+		return err
+	}
+
 	toolFns := []func() (interface{}, error){
 		func() (interface{}, error) { return tools.NewReadFileTool(l.workspacePath) },
 		func() (interface{}, error) { return tools.NewWriteFileTool(l.workspacePath) },
+		func() (interface{}, error) { return tools.NewEditFileTool(l.workspacePath) },
 		func() (interface{}, error) { return tools.NewListDirTool(l.workspacePath) },
+		func() (interface{}, error) { return tools.NewMoveFileTool(l.workspacePath) },
+		func() (interface{}, error) { return tools.NewCopyFileTool(l.workspacePath) },
+		func() (interface{}, error) { return tools.NewDeleteFileTool(l.workspacePath) },
+		func() (interface{}, error) { return tools.NewMakeDirTool(l.workspacePath) },
+		func() (interface{}, error) { return tools.NewStatTool(l.workspacePath) },
+		func() (interface{}, error) { return tools.NewGlobTool(l.workspacePath) },
+		func() (interface{}, error) { return tools.NewGrepTool(l.workspacePath) },
 		func() (interface{}, error) {
 			return tools.NewExecTool(
 				cfg.Tools.Exec.Timeout,
 				cfg.Tools.Exec.RestrictToWorkspace,
 				l.workspacePath,
+				execOpts...,
 			)
 		},
 	}
@@ -70,9 +130,271 @@ func (l *Loop) RegisterDefaultTools(cfg *config.Config) error {
 			}
 		}
 	}
+	memoryTools, err := tools.NewMemoryTools(memory.NewStore(filepath.Join(l.workspacePath, "memory")))
+	if err != nil {
+		return err
+	}
+	for _, t := range memoryTools {
+		if err := l.tools.Register(t); err != nil {
+			return err
+		}
+	}
+	skillTool, err := tools.NewLoadSkillTool(l.workspacePath, skills.NewLoader(filepath.Join(l.workspacePath, "skills")))
+	if err != nil {
+		return err
+	}
+	if err := l.tools.Register(skillTool); err != nil {
+		return err
+	}
+	if cfg.Cron.Enabled {
+		cronTools, err := tools.NewCronTools(cron.NewStore(l.workspacePath), cfg.Cron.Timezone, cfg.ChannelNames())
+		if err != nil {
+			return err
+		}
+		for _, t := range cronTools {
+			if err := l.tools.Register(t); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.Tools.Exec.MaxBackground > 0 {
+		processTools, err := tools.NewProcessTools(l.processes, cfg.Tools.Exec.RestrictToWorkspace, l.workspacePath, execOpts...)
+		if err != nil {
+			return err
+		}
+		for _, t := range processTools {
+			if err := l.tools.Register(t); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.Tools.Spawn.Enabled && cfg.Tools.Spawn.MaxIterations > 0 {
+		spawnTool, err := tools.NewSpawnAgentTool(newSubAgents(l, cfg.Tools.Spawn))
+		if err != nil {
+			return err
+		}
+		if err := l.tools.Register(spawnTool); err != nil {
+			return err
+		}
+	}
+	if err := l.registerWebTools(cfg.Tools.Web); err != nil {
+		return err
+	}
+	l.registerMCPTools(cfg.Tools.MCP)
+	l.registerPluginTools(cfg, execOpts)
+	l.tools.SetOutputLimits(tools.OutputLimits{
+		Default:     cfg.Tools.Output.MaxBytes,
+		PerTool:     cfg.Tools.Output.PerTool,
+		Workspace:   l.workspacePath,
+		ArtifactDir: cfg.Tools.Output.ArtifactDir,
+	})
+	if err := l.configurePolicies(cfg); err != nil {
+		return err
+	}
+	return l.configureApprovals(cfg)
+}
+
+// registerWebTools registers web_fetch, and web_search when a search backend
+// is configured
+func (l *Loop) registerWebTools(cfg config.WebToolsConfig) error {
+	if cfg.Fetch.Enabled {
+		fetcher := webfetch.New(webfetch.Options{
+			MaxBytes:     int64(cfg.Fetch.MaxBytes),
+			Timeout:      time.Duration(cfg.Fetch.Timeout) * time.Second,
+			AllowPrivate: cfg.Fetch.AllowPrivate,
+		})
+		if cfg.Fetch.CacheTTL > 0 {
+			l.fetchCache = tools.NewFetchCache(time.Duration(cfg.Fetch.CacheTTL) * time.Second)
+		}
+		fetchTool, err := tools.NewWebFetchTool(fetcher, l.fetchCache)
+		if err != nil {
+			return err
+		}
+		if err := l.tools.Register(fetchTool); err != nil {
+			return err
+		}
+	}
+
+	opts := websearch.Options{
+		Backend: cfg.Search.Provider,
+		APIKey:  cfg.Search.APIKey,
+		BaseURL: cfg.Search.BaseURL,
+	}
+	if !opts.Configured() {
+		return nil
+	}
+	searcher, err := websearch.New(opts)
+	if err != nil {
+		return err
+	}
+	searchTool, err := tools.NewWebSearchTool(searcher, cfg.Search.MaxResults)
+	if err != nil {
+		return err
+	}
+	return l.tools.Register(searchTool)
+}
+
+// registerMCPTools connects to the configured MCP servers and registers
+// their tools. A server that cannot be reached is skipped with a warning so
+// the agent still starts.
+func (l *Loop) registerMCPTools(cfg config.MCPConfig) {
+	names := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		server := cfg.Servers[name]
+		timeout := cfg.Timeout
+		if server.Timeout > 0 {
+			timeout = server.Timeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), mcpConnectTimeout)
+		client, err := mcp.Connect(ctx, mcp.Options{
+			Name:    name,
+			Command: server.Command,
+			Args:    server.Args,
+			Env:     server.Env,
+			Dir:     l.workspacePath,
+			URL:     server.URL,
+			Headers: server.Headers,
+			Timeout: time.Duration(timeout) * time.Second,
+		})
+		if err != nil {
+			cancel()
+			slog.Warn("mcp server unavailable", "server", name, "error", err)
+			continue
+		}
+		l.mcpClients = append(l.mcpClients, client)
+		mcpTools, err := tools.NewMCPTools(ctx, client)
+		cancel()
+		if err != nil {
+			slog.Warn("list mcp tools failed", "server", name, "error", err)
+			continue
+		}
+		for _, t := range mcpTools {
+			if err := l.tools.Register(t); err != nil {
+				slog.Warn("skipping mcp tool", "server", name, "error", err)
+			}
+		}
+		slog.Info("mcp server connected", "server", name, "tools", len(mcpTools))
+	}
+}
+
+// registerPluginTools registers the executable plugins found in the plugins
+// directory. They share the exec timeout and sandbox; broken manifests are
+// skipped with a warning.
+func (l *Loop) registerPluginTools(cfg *config.Config, execOpts []tools.ExecOption) {
+	if !cfg.Tools.Plugins.Enabled {
+		return
+	}
+	found, errs := plugins.Discover(cfg.PluginsPath())
+	for _, err := range errs {
+		slog.Warn("skipping plugin", "error", err)
+	}
+	for _, p := range found {
+		t, err := tools.NewPluginTool(p, cfg.Tools.Exec.Timeout, l.workspacePath, execOpts...)
+		if err == nil {
+			err = l.tools.Register(t)
+		}
+		if err != nil {
+			slog.Warn("skipping plugin", "plugin", p.Name, "error", err)
+		}
+	}
+}
+
+// execOptions builds the sandbox and command rules shared by exec and exec_background
+func (l *Loop) execOptions(cfg config.ExecToolConfig) ([]tools.ExecOption, error) {
+	var opts []tools.ExecOption
+	if cfg.Sandbox.Enabled {
+		sb, err := newSandbox(cfg.Sandbox, l.workspacePath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, tools.WithSandbox(sb))
+	}
+	rules, err := newCommandAnalyzer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return append(opts, tools.WithCommandRules(rules)), nil
+}
+
+func newCommandAnalyzer(cfg config.ExecToolConfig) (*tools.CommandAnalyzer, error) {
+	rules := make([]tools.CommandRule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		rules = append(rules, tools.CommandRule{
+			Name:     r.Name,
+			Action:   tools.RuleAction(r.Action),
+			Program:  r.Program,
+			Flags:    r.Flags,
+			Args:     r.Args,
+			Redirect: r.Redirect,
+		})
+	}
+	return tools.NewCommandAnalyzer(rules, cfg.DefaultRules)
+}
+
+func newSandbox(cfg config.SandboxConfig, workspacePath string) (sandbox.Sandbox, error) {
+	const mb = 1 << 20
+	return sandbox.New(sandbox.Options{
+		Backend:   cfg.Backend,
+		Workspace: workspacePath,
+		Network:   cfg.Network,
+		Limits: sandbox.Limits{
+			MemoryBytes:   uint64(max(cfg.MemoryMB, 0)) * mb,
+			CPUSeconds:    uint64(max(cfg.CPUSeconds, 0)),
+			FileSizeBytes: uint64(max(cfg.MaxFileSizeMB, 0)) * mb,
+			OpenFiles:     uint64(max(cfg.MaxOpenFiles, 0)),
+			Processes:     uint64(max(cfg.MaxProcesses, 0)),
+		},
+	})
+}
+
+func (l *Loop) configurePolicies(cfg *config.Config) error {
+	if len(cfg.Tools.Policies) == 0 {
+		l.tools.SetPolicies(nil)
+		return nil
+	}
+	policies := make([]tools.ToolPolicy, 0, len(cfg.Tools.Policies))
+	for _, p := range cfg.Tools.Policies {
+		policies = append(policies, tools.ToolPolicy{
+			Channel:   p.Channel,
+			SenderID:  p.SenderID,
+			Session:   p.Session,
+			Allow:     p.Allow,
+			Deny:      p.Deny,
+			ExecAllow: p.ExecAllow,
+			Paths:     p.Paths,
+		})
+	}
+	set, err := tools.NewPolicySet(policies, l.workspacePath)
+	if err != nil {
+		return err
+	}
+	l.tools.SetPolicies(set)
 	return nil
 }
 
+func (l *Loop) configureApprovals(cfg *config.Config) error {
+	approval := cfg.Tools.Approval
+	timeout := time.Duration(approval.Timeout) * time.Second
+
+	var rules []tools.ApprovalRule
+	if approval.Enabled {
+		for _, r := range approval.Rules {
+			rules = append(rules, tools.ApprovalRule{Tool: r.Tool, Pattern: r.Pattern})
+		}
+	}
+	return l.tools.Approvals().SetRules(rules, timeout)
+}
+
+// SetApprover registers who approves tool calls originating from a channel
+func (l *Loop) SetApprover(channel string, approver tools.Approver) {
+	l.tools.Approvals().SetApprover(channel, approver)
+}
+
 func (l *Loop) bindTools(ctx context.Context) error {
 	if l.model == nil {
 		return nil
@@ -89,22 +411,58 @@ func (l *Loop) bindTools(ctx context.Context) error {
 	return nil
 }
 
-// Run starts the agent loop
+// Tools returns the registry of the agent's tools
+func (l *Loop) Tools() *tools.Registry {
+	return l.tools
+}
+
+// WorkspacePath returns the workspace the agent works in
+func (l *Loop) WorkspacePath() string {
+	return l.workspacePath
+}
+
+// EndSession kills the background processes and drops the cached web pages
+// of a session; chats end their session with ResetCommand
+func (l *Loop) EndSession(key string) {
+	l.processes.EndSession(key)
+	if l.fetchCache != nil {
+		l.fetchCache.EndSession(key)
+	}
+}
+
+// Close releases resources held for all sessions, stops MCP servers and
+// closes the session store
+func (l *Loop) Close() {
+	l.processes.Close()
+	for _, c := range l.mcpClients {
+		c.Close()
+	}
+	l.sessions.Close()
+}
+
+// Run starts the agent loop; background processes are killed when it returns
 func (l *Loop) Run(ctx context.Context) error {
+	defer l.Close()
+
 	if err := l.bindTools(ctx); err != nil {
 		return err
 	}
 
 	slog.Info("agent loop started")
+	l.serve(ctx, l.bus.Inbound())
+	return ctx.Err()
+}
 
+// serve answers messages from in one at a time until ctx is done
+func (l *Loop) serve(ctx context.Context, in <-chan *bus.InboundMessage) {
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case msg := <-l.bus.Inbound():
+			return
+		case msg := <-in:
 			resp, err := l.processMessage(ctx, msg)
 			if err != nil {
-				slog.Error("process message failed", "error", err)
+				slog.Error("process message failed", "agent", l.name, "error", err)
 				l.bus.PublishOutbound(&bus.OutboundMessage{
 					Channel: msg.Channel,
 					ChatID:  msg.ChatID,
@@ -120,27 +478,86 @@ func (l *Loop) Run(ctx context.Context) error {
 }
 
 func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bus.OutboundMessage, error) {
-	slog.Info("processing message", "channel", msg.Channel, "sender", msg.SenderID)
+	l.turn.Lock()
+	defer l.turn.Unlock()
+
+	slog.Info("processing message", "channel", msg.Channel, "sender", msg.SenderID, "agent", l.name)
+
+	if strings.TrimSpace(msg.Content) == ResetCommand {
+		return l.resetSession(ctx, msg)
+	}
 
 	sess := l.sessions.GetOrCreate(msg.SessionKey())
+	ctx = tools.WithInvocation(ctx, tools.Invocation{
+		Channel:  msg.Channel,
+		ChatID:   msg.ChatID,
+		SenderID: msg.SenderID,
+		Agent:    l.name,
+	})
 
-	messages := l.context.BuildMessages(sess.GetHistory(50), msg.Content, msg.Media)
+	messages := l.context.BuildMessages(ctx, sess.Key, sess.GetHistory(historyMessages), msg.Content, msg.Media)
 
-	var finalContent string
+	// Tools are bound per call so channel policies decide what the model sees
+	toolInfos, err := l.tools.GetToolInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	finalContent, _, err := l.generate(ctx, messages, toolInfos, l.maxIterations, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if finalContent == "" {
+		finalContent = "Processing complete."
+	}
 
-	for i := 0; i < l.maxIterations; i++ {
+	sess.AddMessage("user", msg.Content)
+	sess.AddMessage("assistant", finalContent)
+	l.sessions.Save(sess)
+
+	return &bus.OutboundMessage{
+		Channel: msg.Channel,
+		ChatID:  msg.ChatID,
+		Content: finalContent,
+	}, nil
+}
+
+// resetSession ends a session and deletes its stored messages
+func (l *Loop) resetSession(ctx context.Context, msg *bus.InboundMessage) (*bus.OutboundMessage, error) {
+	key := msg.SessionKey()
+	l.EndSession(key)
+	if err := l.sessions.Delete(ctx, key); err != nil && !errors.Is(err, session.ErrNotFound) {
+		return nil, err
+	}
+	slog.Info("session reset", "session", key, "agent", l.name)
+	return &bus.OutboundMessage{
+		Channel: msg.Channel,
+		ChatID:  msg.ChatID,
+		Content: "Started a new conversation.",
+	}, nil
+}
+
+// generate calls the model and runs the tools it asks for until it answers
+// or maxIterations model calls are used. When allowed is set, calls to other
+// tools are refused. It returns the answer and the number of model calls.
+func (l *Loop) generate(ctx context.Context, messages []*schema.Message, toolInfos []*schema.ToolInfo, maxIterations int, allowed map[string]bool) (string, int, error) {
+	var finalContent string
+	i := 0
+	for ; i < maxIterations; i++ {
 		if l.model == nil {
 			finalContent = "No model configured"
 			break
 		}
 
-		resp, err := l.model.Generate(ctx, messages)
+		resp, err := l.model.Generate(ctx, messages, model.WithTools(toolInfos))
 		if err != nil {
-			return nil, err
+			return "", i, err
 		}
 
 		if len(resp.ToolCalls) == 0 {
 			finalContent = resp.Content
+			i++
 			break
 		}
 
@@ -153,7 +570,13 @@ func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bu
 				l.OnToolStart(tc.Function.Name, tc.Function.Arguments)
 			}
 
-			result, err := l.tools.Execute(ctx, tc.Function.Name, tc.Function.Arguments)
+			var result string
+			var err error
+			if allowed != nil && !allowed[tc.Function.Name] {
+				err = fmt.Errorf("tool %s is not available here", tc.Function.Name)
+			} else {
+				result, err = l.tools.Execute(ctx, tc.Function.Name, tc.Function.Arguments)
+			}
 			if err != nil {
 				result = "Error: " + err.Error()
 			}
@@ -169,20 +592,14 @@ func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bu
 			})
 		}
 	}
+	return finalContent, i, nil
+}
 
-	if finalContent == "" {
-		finalContent = "Processing complete."
-	}
-
-	sess.AddMessage("user", msg.Content)
-	sess.AddMessage("assistant", finalContent)
-	l.sessions.Save(sess)
-
-	return &bus.OutboundMessage{
-		Channel: msg.Channel,
-		ChatID:  msg.ChatID,
-		Content: finalContent,
-	}, nil
+// ProcessMessage runs one turn for msg and returns the reply without
+// publishing it, for callers that decide themselves whether to deliver it.
+// It waits for a turn the loop is already running.
+func (l *Loop) ProcessMessage(ctx context.Context, msg *bus.InboundMessage) (*bus.OutboundMessage, error) {
+	return l.processMessage(ctx, msg)
 }
 
 // ProcessDirect processes a message directly (for CLI)
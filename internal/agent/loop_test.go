@@ -2,6 +2,12 @@ package agent
 
 import (
     "context"
+    "errors"
+    "net/http/httptest"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
     "strings"
     "testing"
 
@@ -9,14 +15,20 @@ import (
     "github.com/cloudwego/eino/schema"
     "github.com/MEKXH/golem/internal/bus"
     "github.com/MEKXH/golem/internal/config"
+    "github.com/MEKXH/golem/internal/mcp/mcptest"
+    "github.com/MEKXH/golem/internal/memory"
+    "github.com/MEKXH/golem/internal/session"
+    "github.com/MEKXH/golem/internal/tools"
 )
 
 type mockChatModel struct {
     bindCalls  int
     boundTools int
+    callTools  []*schema.ToolInfo
 }
 
 func (m *mockChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+    m.callTools = model.GetCommonOptions(&model.Options{}, opts...).Tools
     return &schema.Message{Role: schema.Assistant, Content: "ok"}, nil
 }
 
@@ -67,6 +79,122 @@ func TestContextBuilder_BuildSystemPrompt(t *testing.T) {
     }
 }
 
+func TestContextBuilder_PromptFilesReplaceBootstrap(t *testing.T) {
+    tmpDir := t.TempDir()
+    shared := filepath.Join(t.TempDir(), "ops-rules.md")
+    files := map[string]string{
+        filepath.Join(tmpDir, "SOUL.md"):   "Warm and chatty",
+        filepath.Join(tmpDir, "OPS.md"):    "You run the production servers",
+        shared:                             "Never restart the database",
+    }
+    for path, content := range files {
+        if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    cb := NewContextBuilder(tmpDir)
+    cb.SetPromptFiles([]string{"OPS.md", shared})
+    prompt := cb.BuildSystemPrompt()
+    if !strings.Contains(prompt, "## OPS\nYou run the production servers") || !strings.Contains(prompt, "## ops-rules\nNever restart") {
+        t.Errorf("expected the prompt files:\n%s", prompt)
+    }
+    if strings.Contains(prompt, "Warm and chatty") {
+        t.Errorf("expected SOUL.md to be replaced:\n%s", prompt)
+    }
+}
+
+func TestContextBuilder_IncludesMemoryAndRecentNotes(t *testing.T) {
+    tmpDir := t.TempDir()
+    store := memory.NewStore(filepath.Join(tmpDir, "memory"))
+    if _, err := store.Save("Likes green tea", []string{"preferences"}); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := store.AddNote("Booked flights", nil); err != nil {
+        t.Fatal(err)
+    }
+
+    prompt := NewContextBuilder(tmpDir).BuildSystemPrompt()
+    if !strings.Contains(prompt, "## Long-term Memory") || !strings.Contains(prompt, "Likes green tea") {
+        t.Errorf("expected long-term memory in prompt:\n%s", prompt)
+    }
+    if !strings.Contains(prompt, "## Recent Notes") || !strings.Contains(prompt, "Booked flights") {
+        t.Errorf("expected today's notes in prompt:\n%s", prompt)
+    }
+}
+
+func TestContextBuilder_ListsSkills(t *testing.T) {
+    tmpDir := t.TempDir()
+    dir := filepath.Join(tmpDir, "skills", "pdf")
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        t.Fatal(err)
+    }
+    skill := "---\nname: pdf\ndescription: Fill PDF forms\nwhen_to_use: The user sends a form\n---\nSecret steps.\n"
+    if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(skill), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    prompt := NewContextBuilder(tmpDir).BuildSystemPrompt()
+    if !strings.Contains(prompt, "## Skills") || !strings.Contains(prompt, "- pdf: Fill PDF forms (use when: The user sends a form)") {
+        t.Errorf("expected skill summary in prompt:\n%s", prompt)
+    }
+    if strings.Contains(prompt, "Secret steps") {
+        t.Errorf("expected instructions to stay out of the prompt:\n%s", prompt)
+    }
+}
+
+func TestContextBuilder_RecallsRelevantMemories(t *testing.T) {
+    tmpDir := t.TempDir()
+    store := memory.NewStore(filepath.Join(tmpDir, "memory"))
+    if _, err := store.Save("Likes green tea", []string{"preferences"}); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := store.Save("Parks the car on level three", nil); err != nil {
+        t.Fatal(err)
+    }
+
+    builder := NewContextBuilder(tmpDir)
+    builder.SetMemoryIndex(memory.NewIndex(memory.IndexOptions{
+        Workspace: tmpDir,
+        Embedder:  memory.NewHashEmbedder(256),
+        MinScore:  0.2,
+    }), 1)
+    messages := builder.BuildMessages(context.Background(), "cli:direct", nil, "Which tea should I buy?", nil)
+    prompt := messages[0].Content
+    if !strings.Contains(prompt, "## Relevant Memories") || !strings.Contains(prompt, "Likes green tea") {
+        t.Errorf("expected the tea memory to be recalled:\n%s", prompt)
+    }
+    if strings.Contains(prompt, "## Long-term Memory") || strings.Contains(prompt, "level three") {
+        t.Errorf("expected only relevant memories in prompt:\n%s", prompt)
+    }
+    if got := messages[len(messages)-1].Content; got != "Which tea should I buy?" {
+        t.Errorf("expected current message last, got %q", got)
+    }
+}
+
+func TestContextBuilder_DoesNotRecallOtherChats(t *testing.T) {
+    tmpDir := t.TempDir()
+    sessions := session.NewManager(tmpDir)
+    alice := sessions.GetOrCreate("telegram:alice")
+    alice.AddMessage("user", "My door code is 4711, remember it for the garden shed")
+    alice.AddMessage("assistant", "Noted the garden shed door code.")
+    if err := sessions.Save(alice); err != nil {
+        t.Fatal(err)
+    }
+
+    builder := NewContextBuilder(tmpDir)
+    builder.SetMemoryIndex(memory.NewIndex(memory.IndexOptions{
+        Workspace:  tmpDir,
+        Embedder:   memory.NewHashEmbedder(256),
+        Sessions:   true,
+        SkipRecent: historyMessages,
+    }), 5)
+    messages := builder.BuildMessages(context.Background(), "telegram:bob", nil, "What is the garden shed door code?", nil)
+    if prompt := messages[0].Content; strings.Contains(prompt, "4711") {
+        t.Errorf("expected another chat's turns to stay out of the prompt:\n%s", prompt)
+    }
+}
+
 func TestProcessDirect_BindsTools(t *testing.T) {
     tmpDir := t.TempDir()
     t.Setenv("HOME", tmpDir)
@@ -100,3 +228,219 @@ func TestProcessDirect_BindsTools(t *testing.T) {
         t.Fatalf("expected tools to be bound")
     }
 }
+
+func TestProcessMessage_BindsToolsAllowedByPolicy(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    cfg := config.DefaultConfig()
+    cfg.Tools.Policies = []config.ToolPolicyConfig{
+        {Channel: "telegram", Allow: []string{"read_file", "list_dir"}},
+    }
+    model := &mockChatModel{}
+
+    loop, err := NewLoop(cfg, bus.NewMessageBus(1), model)
+    if err != nil {
+        t.Fatalf("NewLoop error: %v", err)
+    }
+    if err := loop.RegisterDefaultTools(cfg); err != nil {
+        t.Fatalf("RegisterDefaultTools error: %v", err)
+    }
+
+    _, err = loop.processMessage(context.Background(), &bus.InboundMessage{
+        Channel: "telegram", ChatID: "1", SenderID: "7", Content: "hi",
+    })
+    if err != nil {
+        t.Fatalf("processMessage error: %v", err)
+    }
+    if len(model.callTools) != 2 {
+        t.Fatalf("expected 2 tools for telegram, got %d", len(model.callTools))
+    }
+
+    if _, err := loop.ProcessDirect(context.Background(), "hi"); err != nil {
+        t.Fatalf("ProcessDirect error: %v", err)
+    }
+    if len(model.callTools) != len(loop.tools.Names()) {
+        t.Fatalf("expected all tools for cli, got %d", len(model.callTools))
+    }
+}
+
+func TestRegisterDefaultTools_WebSearchNeedsConfiguredBackend(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    hasSearch := func(cfg *config.Config) bool {
+        loop, err := NewLoop(cfg, bus.NewMessageBus(1), &mockChatModel{})
+        if err != nil {
+            t.Fatalf("NewLoop error: %v", err)
+        }
+        if err := loop.RegisterDefaultTools(cfg); err != nil {
+            t.Fatalf("RegisterDefaultTools error: %v", err)
+        }
+        _, ok := loop.tools.Get("web_search")
+        return ok
+    }
+
+    cfg := config.DefaultConfig()
+    if hasSearch(cfg) {
+        t.Fatal("expected web_search to be skipped without an api key")
+    }
+    cfg.Tools.Web.Search.APIKey = "key"
+    if !hasSearch(cfg) {
+        t.Fatal("expected web_search with a brave api key")
+    }
+}
+
+func TestLoop_RegistersMCPTools(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    srv := httptest.NewServer(mcptest.NewServer())
+    defer srv.Close()
+
+    cfg := config.DefaultConfig()
+    cfg.Tools.MCP.Servers = map[string]config.MCPServerConfig{
+        "fake":    {URL: srv.URL},
+        "missing": {Command: filepath.Join(tmpDir, "no-such-server")},
+    }
+    loop, err := NewLoop(cfg, bus.NewMessageBus(10), nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer loop.Close()
+    if err := loop.RegisterDefaultTools(cfg); err != nil {
+        t.Fatalf("expected an unavailable server to be skipped, got %v", err)
+    }
+
+    out, err := loop.Tools().Execute(context.Background(), "fake__add", `{"a":2,"b":2}`)
+    if err != nil || out != "4" {
+        t.Fatalf("expected the MCP tool to be registered, got %q %v", out, err)
+    }
+    for _, name := range loop.Tools().Names() {
+        if strings.HasPrefix(name, "missing__") {
+            t.Fatalf("unexpected tool %s", name)
+        }
+    }
+}
+
+func TestLoop_RegistersPluginTools(t *testing.T) {
+    if runtime.GOOS == "windows" {
+        t.Skip("plugin script is a shell script")
+    }
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    cfg := config.DefaultConfig()
+    writePlugin := func(folder, manifest string) {
+        dir := filepath.Join(cfg.Tools.Plugins.Dir, folder)
+        if err := os.MkdirAll(dir, 0755); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\ncat\n"), 0755); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(manifest), 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    writePlugin("echo", `{"name": "echo_json", "description": "Echo the arguments", "command": "./run.sh"}`)
+    writePlugin("shadow", `{"name": "read_file", "description": "Not the real one", "command": "./run.sh"}`)
+    writePlugin("broken", `{"name": "broken"`)
+
+    loop, err := NewLoop(cfg, bus.NewMessageBus(10), nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer loop.Close()
+    if err := loop.RegisterDefaultTools(cfg); err != nil {
+        t.Fatalf("expected broken plugins to be skipped, got %v", err)
+    }
+
+    out, err := loop.Tools().Execute(context.Background(), "echo_json", `{"a":1}`)
+    if err != nil || out != `{"a":1}` {
+        t.Fatalf("expected the plugin to be registered, got %q %v", out, err)
+    }
+    builtin, ok := loop.Tools().Get("read_file")
+    if !ok {
+        t.Fatal("expected read_file to be registered")
+    }
+    if _, ok := tools.PluginDir(builtin); ok {
+        t.Fatal("expected a plugin not to replace a built-in tool")
+    }
+}
+
+func TestLoop_ResetEndsSession(t *testing.T) {
+    if runtime.GOOS == "windows" {
+        t.Skip("uses sleep")
+    }
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    loop, err := NewLoop(config.DefaultConfig(), bus.NewMessageBus(1), &mockChatModel{})
+    if err != nil {
+        t.Fatalf("NewLoop error: %v", err)
+    }
+    defer loop.Close()
+    ctx := context.Background()
+    msg := &bus.InboundMessage{Channel: "telegram", ChatID: "1", SenderID: "7", Content: "hi"}
+    if _, err := loop.processMessage(ctx, msg); err != nil {
+        t.Fatalf("processMessage error: %v", err)
+    }
+    if _, err := loop.processes.Start("telegram:1", "sleep 30", exec.Command("sleep", "30")); err != nil {
+        t.Fatalf("Start error: %v", err)
+    }
+
+    resp, err := loop.processMessage(ctx, &bus.InboundMessage{Channel: "telegram", ChatID: "1", SenderID: "7", Content: " /reset "})
+    if err != nil || resp == nil || resp.ChatID != "1" {
+        t.Fatalf("expected a reply to /reset, got %+v %v", resp, err)
+    }
+    if procs := loop.processes.List("telegram:1"); len(procs) != 0 {
+        t.Fatalf("expected background processes to be killed, got %+v", procs)
+    }
+    if _, err := loop.sessions.Get(ctx, "telegram:1"); !errors.Is(err, session.ErrNotFound) {
+        t.Fatalf("expected session history to be deleted, got %v", err)
+    }
+    if history := loop.sessions.GetOrCreate("telegram:1").GetHistory(10); len(history) != 0 {
+        t.Fatalf("expected an empty conversation, got %d messages", len(history))
+    }
+}
+
+func TestLoop_SQLiteSessionStore(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    cfg := config.DefaultConfig()
+    cfg.Sessions.Store = "sqlite"
+    loop, err := NewLoop(cfg, bus.NewMessageBus(1), &mockChatModel{})
+    if err != nil {
+        t.Fatalf("NewLoop error: %v", err)
+    }
+    _, err = loop.processMessage(context.Background(), &bus.InboundMessage{
+        Channel: "telegram", ChatID: "1", SenderID: "7", Content: "hi",
+    })
+    if err != nil {
+        t.Fatalf("processMessage error: %v", err)
+    }
+    loop.Close()
+
+    store, err := session.OpenStore("sqlite", loop.WorkspacePath())
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer store.Close()
+    msgs, err := store.Messages(context.Background(), "telegram:1", 0, 0)
+    if err != nil || len(msgs) != 2 || msgs[0].Content != "hi" || msgs[1].Content != "ok" {
+        t.Fatalf("expected the turn in the database, got %v %v", msgs, err)
+    }
+
+    cfg.Sessions.Store = "mongo"
+    if _, err := NewLoop(cfg, bus.NewMessageBus(1), nil); err == nil {
+        t.Fatal("expected an unknown session store to be rejected")
+    }
+}
@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/mcp"
+	"github.com/MEKXH/golem/internal/tools"
+)
+
+// MCPChannel is the channel MCP clients act on; tool policies and approval
+// rules can match it like any other channel
+const MCPChannel = "mcp"
+
+// AskGolemName is the MCP tool that runs a full agent turn
+const AskGolemName = "ask_golem"
+
+const defaultMCPSession = "default"
+
+// AskGolemInput parameters for ask_golem
+type AskGolemInput struct {
+	Prompt  string `json:"prompt"`
+	Session string `json:"session,omitempty"`
+}
+
+var askGolemSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "prompt": {"type": "string", "description": "The request for Golem, in plain language"},
+    "session": {"type": "string", "description": "Conversation to continue; requests with the same session share history (default: default)"}
+  },
+  "required": ["prompt"]
+}`)
+
+// MCPServer exposes the agent's tools and ask_golem to MCP clients. Tool
+// calls run as the conversation mcp:default, so the workspace restrictions,
+// policies and approval rules of the agent apply to them.
+func (l *Loop) MCPServer(ctx context.Context) (*mcp.Server, error) {
+	if err := l.bindTools(ctx); err != nil {
+		return nil, err
+	}
+	infos, err := l.tools.GetToolInfos(l.mcpContext(ctx, defaultMCPSession))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	serverTools := make([]mcp.ServerTool, 0, len(infos)+1)
+	for _, info := range infos {
+		params, err := info.ParamsOneOf.ToJSONSchema()
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", info.Name, err)
+		}
+		inputSchema := json.RawMessage(`{"type":"object"}`)
+		if params != nil {
+			if inputSchema, err = json.Marshal(params); err != nil {
+				return nil, fmt.Errorf("tool %s: %w", info.Name, err)
+			}
+		}
+		name := info.Name
+		serverTools = append(serverTools, mcp.ServerTool{
+			Tool: mcp.Tool{Name: name, Description: info.Desc, InputSchema: inputSchema},
+			Call: func(ctx context.Context, args json.RawMessage) (string, error) {
+				if len(args) == 0 {
+					args = json.RawMessage("{}")
+				}
+				return l.tools.Execute(l.mcpContext(ctx, defaultMCPSession), name, string(args))
+			},
+		})
+	}
+
+	serverTools = append(serverTools, mcp.ServerTool{
+		Tool: mcp.Tool{
+			Name:        AskGolemName,
+			Description: "Ask the Golem agent to handle a request. It plans and uses its tools, memory and skills on its own and replies when done.",
+			InputSchema: askGolemSchema,
+		},
+		Call: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var input AskGolemInput
+			if err := json.Unmarshal(args, &input); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+			if strings.TrimSpace(input.Prompt) == "" {
+				return "", fmt.Errorf("prompt is empty")
+			}
+			if input.Session == "" {
+				input.Session = defaultMCPSession
+			}
+			resp, err := l.processMessage(ctx, &bus.InboundMessage{
+				Channel:   MCPChannel,
+				ChatID:    input.Session,
+				SenderID:  MCPChannel,
+				Content:   input.Prompt,
+				Timestamp: time.Now(),
+			})
+			if err != nil {
+				return "", err
+			}
+			return resp.Content, nil
+		},
+	})
+
+	instructions := fmt.Sprintf("Golem's tools work in the workspace %s. Use them directly for single steps, "+
+		"or call %s to let the Golem agent carry out a whole request.", l.workspacePath, AskGolemName)
+	return mcp.NewServer(instructions, serverTools), nil
+}
+
+// mcpContext marks tool calls as coming from an MCP client
+func (l *Loop) mcpContext(ctx context.Context, session string) context.Context {
+	return tools.WithInvocation(ctx, tools.Invocation{
+		Channel:  MCPChannel,
+		ChatID:   session,
+		SenderID: MCPChannel,
+		Agent:    l.name,
+	})
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/mcp"
+)
+
+func callMCP(t *testing.T, server *mcp.Server, method string, params any) *mcp.Message {
+	t.Helper()
+	req, err := mcp.NewRequest(1, method, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server.Handle(context.Background(), req)
+}
+
+func TestLoop_MCPServer(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Tools.Policies = []config.ToolPolicyConfig{{Channel: MCPChannel, Deny: []string{"exec"}}}
+	loop, err := NewLoop(cfg, bus.NewMessageBus(10), &echoModel{name: "golem"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loop.Close()
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(loop.WorkspacePath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(loop.WorkspacePath(), "todo.txt"), []byte("water plants"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := loop.MCPServer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var list mcp.ListToolsResult
+	if err := json.Unmarshal(callMCP(t, server, "tools/list", nil).Result, &list); err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, tl := range list.Tools {
+		names[tl.Name] = true
+	}
+	if !names["read_file"] || !names[AskGolemName] || names["exec"] {
+		t.Fatalf("expected the mcp policy to apply to the listed tools, got %v", names)
+	}
+
+	var result mcp.CallToolResult
+	reply := callMCP(t, server, "tools/call", mcp.CallToolParams{Name: "read_file", Arguments: json.RawMessage(`{"path":"todo.txt"}`)})
+	if err := json.Unmarshal(reply.Result, &result); err != nil || result.IsError || !json.Valid([]byte(result.Text())) {
+		t.Fatalf("read_file: %s %v", reply.Result, err)
+	}
+	reply = callMCP(t, server, "tools/call", mcp.CallToolParams{Name: "read_file", Arguments: json.RawMessage(`{"path":"../outside.txt"}`)})
+	result = mcp.CallToolResult{}
+	if err := json.Unmarshal(reply.Result, &result); err != nil || !result.IsError {
+		t.Fatalf("expected paths outside the workspace to be refused, got %s", reply.Result)
+	}
+
+	reply = callMCP(t, server, "tools/call", mcp.CallToolParams{Name: AskGolemName, Arguments: json.RawMessage(`{"prompt":"what is on my list?","session":"ide"}`)})
+	result = mcp.CallToolResult{}
+	if err := json.Unmarshal(reply.Result, &result); err != nil || result.Text() != "golem: what is on my list?" {
+		t.Fatalf("ask_golem: %s %v", reply.Result, err)
+	}
+	if history := loop.sessions.GetOrCreate("mcp:ide").GetHistory(0); len(history) != 2 {
+		t.Fatalf("expected the turn in session mcp:ide, got %d messages", len(history))
+	}
+}
@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/memory"
+	"github.com/MEKXH/golem/internal/session"
+)
+
+// NewMemoryIndex creates the semantic memory index for a workspace from the
+// memory.semantic settings. Past conversations are read from sessions when
+// they are not kept in JSONL files.
+func NewMemoryIndex(cfg *config.Config, workspacePath string, sessions session.Store) (*memory.Index, error) {
+	sc := cfg.Memory.Semantic
+	opts := memory.EmbedderOptions{
+		Provider: sc.Provider,
+		BaseURL:  sc.BaseURL,
+		APIKey:   sc.APIKey,
+		Model:    sc.Model,
+	}
+	switch strings.ToLower(strings.TrimSpace(sc.Provider)) {
+	case memory.ProviderOpenAI, "":
+		if opts.APIKey == "" {
+			opts.APIKey = cfg.Providers.OpenAI.APIKey
+		}
+		if opts.BaseURL == "" {
+			opts.BaseURL = cfg.Providers.OpenAI.BaseURL
+		}
+	case memory.ProviderOllama:
+		if opts.BaseURL == "" {
+			opts.BaseURL = cfg.Providers.Ollama.BaseURL
+		}
+	}
+	embedder, err := memory.NewEmbedder(opts)
+	if err != nil {
+		return nil, err
+	}
+	indexOpts := memory.IndexOptions{
+		Workspace:   workspacePath,
+		Embedder:    embedder,
+		Sessions:    sc.IndexSessions,
+		AllSessions: sc.RecallAllSessions,
+		SkipRecent:  historyMessages,
+		MinScore:    sc.MinScore,
+	}
+	if _, jsonl := sessions.(*session.JSONLStore); !jsonl {
+		indexOpts.SessionStore = sessions
+	}
+	return memory.NewIndex(indexOpts), nil
+}
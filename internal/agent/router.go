@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+)
+
+// Route sends matching messages to an agent. Empty match fields (or "*")
+// match anything; Prefix must start the message and is stripped from it.
+type Route struct {
+	Agent    string
+	Channel  string
+	ChatID   string
+	SenderID string
+	Prefix   string
+}
+
+func (r Route) match(msg *bus.InboundMessage) (string, bool) {
+	if !matchField(r.Channel, msg.Channel) || !matchField(r.ChatID, msg.ChatID) || !matchField(r.SenderID, msg.SenderID) {
+		return "", false
+	}
+	if r.Prefix == "" {
+		return msg.Content, true
+	}
+	content := strings.TrimLeftFunc(msg.Content, unicode.IsSpace)
+	if len(content) < len(r.Prefix) || !strings.EqualFold(content[:len(r.Prefix)], r.Prefix) {
+		return "", false
+	}
+	rest := content[len(r.Prefix):]
+	if rest != "" && !unicode.IsSpace([]rune(rest)[0]) {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+func matchField(want, got string) bool {
+	return want == "" || want == "*" || want == got
+}
+
+// Router runs several agents on one bus, handing each inbound message to the
+// agent its route names. Every agent answers its own messages in order while
+// the others keep working.
+type Router struct {
+	bus    *bus.MessageBus
+	loops  map[string]*Loop
+	routes []Route
+}
+
+// NewRouter creates a router for loops keyed by agent name, which must
+// include config.DefaultAgent for messages no route matches
+func NewRouter(msgBus *bus.MessageBus, loops map[string]*Loop, routes []Route) (*Router, error) {
+	if loops[config.DefaultAgent] == nil {
+		return nil, fmt.Errorf("no %s agent", config.DefaultAgent)
+	}
+	named := make(map[string]*Loop, len(loops))
+	for name, l := range loops {
+		name = strings.ToLower(name)
+		l.name = name
+		named[name] = l
+	}
+	checked := make([]Route, len(routes))
+	for i, r := range routes {
+		r.Agent = strings.ToLower(strings.TrimSpace(r.Agent))
+		if named[r.Agent] == nil {
+			return nil, fmt.Errorf("route %d: unknown agent %q", i+1, r.Agent)
+		}
+		checked[i] = r
+	}
+	return &Router{bus: msgBus, loops: named, routes: checked}, nil
+}
+
+// Route returns the agent for msg and the message it should see. Inbound
+// metadata naming an agent, as scheduled tasks carry, overrides the routes.
+func (r *Router) Route(msg *bus.InboundMessage) (string, *bus.InboundMessage) {
+	if name, ok := msg.Metadata[bus.MetadataAgent].(string); ok && r.loops[strings.ToLower(name)] != nil {
+		return strings.ToLower(name), msg
+	}
+	for _, route := range r.routes {
+		content, ok := route.match(msg)
+		if !ok {
+			continue
+		}
+		if content != msg.Content {
+			routed := *msg
+			routed.Content = content
+			msg = &routed
+		}
+		return route.Agent, msg
+	}
+	return config.DefaultAgent, msg
+}
+
+// Run dispatches inbound messages until ctx is done. It returns once every
+// agent has finished its current turn; their background processes are
+// killed and their session stores closed.
+func (r *Router) Run(ctx context.Context) error {
+	for _, l := range r.loops {
+		defer l.Close()
+		if err := l.bindTools(ctx); err != nil {
+			return fmt.Errorf("agent %s: %w", l.name, err)
+		}
+	}
+
+	// Deferred after the Close calls above, so it runs before them
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	queues := make(map[string]chan *bus.InboundMessage, len(r.loops))
+	for name, l := range r.loops {
+		queues[name] = make(chan *bus.InboundMessage, cap(r.bus.Inbound()))
+		wg.Add(1)
+		go func(l *Loop, in <-chan *bus.InboundMessage) {
+			defer wg.Done()
+			l.serve(ctx, in)
+		}(l, queues[name])
+	}
+	slog.Info("agent router started", "agents", len(r.loops), "routes", len(r.routes))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg := <-r.bus.Inbound():
+			name, routed := r.Route(msg)
+			select {
+			case queues[name] <- routed:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// echoModel answers with its name and the message
+type echoModel struct {
+	name string
+}
+
+func (m *echoModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return &schema.Message{Role: schema.Assistant, Content: m.name + ": " + input[len(input)-1].Content}, nil
+}
+
+func (m *echoModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *echoModel) BindTools(tools []*schema.ToolInfo) error {
+	return nil
+}
+
+func newTestLoops(t *testing.T, names ...string) map[string]*Loop {
+	t.Helper()
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	loops := make(map[string]*Loop)
+	msgBus := bus.NewMessageBus(10)
+	for _, name := range names {
+		cfg := config.DefaultConfig()
+		cfg.Agents.Defaults.WorkspaceMode = "path"
+		cfg.Agents.Defaults.Workspace = filepath.Join(tmpDir, name)
+		loop, err := NewLoop(cfg, msgBus, &echoModel{name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		loops[name] = loop
+	}
+	return loops
+}
+
+func TestRouter_Route(t *testing.T) {
+	loops := newTestLoops(t, config.DefaultAgent, "ops", "home")
+	router, err := NewRouter(bus.NewMessageBus(10), loops, []Route{
+		{Agent: "Ops", Channel: "telegram", ChatID: "-100"},
+		{Agent: "home", Channel: "telegram", SenderID: "alice", Prefix: "/home"},
+		{Agent: "ops", Prefix: "/ops"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		msg     bus.InboundMessage
+		agent   string
+		content string
+	}{
+		{bus.InboundMessage{Channel: "telegram", ChatID: "-100", SenderID: "bob", Content: "disk full?"}, "ops", "disk full?"},
+		{bus.InboundMessage{Channel: "telegram", ChatID: "1", SenderID: "alice", Content: "/HOME buy milk"}, "home", "buy milk"},
+		{bus.InboundMessage{Channel: "telegram", ChatID: "1", SenderID: "bob", Content: "/home buy milk"}, config.DefaultAgent, "/home buy milk"},
+		{bus.InboundMessage{Channel: "cli", ChatID: "direct", Content: "  /ops\nrestart nginx"}, "ops", "restart nginx"},
+		{bus.InboundMessage{Channel: "cli", ChatID: "direct", Content: "/opsec tips"}, config.DefaultAgent, "/opsec tips"},
+		{bus.InboundMessage{Channel: "cli", ChatID: "direct", Content: "hello"}, config.DefaultAgent, "hello"},
+		{bus.InboundMessage{Channel: "cli", ChatID: "direct", Content: "reminder", Metadata: map[string]any{bus.MetadataAgent: "home"}}, "home", "reminder"},
+	}
+	for _, tt := range tests {
+		msg := tt.msg
+		agent, routed := router.Route(&msg)
+		if agent != tt.agent || routed.Content != tt.content {
+			t.Errorf("%q: got %s %q, want %s %q", tt.msg.Content, agent, routed.Content, tt.agent, tt.content)
+		}
+		if msg.Content != tt.msg.Content {
+			t.Errorf("%q: the inbound message was modified", tt.msg.Content)
+		}
+	}
+}
+
+func TestNewRouter_Validates(t *testing.T) {
+	loops := newTestLoops(t, config.DefaultAgent, "ops")
+	if _, err := NewRouter(bus.NewMessageBus(1), loops, []Route{{Agent: "sales"}}); err == nil {
+		t.Error("expected a route to an unknown agent to fail")
+	}
+	delete(loops, config.DefaultAgent)
+	if _, err := NewRouter(bus.NewMessageBus(1), loops, nil); err == nil {
+		t.Error("expected a missing default agent to fail")
+	}
+}
+
+func TestRouter_RunDispatchesToAgents(t *testing.T) {
+	loops := newTestLoops(t, config.DefaultAgent, "ops")
+	msgBus := bus.NewMessageBus(10)
+	for _, l := range loops {
+		l.bus = msgBus
+	}
+	router, err := NewRouter(msgBus, loops, []Route{{Agent: "ops", Prefix: "/ops"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go router.Run(ctx)
+
+	msgBus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "/ops uptime"})
+	msgBus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "2", Content: "hi"})
+
+	replies := map[string]string{}
+	for len(replies) < 2 {
+		select {
+		case out := <-msgBus.Outbound():
+			replies[out.ChatID] = out.Content
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out, got %v", replies)
+		}
+	}
+	if replies["1"] != "ops: uptime" || replies["2"] != "default: hi" {
+		t.Fatalf("unexpected replies %v", replies)
+	}
+
+	// Each agent keeps the conversation in its own workspace
+	if _, err := os.Stat(filepath.Join(loops["ops"].WorkspacePath(), "sessions")); err != nil {
+		t.Fatalf("expected the ops session in its workspace: %v", err)
+	}
+	history := loops["ops"].sessions.GetOrCreate("telegram:1").GetHistory(0)
+	if len(history) != 2 || !strings.Contains(history[0].Content, "uptime") {
+		t.Fatalf("unexpected ops history %+v", history)
+	}
+}
+
+// blockingModel answers only once released, ignoring cancellation
+type blockingModel struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (m *blockingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	close(m.entered)
+	<-m.release
+	return &schema.Message{Role: schema.Assistant, Content: "done"}, nil
+}
+
+func (m *blockingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *blockingModel) BindTools(tools []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestRouter_RunWaitsForTurnsInProgress(t *testing.T) {
+	loops := newTestLoops(t, config.DefaultAgent)
+	msgBus := bus.NewMessageBus(10)
+	m := &blockingModel{entered: make(chan struct{}), release: make(chan struct{})}
+	loops[config.DefaultAgent].bus = msgBus
+	loops[config.DefaultAgent].model = m
+	router, err := NewRouter(msgBus, loops, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- router.Run(ctx) }()
+	msgBus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "hi"})
+	select {
+	case <-m.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("turn did not start")
+	}
+
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("Run returned while a turn was still running")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(m.release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the turn finished")
+	}
+	if history := loops[config.DefaultAgent].sessions.GetOrCreate("telegram:1").GetHistory(0); len(history) == 0 {
+		t.Fatal("expected the finished turn to be saved")
+	}
+}
+
+// overlapModel records whether two turns ever called it at the same time
+type overlapModel struct {
+	active  atomic.Int32
+	overlap atomic.Bool
+}
+
+func (m *overlapModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	if m.active.Add(1) > 1 {
+		m.overlap.Store(true)
+	}
+	time.Sleep(20 * time.Millisecond)
+	m.active.Add(-1)
+	return &schema.Message{Role: schema.Assistant, Content: "ok"}, nil
+}
+
+func (m *overlapModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *overlapModel) BindTools(tools []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestLoop_ProcessMessageWaitsForServedTurns(t *testing.T) {
+	loops := newTestLoops(t, config.DefaultAgent)
+	msgBus := bus.NewMessageBus(10)
+	m := &overlapModel{}
+	loop := loops[config.DefaultAgent]
+	loop.bus = msgBus
+	loop.model = m
+	router, err := NewRouter(msgBus, loops, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go router.Run(ctx)
+
+	for i := 0; i < 3; i++ {
+		msgBus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "hi"})
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := loop.ProcessMessage(ctx, &bus.InboundMessage{Channel: "heartbeat", ChatID: "main", Content: "beat"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-msgBus.Outbound():
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for served replies")
+		}
+	}
+	if m.overlap.Load() {
+		t.Fatal("a direct turn ran alongside a served turn")
+	}
+}
@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/tools"
+	"github.com/cloudwego/eino/schema"
+)
+
+// subAgents runs spawn_agent tasks through the parent loop's model and tool
+// registry, so policies, approvals and output limits apply to them as well.
+// Each task starts from an empty history and never sees spawn_agent.
+type subAgents struct {
+	loop          *Loop
+	maxIterations int
+	maxBackground int
+	timeout       time.Duration
+	running       atomic.Int32
+}
+
+func newSubAgents(l *Loop, cfg config.SpawnToolConfig) *subAgents {
+	return &subAgents{
+		loop:          l,
+		maxIterations: cfg.MaxIterations,
+		maxBackground: cfg.MaxBackground,
+		timeout:       time.Duration(cfg.Timeout) * time.Second,
+	}
+}
+
+// RunSubAgent implements tools.SubAgentRunner
+func (s *subAgents) RunSubAgent(ctx context.Context, input tools.SpawnAgentInput) (*tools.SpawnAgentOutput, error) {
+	budget := s.maxIterations
+	if input.MaxIterations > 0 && input.MaxIterations < budget {
+		budget = input.MaxIterations
+	}
+	toolInfos, allowed, err := s.toolSet(ctx, input.Tools)
+	if err != nil {
+		return nil, err
+	}
+	id := newSubAgentID()
+
+	if !input.Background {
+		runCtx, cancel := s.withTimeout(ctx)
+		defer cancel()
+		return s.run(runCtx, id, input.Task, toolInfos, allowed, budget)
+	}
+
+	inv, ok := tools.InvocationFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("background sub-agents need a conversation to report to")
+	}
+	if int(s.running.Add(1)) > s.maxBackground {
+		s.running.Add(-1)
+		return nil, fmt.Errorf("too many background sub-agents running (max %d)", s.maxBackground)
+	}
+	// The request may finish long before the sub-agent, so only the
+	// invocation and other values are kept from its context
+	runCtx, cancel := s.withTimeout(context.WithoutCancel(ctx))
+	go func() {
+		defer cancel()
+		defer s.running.Add(-1)
+		out, err := s.run(runCtx, id, input.Task, toolInfos, allowed, budget)
+		s.announce(inv, id, out, err)
+	}()
+	return &tools.SpawnAgentOutput{ID: id, Status: tools.SubAgentRunning}, nil
+}
+
+func (s *subAgents) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+// toolSet returns the tools the sub-agent sees: the requested ones, or all
+// the parent may use, minus spawn_agent
+func (s *subAgents) toolSet(ctx context.Context, requested []string) ([]*schema.ToolInfo, map[string]bool, error) {
+	infos, err := s.loop.tools.GetToolInfos(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	available := make(map[string]bool, len(infos))
+	for _, info := range infos {
+		available[info.Name] = true
+	}
+	want := make(map[string]bool, len(requested))
+	for _, name := range requested {
+		if !available[name] {
+			return nil, nil, fmt.Errorf("tool %s is not available", name)
+		}
+		want[name] = true
+	}
+
+	var out []*schema.ToolInfo
+	allowed := make(map[string]bool)
+	for _, info := range infos {
+		if info.Name == tools.SpawnAgentName || (len(want) > 0 && !want[info.Name]) {
+			continue
+		}
+		out = append(out, info)
+		allowed[info.Name] = true
+	}
+	return out, allowed, nil
+}
+
+func (s *subAgents) run(ctx context.Context, id, task string, toolInfos []*schema.ToolInfo, allowed map[string]bool, budget int) (*tools.SpawnAgentOutput, error) {
+	slog.Info("sub-agent started", "id", id, "tools", len(toolInfos), "max_iterations", budget)
+	messages := s.loop.context.BuildSubAgentMessages(task)
+	result, iterations, err := s.loop.generate(ctx, messages, toolInfos, budget, allowed)
+	if err != nil {
+		return nil, fmt.Errorf("sub-agent %s failed: %w", id, err)
+	}
+	out := &tools.SpawnAgentOutput{ID: id, Status: tools.SubAgentCompleted, Result: result, Iterations: iterations}
+	if result == "" && iterations >= budget {
+		out.Status = tools.SubAgentExhausted
+		out.Result = fmt.Sprintf("The sub-agent used all %d iterations without finishing.", budget)
+	}
+	slog.Info("sub-agent finished", "id", id, "status", out.Status, "iterations", iterations)
+	return out, nil
+}
+
+// announce posts a background result to the chat that started it and adds
+// it to that session so the conversation can build on it
+func (s *subAgents) announce(inv tools.Invocation, id string, out *tools.SpawnAgentOutput, err error) {
+	var content string
+	if err != nil {
+		content = fmt.Sprintf("Background task %s failed: %v", id, err)
+	} else {
+		content = fmt.Sprintf("Background task %s %s:\n\n%s", id, out.Status, out.Result)
+	}
+	sess := s.loop.sessions.GetOrCreate(inv.SessionKey())
+	sess.AddMessage("assistant", content)
+	if err := s.loop.sessions.Save(sess); err != nil {
+		slog.Warn("save session failed", "session", inv.SessionKey(), "error", err)
+	}
+	s.loop.bus.PublishOutbound(&bus.OutboundMessage{
+		Channel: inv.Channel,
+		ChatID:  inv.ChatID,
+		Content: content,
+	})
+}
+
+func newSubAgentID() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return "sub-" + hex.EncodeToString(b)
+}
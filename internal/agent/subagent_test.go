@@ -0,0 +1,170 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/tools"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// delegatingModel spawns a sub-agent for every user message; the sub-agent
+// reads notes.txt and summarizes it
+type delegatingModel struct {
+	spawn      tools.SpawnAgentInput
+	mu         sync.Mutex
+	childTools []string
+}
+
+func (m *delegatingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	last := input[len(input)-1]
+	if strings.Contains(input[0].Content, "## Sub-agent") {
+		m.mu.Lock()
+		m.childTools = nil
+		for _, info := range model.GetCommonOptions(&model.Options{}, opts...).Tools {
+			m.childTools = append(m.childTools, info.Name)
+		}
+		m.mu.Unlock()
+		if last.Role == schema.Tool {
+			return &schema.Message{Role: schema.Assistant, Content: "Summary: " + last.Content}, nil
+		}
+		return toolCall("read_file", `{"path":"notes.txt"}`), nil
+	}
+	if last.Role == schema.Tool {
+		return &schema.Message{Role: schema.Assistant, Content: "Parent got " + last.Content}, nil
+	}
+	args, _ := json.Marshal(m.spawn)
+	return toolCall(tools.SpawnAgentName, string(args)), nil
+}
+
+func (m *delegatingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *delegatingModel) BindTools(tools []*schema.ToolInfo) error {
+	return nil
+}
+
+func toolCall(name, args string) *schema.Message {
+	return &schema.Message{
+		Role: schema.Assistant,
+		ToolCalls: []schema.ToolCall{{
+			ID:       "call-" + name,
+			Function: schema.FunctionCall{Name: name, Arguments: args},
+		}},
+	}
+}
+
+func newSpawnTestLoop(t *testing.T, chatModel model.ChatModel) (*Loop, *bus.MessageBus) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	msgBus := bus.NewMessageBus(10)
+	loop, err := NewLoop(cfg, msgBus, chatModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(loop.Close)
+	if err := os.MkdirAll(loop.workspacePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(loop.workspacePath, "notes.txt"), []byte("ship on friday"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return loop, msgBus
+}
+
+func TestSpawnAgent_Foreground(t *testing.T) {
+	chatModel := &delegatingModel{spawn: tools.SpawnAgentInput{Task: "Summarize notes.txt", Tools: []string{"read_file"}}}
+	loop, _ := newSpawnTestLoop(t, chatModel)
+
+	resp, err := loop.ProcessDirect(context.Background(), "what do my notes say?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp, "ship on friday") || !strings.Contains(resp, `"status":"completed"`) {
+		t.Fatalf("expected the sub-agent summary in the parent reply, got %q", resp)
+	}
+	if len(chatModel.childTools) != 1 || chatModel.childTools[0] != "read_file" {
+		t.Fatalf("expected the sub-agent to see only read_file, got %v", chatModel.childTools)
+	}
+
+	// The parent history holds the exchange but none of the sub-agent's turns
+	history := loop.sessions.GetOrCreate("cli:direct").GetHistory(0)
+	if len(history) != 2 {
+		t.Fatalf("expected only the parent turn in the session, got %d messages", len(history))
+	}
+}
+
+func TestSpawnAgent_DefaultToolsExcludeSpawn(t *testing.T) {
+	chatModel := &delegatingModel{spawn: tools.SpawnAgentInput{Task: "Summarize notes.txt"}}
+	loop, _ := newSpawnTestLoop(t, chatModel)
+
+	if _, err := loop.ProcessDirect(context.Background(), "go"); err != nil {
+		t.Fatal(err)
+	}
+	if len(chatModel.childTools) < 2 {
+		t.Fatalf("expected the parent's tools, got %v", chatModel.childTools)
+	}
+	for _, name := range chatModel.childTools {
+		if name == tools.SpawnAgentName {
+			t.Fatal("expected sub-agents not to get spawn_agent")
+		}
+	}
+}
+
+func TestSpawnAgent_BudgetExhausted(t *testing.T) {
+	chatModel := &delegatingModel{spawn: tools.SpawnAgentInput{Task: "Summarize notes.txt", MaxIterations: 1}}
+	loop, _ := newSpawnTestLoop(t, chatModel)
+
+	resp, err := loop.ProcessDirect(context.Background(), "go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp, `"status":"exhausted"`) {
+		t.Fatalf("expected the one-iteration budget to run out, got %q", resp)
+	}
+}
+
+func TestSpawnAgent_BackgroundAnnouncesResult(t *testing.T) {
+	chatModel := &delegatingModel{spawn: tools.SpawnAgentInput{Task: "Summarize notes.txt", Background: true}}
+	loop, msgBus := newSpawnTestLoop(t, chatModel)
+
+	resp, err := loop.ProcessMessage(context.Background(), &bus.InboundMessage{
+		Channel: "telegram", ChatID: "7", SenderID: "u", Content: "summarize in the background",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Content, `"status":"running"`) {
+		t.Fatalf("expected an immediate running status, got %q", resp.Content)
+	}
+
+	select {
+	case out := <-msgBus.Outbound():
+		if out.Channel != "telegram" || out.ChatID != "7" || !strings.Contains(out.Content, "ship on friday") {
+			t.Fatalf("unexpected announcement %+v", out)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the background result")
+	}
+	history := loop.sessions.GetOrCreate("telegram:7").GetHistory(0)
+	if last := history[len(history)-1]; !strings.Contains(last.Content, "ship on friday") {
+		t.Fatalf("expected the result in the chat history, got %q", last.Content)
+	}
+}
@@ -2,6 +2,10 @@ package bus
 
 import "time"
 
+// MetadataAgent is the inbound metadata key naming the agent that should
+// answer, overriding the routing rules
+const MetadataAgent = "agent"
+
 // InboundMessage received from a channel
 type InboundMessage struct {
     Channel   string
@@ -0,0 +1,151 @@
+package telegram
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "strings"
+
+    tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+    "github.com/MEKXH/golem/internal/tools"
+)
+
+const (
+    approvalPrefix    = "approval"
+    maxApprovalArgLen = 1000
+)
+
+// pendingApproval is a request waiting for a button press in its chat
+type pendingApproval struct {
+    req   *tools.ApprovalRequest
+    reply chan tools.ApprovalDecision
+}
+
+// answerableBy reports whether a callback comes from the user who made the
+// request, pressed in the chat the request was sent to
+func (p *pendingApproval) answerableBy(q *tgbotapi.CallbackQuery) bool {
+    return q.From != nil && q.Message != nil && q.Message.Chat != nil &&
+        fmt.Sprintf("%d", q.From.ID) == p.req.Invocation.SenderID &&
+        fmt.Sprintf("%d", q.Message.Chat.ID) == p.req.Invocation.ChatID
+}
+
+// RequestApproval asks the originating chat to confirm a tool call with an
+// inline keyboard. Only the sender of the message that led to the call can answer.
+func (c *Channel) RequestApproval(ctx context.Context, req *tools.ApprovalRequest) (tools.ApprovalDecision, error) {
+    if c.bot == nil {
+        return tools.ApprovalDenied, fmt.Errorf("bot not initialized")
+    }
+
+    reply := make(chan tools.ApprovalDecision, 1)
+    c.mu.Lock()
+    c.approvals[req.ID] = &pendingApproval{req: req, reply: reply}
+    c.mu.Unlock()
+    defer func() {
+        c.mu.Lock()
+        delete(c.approvals, req.ID)
+        c.mu.Unlock()
+    }()
+
+    chatID := parseInt64(req.Invocation.ChatID)
+    msg := tgbotapi.NewMessage(chatID, approvalText(req))
+    msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+        tgbotapi.NewInlineKeyboardButtonData("Approve", approvalData(req.ID, "y")),
+        tgbotapi.NewInlineKeyboardButtonData("Deny", approvalData(req.ID, "n")),
+        tgbotapi.NewInlineKeyboardButtonData("Always allow", approvalData(req.ID, "a")),
+    ))
+    sent, err := c.bot.Send(msg)
+    if err != nil {
+        return tools.ApprovalDenied, fmt.Errorf("send approval request: %w", err)
+    }
+
+    select {
+    case decision := <-reply:
+        return decision, nil
+    case <-ctx.Done():
+        edit := tgbotapi.NewEditMessageText(chatID, sent.MessageID, approvalText(req)+"\n\nApproval timed out.")
+        if _, err := c.bot.Request(edit); err != nil {
+            slog.Debug("telegram edit approval failed", "error", err)
+        }
+        return tools.ApprovalDenied, ctx.Err()
+    }
+}
+
+func (c *Channel) handleCallback(q *tgbotapi.CallbackQuery) {
+    id, decision, ok := parseApprovalData(q.Data)
+    if !ok {
+        return
+    }
+
+    answer := "Unknown or expired request"
+    defer func() {
+        if c.bot != nil {
+            _, _ = c.bot.Request(tgbotapi.NewCallback(q.ID, answer))
+        }
+    }()
+
+    if q.From == nil || !c.IsAllowed(fmt.Sprintf("%d", q.From.ID)) {
+        answer = "Not allowed"
+        return
+    }
+
+    c.mu.Lock()
+    pending, ok := c.approvals[id]
+    if ok && !pending.answerableBy(q) {
+        c.mu.Unlock()
+        answer = "Only the requester can decide"
+        return
+    }
+    delete(c.approvals, id)
+    c.mu.Unlock()
+    if !ok {
+        return
+    }
+    pending.reply <- decision
+
+    answer = decisionLabel(decision)
+    if q.Message != nil && c.bot != nil {
+        edit := tgbotapi.NewEditMessageText(q.Message.Chat.ID, q.Message.MessageID, q.Message.Text+"\n\n"+answer)
+        if _, err := c.bot.Request(edit); err != nil {
+            slog.Debug("telegram edit approval failed", "error", err)
+        }
+    }
+}
+
+func approvalText(req *tools.ApprovalRequest) string {
+    args := req.Args
+    if len(args) > maxApprovalArgLen {
+        args = args[:maxApprovalArgLen] + "..."
+    }
+    return fmt.Sprintf("Approval required: %s\n%s\n\n%s", req.Tool, req.Reason, args)
+}
+
+func approvalData(id, choice string) string {
+    return approvalPrefix + ":" + id + ":" + choice
+}
+
+func parseApprovalData(data string) (string, tools.ApprovalDecision, bool) {
+    parts := strings.Split(data, ":")
+    if len(parts) != 3 || parts[0] != approvalPrefix || parts[1] == "" {
+        return "", tools.ApprovalDenied, false
+    }
+    switch parts[2] {
+    case "y":
+        return parts[1], tools.ApprovalApproved, true
+    case "a":
+        return parts[1], tools.ApprovalAlwaysAllow, true
+    case "n":
+        return parts[1], tools.ApprovalDenied, true
+    }
+    return "", tools.ApprovalDenied, false
+}
+
+func decisionLabel(d tools.ApprovalDecision) string {
+    switch d {
+    case tools.ApprovalApproved:
+        return "Approved"
+    case tools.ApprovalAlwaysAllow:
+        return "Always allowed for this rule in this chat"
+    default:
+        return "Denied"
+    }
+}
@@ -2,10 +2,17 @@ package telegram
 
 import (
     "context"
+    "crypto/rand"
+    "crypto/subtle"
+    "encoding/hex"
+    "encoding/json"
     "fmt"
     "log/slog"
+    "net/http"
+    "net/url"
     "regexp"
     "strings"
+    "sync"
     "time"
 
     tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -14,11 +21,23 @@ import (
     "github.com/MEKXH/golem/internal/config"
 )
 
+const (
+    defaultWebhookPath = "/telegram/webhook"
+    secretTokenHeader  = "X-Telegram-Bot-Api-Secret-Token"
+    maxWebhookBody     = 1 << 20
+)
+
 // Channel implements Telegram bot
 type Channel struct {
     channel.BaseChannel
-    cfg *config.TelegramConfig
-    bot *tgbotapi.BotAPI
+    cfg         *config.TelegramConfig
+    bot         *tgbotapi.BotAPI
+    apiEndpoint string
+    // secretToken authenticates webhook requests; generated when not configured
+    secretToken string
+
+    mu        sync.Mutex
+    approvals map[string]*pendingApproval
 }
 
 // New creates a Telegram channel
@@ -27,19 +46,26 @@ func New(cfg *config.TelegramConfig, msgBus *bus.MessageBus) *Channel {
     for _, id := range cfg.AllowFrom {
         allowList[id] = true
     }
+    secret := cfg.Webhook.SecretToken
+    if cfg.Webhook.Enabled && secret == "" {
+        secret = randomSecretToken()
+    }
     return &Channel{
         BaseChannel: channel.BaseChannel{
             Bus:       msgBus,
             AllowList: allowList,
         },
-        cfg: cfg,
+        cfg:         cfg,
+        apiEndpoint: tgbotapi.APIEndpoint,
+        secretToken: secret,
+        approvals:   make(map[string]*pendingApproval),
     }
 }
 
 func (c *Channel) Name() string { return "telegram" }
 
 func (c *Channel) Start(ctx context.Context) error {
-    bot, err := tgbotapi.NewBotAPI(c.cfg.Token)
+    bot, err := tgbotapi.NewBotAPIWithAPIEndpoint(c.cfg.Token, c.apiEndpoint)
     if err != nil {
         return fmt.Errorf("telegram init failed: %w", err)
     }
@@ -47,23 +73,113 @@ func (c *Channel) Start(ctx context.Context) error {
 
     slog.Info("telegram bot connected", "username", bot.Self.UserName)
 
+    if c.WebhookEnabled() {
+        return c.startWebhook(ctx)
+    }
+    return c.startPolling(ctx)
+}
+
+func (c *Channel) startPolling(ctx context.Context) error {
+    // getUpdates is rejected while a webhook is registered
+    if _, err := c.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+        slog.Warn("telegram delete webhook failed", "error", err)
+    }
+
     u := tgbotapi.NewUpdate(0)
     u.Timeout = 60
-    updates := bot.GetUpdatesChan(u)
+    updates := c.bot.GetUpdatesChan(u)
 
     for {
         select {
         case <-ctx.Done():
             return nil
         case update := <-updates:
-            if update.Message == nil {
-                continue
-            }
-            go c.handleMessage(update.Message)
+            c.handleUpdate(update)
         }
     }
 }
 
+func (c *Channel) startWebhook(ctx context.Context) error {
+    if c.cfg.Webhook.URL == "" {
+        return fmt.Errorf("telegram webhook url is required")
+    }
+
+    if c.secretToken == "" {
+        return fmt.Errorf("telegram webhook secret token is required")
+    }
+
+    params := tgbotapi.Params{"url": c.cfg.Webhook.URL, "secret_token": c.secretToken}
+    if _, err := c.bot.MakeRequest("setWebhook", params); err != nil {
+        return fmt.Errorf("telegram set webhook failed: %w", err)
+    }
+
+    slog.Info("telegram webhook registered", "path", c.WebhookPath())
+
+    <-ctx.Done()
+    return nil
+}
+
+// WebhookEnabled reports whether updates are delivered by webhook
+func (c *Channel) WebhookEnabled() bool {
+    return c.cfg.Webhook.Enabled
+}
+
+// WebhookPath returns the gateway route that receives updates
+func (c *Channel) WebhookPath() string {
+    if c.cfg.Webhook.Path != "" {
+        return c.cfg.Webhook.Path
+    }
+    if u, err := url.Parse(c.cfg.Webhook.URL); err == nil && u.Path != "" && u.Path != "/" {
+        return u.Path
+    }
+    return defaultWebhookPath
+}
+
+// randomSecretToken returns a token for setWebhook, which allows letters,
+// digits, "_" and "-"
+func randomSecretToken() string {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return ""
+    }
+    return hex.EncodeToString(b)
+}
+
+// ServeHTTP receives webhook updates from the Bot API. Requests must carry
+// the secret token registered with setWebhook.
+func (c *Channel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        w.WriteHeader(http.StatusMethodNotAllowed)
+        return
+    }
+    got := r.Header.Get(secretTokenHeader)
+    if c.secretToken == "" || subtle.ConstantTimeCompare([]byte(got), []byte(c.secretToken)) != 1 {
+        slog.Warn("telegram webhook rejected: bad secret token")
+        w.WriteHeader(http.StatusUnauthorized)
+        return
+    }
+
+    var update tgbotapi.Update
+    if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookBody)).Decode(&update); err != nil {
+        w.WriteHeader(http.StatusBadRequest)
+        return
+    }
+
+    c.handleUpdate(update)
+    w.WriteHeader(http.StatusOK)
+}
+
+func (c *Channel) handleUpdate(update tgbotapi.Update) {
+    if update.CallbackQuery != nil {
+        go c.handleCallback(update.CallbackQuery)
+        return
+    }
+    if update.Message == nil {
+        return
+    }
+    go c.handleMessage(update.Message)
+}
+
 func (c *Channel) handleMessage(msg *tgbotapi.Message) {
     senderID := fmt.Sprintf("%d", msg.From.ID)
 
@@ -114,9 +230,16 @@ func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 }
 
 func (c *Channel) Stop(ctx context.Context) error {
-    if c.bot != nil {
-        c.bot.StopReceivingUpdates()
+    if c.bot == nil {
+        return nil
+    }
+    if c.WebhookEnabled() {
+        if _, err := c.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+            return fmt.Errorf("telegram delete webhook failed: %w", err)
+        }
+        return nil
     }
+    c.bot.StopReceivingUpdates()
     return nil
 }
 
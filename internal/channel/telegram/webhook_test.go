@@ -0,0 +1,297 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/tools"
+)
+
+// fakeBotAPI is a minimal stand-in for the Telegram Bot API
+type fakeBotAPI struct {
+	mu    sync.Mutex
+	calls map[string][]map[string]string
+	srv   *httptest.Server
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+	t.Helper()
+	f := &fakeBotAPI{calls: make(map[string][]map[string]string)}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fakeBotAPI) endpoint() string {
+	return f.srv.URL + "/bot%s/%s"
+}
+
+func (f *fakeBotAPI) serve(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	params := make(map[string]string)
+	for k := range r.PostForm {
+		params[k] = r.PostForm.Get(k)
+	}
+	f.mu.Lock()
+	f.calls[method] = append(f.calls[method], params)
+	f.mu.Unlock()
+
+	var result any = true
+	switch method {
+	case "getMe":
+		result = map[string]any{"id": 1, "is_bot": true, "first_name": "golem", "username": "golem_bot"}
+	case "sendMessage":
+		result = map[string]any{"message_id": 7, "date": 0, "chat": map[string]any{"id": 99}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func (f *fakeBotAPI) called(method string) []map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]string(nil), f.calls[method]...)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+func newWebhookChannel(t *testing.T, api *fakeBotAPI, msgBus *bus.MessageBus) *Channel {
+	t.Helper()
+	ch := New(&config.TelegramConfig{
+		Enabled: true,
+		Token:   "test-token",
+		Webhook: config.TelegramWebhookConfig{
+			Enabled:     true,
+			URL:         "https://bot.example.com/hooks/tg",
+			SecretToken: "s3cret",
+		},
+	}, msgBus)
+	ch.apiEndpoint = api.endpoint()
+	return ch
+}
+
+func TestWebhook_LifecycleRegistersAndDeletes(t *testing.T) {
+	api := newFakeBotAPI(t)
+	ch := newWebhookChannel(t, api, bus.NewMessageBus(1))
+
+	if got := ch.WebhookPath(); got != "/hooks/tg" {
+		t.Fatalf("expected path from url, got %q", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ch.Start(ctx) }()
+
+	waitFor(t, func() bool { return len(api.called("setWebhook")) == 1 })
+	set := api.called("setWebhook")[0]
+	if set["url"] != "https://bot.example.com/hooks/tg" {
+		t.Fatalf("unexpected webhook url: %q", set["url"])
+	}
+	if set["secret_token"] != "s3cret" {
+		t.Fatalf("expected secret token to be registered, got %q", set["secret_token"])
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	if err := ch.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop error: %v", err)
+	}
+	if len(api.called("deleteWebhook")) != 1 {
+		t.Fatalf("expected deleteWebhook on stop")
+	}
+}
+
+func TestWebhook_ServeHTTPVerifiesSecret(t *testing.T) {
+	api := newFakeBotAPI(t)
+	msgBus := bus.NewMessageBus(1)
+	ch := newWebhookChannel(t, api, msgBus)
+
+	body := `{"update_id":1,"message":{"message_id":3,"from":{"id":42,"username":"alice"},"chat":{"id":99},"text":"hello"}}`
+
+	tests := []struct {
+		name   string
+		method string
+		secret string
+		want   int
+	}{
+		{name: "missing secret", method: http.MethodPost, secret: "", want: http.StatusUnauthorized},
+		{name: "wrong secret", method: http.MethodPost, secret: "nope", want: http.StatusUnauthorized},
+		{name: "wrong method", method: http.MethodGet, secret: "s3cret", want: http.StatusMethodNotAllowed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/hooks/tg", strings.NewReader(body))
+			if tc.secret != "" {
+				req.Header.Set(secretTokenHeader, tc.secret)
+			}
+			rec := httptest.NewRecorder()
+			ch.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, rec.Code)
+			}
+		})
+	}
+
+	select {
+	case msg := <-msgBus.Inbound():
+		t.Fatalf("rejected update must not reach the bus, got %+v", msg)
+	default:
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/hooks/tg", strings.NewReader(body))
+	req.Header.Set(secretTokenHeader, "s3cret")
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	select {
+	case msg := <-msgBus.Inbound():
+		if msg.Content != "hello" || msg.SenderID != "42" || msg.ChatID != "99" {
+			t.Fatalf("unexpected inbound message: %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for inbound message")
+	}
+}
+
+func TestWebhook_SendUsesBotAPI(t *testing.T) {
+	api := newFakeBotAPI(t)
+	ch := newWebhookChannel(t, api, bus.NewMessageBus(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ch.Start(ctx)
+	waitFor(t, func() bool { return len(api.called("setWebhook")) == 1 })
+
+	if err := ch.Send(ctx, &bus.OutboundMessage{ChatID: "99", Content: "**hi**"}); err != nil {
+		t.Fatalf("Send error: %v", err)
+	}
+	sent := api.called("sendMessage")
+	if len(sent) != 1 || sent[0]["chat_id"] != "99" || sent[0]["text"] != "<b>hi</b>" {
+		t.Fatalf("unexpected sendMessage calls: %+v", sent)
+	}
+}
+
+func TestRequestApproval_InlineKeyboardCallback(t *testing.T) {
+	api := newFakeBotAPI(t)
+	ch := newWebhookChannel(t, api, bus.NewMessageBus(1))
+	ch.AllowList = map[string]bool{"42": true, "43": true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ch.Start(ctx)
+	waitFor(t, func() bool { return len(api.called("setWebhook")) == 1 })
+
+	result := make(chan tools.ApprovalDecision, 1)
+	go func() {
+		d, _ := ch.RequestApproval(ctx, &tools.ApprovalRequest{
+			ID:         "abc",
+			Tool:       "exec",
+			Args:       `{"command":"ls"}`,
+			Invocation: tools.Invocation{Channel: "telegram", ChatID: "99", SenderID: "42"},
+		})
+		result <- d
+	}()
+
+	waitFor(t, func() bool { return len(api.called("sendMessage")) == 1 })
+	if markup := api.called("sendMessage")[0]["reply_markup"]; !strings.Contains(markup, "approval:abc:y") {
+		t.Fatalf("expected inline keyboard, got %q", markup)
+	}
+
+	post := func(from, chat int) {
+		body := fmt.Sprintf(`{"update_id":2,"callback_query":{"id":"cb","from":{"id":%d},"data":"approval:abc:y",`+
+			`"message":{"message_id":7,"chat":{"id":%d},"text":"Approval required"}}}`, from, chat)
+		req := httptest.NewRequest(http.MethodPost, "/hooks/tg", strings.NewReader(body))
+		req.Header.Set(secretTokenHeader, "s3cret")
+		ch.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rejected := []struct {
+		name       string
+		from, chat int
+	}{
+		{"user not in allow_from", 13, 99},
+		{"allowed user who did not ask", 43, 99},
+		{"requester in another chat", 42, 100},
+	}
+	for i, tc := range rejected {
+		post(tc.from, tc.chat)
+		waitFor(t, func() bool { return len(api.called("answerCallbackQuery")) == i+1 })
+		select {
+		case d := <-result:
+			t.Fatalf("%s must not decide, got %v", tc.name, d)
+		default:
+		}
+	}
+
+	post(42, 99)
+	select {
+	case d := <-result:
+		if d != tools.ApprovalApproved {
+			t.Fatalf("expected approved, got %v", d)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for decision")
+	}
+}
+
+func TestWebhook_GeneratesSecretWhenNotConfigured(t *testing.T) {
+	api := newFakeBotAPI(t)
+	msgBus := bus.NewMessageBus(1)
+	ch := New(&config.TelegramConfig{
+		Enabled: true,
+		Token:   "test-token",
+		Webhook: config.TelegramWebhookConfig{Enabled: true, URL: "https://bot.example.com/hooks/tg"},
+	}, msgBus)
+	ch.apiEndpoint = api.endpoint()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ch.Start(ctx)
+	waitFor(t, func() bool { return len(api.called("setWebhook")) == 1 })
+	secret := api.called("setWebhook")[0]["secret_token"]
+	if len(secret) < 32 {
+		t.Fatalf("expected a generated secret token, got %q", secret)
+	}
+
+	body := `{"update_id":1,"message":{"message_id":3,"from":{"id":42},"chat":{"id":99},"text":"hello"}}`
+	req := httptest.NewRequest(http.MethodPost, "/hooks/tg", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	ch.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without secret header, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/hooks/tg", strings.NewReader(body))
+	req.Header.Set(secretTokenHeader, secret)
+	rec = httptest.NewRecorder()
+	ch.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with generated secret, got %d", rec.Code)
+	}
+}
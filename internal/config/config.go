@@ -5,6 +5,7 @@ import (
     "fmt"
     "os"
     "path/filepath"
+    "sort"
     "strings"
 
     "github.com/go-viper/mapstructure/v2"
@@ -18,21 +19,108 @@ type Config struct {
     Providers ProvidersConfig `mapstructure:"providers"`
     Gateway   GatewayConfig   `mapstructure:"gateway"`
     Tools     ToolsConfig     `mapstructure:"tools"`
+    Memory    MemoryConfig    `mapstructure:"memory"`
+    Sessions  SessionsConfig  `mapstructure:"sessions"`
+    Cron      CronConfig      `mapstructure:"cron"`
+    Heartbeat HeartbeatConfig `mapstructure:"heartbeat"`
 }
 
-// AgentsConfig agent settings
+// SessionsConfig conversation storage in <workspace>/sessions: "jsonl"
+// (one file per session) or "sqlite" (sessions.db). Switching does not
+// move existing sessions.
+type SessionsConfig struct {
+    Store string `mapstructure:"store"`
+}
+
+// DefaultAgent is the name of the agent built from agents.defaults
+const DefaultAgent = "default"
+
+// AgentsConfig agent settings; profiles are named agents and routes decide
+// which agent answers a message
 type AgentsConfig struct {
-    Defaults AgentDefaults `mapstructure:"defaults"`
+    Defaults AgentDefaults           `mapstructure:"defaults"`
+    Profiles map[string]AgentProfile `mapstructure:"profiles"`
+    Routes   []AgentRouteConfig      `mapstructure:"routes"`
 }
 
-// AgentDefaults default agent parameters
+// AgentDefaults default agent parameters. prompt_files replace the workspace
+// bootstrap files (IDENTITY.md, SOUL.md, ...) in the system prompt; relative
+// paths are read from the workspace.
 type AgentDefaults struct {
-    Workspace         string  `mapstructure:"workspace"`
-    WorkspaceMode     string  `mapstructure:"workspace_mode"`
+    Workspace         string   `mapstructure:"workspace"`
+    WorkspaceMode     string   `mapstructure:"workspace_mode"`
+    Model             string   `mapstructure:"model"`
+    MaxTokens         int      `mapstructure:"max_tokens"`
+    Temperature       float64  `mapstructure:"temperature"`
+    MaxToolIterations int      `mapstructure:"max_tool_iterations"`
+    PromptFiles       []string `mapstructure:"prompt_files"`
+}
+
+// AgentProfile a named agent; unset fields inherit agents.defaults, and
+// setting workspace implies workspace_mode "path". Tools limits the agent to
+// the listed tool names or globs. Agents sharing a workspace share its
+// memory, skills and sessions.
+type AgentProfile struct {
+    Workspace         string   `mapstructure:"workspace"`
+    WorkspaceMode     string   `mapstructure:"workspace_mode"`
+    Model             string   `mapstructure:"model"`
+    MaxTokens         int      `mapstructure:"max_tokens"`
+    Temperature       *float64 `mapstructure:"temperature"`
+    MaxToolIterations int      `mapstructure:"max_tool_iterations"`
+    PromptFiles       []string `mapstructure:"prompt_files"`
+    Tools             []string `mapstructure:"tools"`
+}
+
+// AgentRouteConfig sends matching messages to agent. Channel, chat_id and
+// sender_id must all match (empty or "*" matches any); prefix, e.g. "/ops",
+// must start the message and is removed before the agent sees it. The first
+// matching route wins; unmatched messages go to the default agent.
+type AgentRouteConfig struct {
+    Agent    string `mapstructure:"agent"`
+    Channel  string `mapstructure:"channel"`
+    ChatID   string `mapstructure:"chat_id"`
+    SenderID string `mapstructure:"sender_id"`
+    Prefix   string `mapstructure:"prefix"`
+}
+
+// MemoryConfig long-term memory settings
+type MemoryConfig struct {
+    Semantic SemanticMemoryConfig `mapstructure:"semantic"`
+}
+
+// SemanticMemoryConfig puts the top_k memories and past conversation turns
+// most similar to each message into the prompt instead of all of MEMORY.md.
+// Provider is openai (any compatible /embeddings endpoint), ollama or hash;
+// api_key and base_url fall back to the matching entry under providers.
+// Conversation turns are only recalled in the chat they come from unless
+// recall_all_sessions is set.
+type SemanticMemoryConfig struct {
+    Enabled           bool    `mapstructure:"enabled"`
+    Provider          string  `mapstructure:"provider"`
+    BaseURL           string  `mapstructure:"base_url"`
+    APIKey            string  `mapstructure:"api_key"`
     Model             string  `mapstructure:"model"`
-    MaxTokens         int     `mapstructure:"max_tokens"`
-    Temperature       float64 `mapstructure:"temperature"`
-    MaxToolIterations int     `mapstructure:"max_tool_iterations"`
+    TopK              int     `mapstructure:"top_k"`
+    MinScore          float64 `mapstructure:"min_score"`
+    IndexSessions     bool    `mapstructure:"index_sessions"`
+    RecallAllSessions bool    `mapstructure:"recall_all_sessions"`
+}
+
+// CronConfig scheduled task settings; jobs fire while `golem run` is running.
+// Timezone (IANA name) applies to tasks that name none; empty means local time.
+type CronConfig struct {
+    Enabled  bool   `mapstructure:"enabled"`
+    Timezone string `mapstructure:"timezone"`
+}
+
+// HeartbeatConfig periodic check-ins while `golem run` is running; interval
+// is seconds. Reports go to channel/chat_id; the tasks are read from
+// HEARTBEAT.md in the workspace.
+type HeartbeatConfig struct {
+    Enabled  bool   `mapstructure:"enabled"`
+    Interval int    `mapstructure:"interval"`
+    Channel  string `mapstructure:"channel"`
+    ChatID   string `mapstructure:"chat_id"`
 }
 
 // ChannelsConfig channel settings
@@ -45,6 +133,16 @@ type TelegramConfig struct {
     Enabled   bool     `mapstructure:"enabled"`
     Token     string   `mapstructure:"token"`
     AllowFrom []string `mapstructure:"allow_from"`
+
+    Webhook TelegramWebhookConfig `mapstructure:"webhook"`
+}
+
+// TelegramWebhookConfig telegram webhook delivery settings
+type TelegramWebhookConfig struct {
+    Enabled     bool   `mapstructure:"enabled"`
+    URL         string `mapstructure:"url"`
+    Path        string `mapstructure:"path"`
+    SecretToken string `mapstructure:"secret_token"`
 }
 
 // ProvidersConfig LLM provider settings
@@ -67,33 +165,152 @@ type ProviderConfig struct {
     BaseURL   string `mapstructure:"base_url"`
 }
 
-// GatewayConfig server settings
+// GatewayConfig server settings. The gateway starts when a channel serves
+// on it, such as a Telegram webhook, or when Enabled is set.
 type GatewayConfig struct {
-    Host string `mapstructure:"host"`
-    Port int    `mapstructure:"port"`
+    Enabled bool   `mapstructure:"enabled"`
+    Host    string `mapstructure:"host"`
+    Port    int    `mapstructure:"port"`
 }
 
 // ToolsConfig tool settings
 type ToolsConfig struct {
-    Web  WebToolsConfig `mapstructure:"web"`
-    Exec ExecToolConfig `mapstructure:"exec"`
+    Web      WebToolsConfig     `mapstructure:"web"`
+    Exec     ExecToolConfig     `mapstructure:"exec"`
+    Approval ApprovalConfig     `mapstructure:"approval"`
+    Policies []ToolPolicyConfig `mapstructure:"policies"`
+    Output   OutputConfig       `mapstructure:"output"`
+    Spawn    SpawnToolConfig    `mapstructure:"spawn"`
+    MCP      MCPConfig          `mapstructure:"mcp"`
+    Plugins  PluginsConfig      `mapstructure:"plugins"`
+}
+
+// PluginsConfig executable plugin tools, one folder with a plugin.json per
+// tool in dir (see PluginsPath)
+type PluginsConfig struct {
+    Enabled bool   `mapstructure:"enabled"`
+    Dir     string `mapstructure:"dir"`
+}
+
+// MCPConfig external MCP servers whose tools are registered as
+// <server>__<tool>; timeout is seconds per request
+type MCPConfig struct {
+    Timeout int                        `mapstructure:"timeout"`
+    Servers map[string]MCPServerConfig `mapstructure:"servers"`
+}
+
+// MCPServerConfig one MCP server: command (with args and KEY=value env
+// entries) starts it over stdio in the workspace, url connects over
+// streamable HTTP with headers. A timeout above zero overrides mcp.timeout.
+type MCPServerConfig struct {
+    Command string            `mapstructure:"command"`
+    Args    []string          `mapstructure:"args"`
+    Env     []string          `mapstructure:"env"`
+    URL     string            `mapstructure:"url"`
+    Headers map[string]string `mapstructure:"headers"`
+    Timeout int               `mapstructure:"timeout"`
+}
+
+// SpawnToolConfig spawn_agent settings; max_iterations caps what a task may
+// ask for, max_background counts running background sub-agents and timeout
+// is seconds per sub-agent (0 for none)
+type SpawnToolConfig struct {
+    Enabled       bool `mapstructure:"enabled"`
+    MaxIterations int  `mapstructure:"max_iterations"`
+    MaxBackground int  `mapstructure:"max_background"`
+    Timeout       int  `mapstructure:"timeout"`
+}
+
+// OutputConfig caps tool results sent to the model; full output spills to
+// artifact_dir in the workspace, which keeps the newest 100 outputs. A negative
+// per_tool value disables the cap.
+type OutputConfig struct {
+    MaxBytes    int            `mapstructure:"max_bytes"`
+    PerTool     map[string]int `mapstructure:"per_tool"`
+    ArtifactDir string         `mapstructure:"artifact_dir"`
 }
 
 // WebToolsConfig web tool settings
 type WebToolsConfig struct {
     Search WebSearchConfig `mapstructure:"search"`
+    Fetch  WebFetchConfig  `mapstructure:"fetch"`
 }
 
-// WebSearchConfig brave search settings
+// WebSearchConfig web search settings; provider is brave, searxng or duckduckgo.
+// base_url overrides the endpoint and is required for searxng.
 type WebSearchConfig struct {
+    Provider   string `mapstructure:"provider"`
     APIKey     string `mapstructure:"api_key"`
+    BaseURL    string `mapstructure:"base_url"`
     MaxResults int    `mapstructure:"max_results"`
 }
 
+// WebFetchConfig web_fetch settings; timeout and cache_ttl are seconds.
+// Private, loopback and link-local addresses are refused unless allow_private is set.
+type WebFetchConfig struct {
+    Enabled      bool `mapstructure:"enabled"`
+    MaxBytes     int  `mapstructure:"max_bytes"`
+    Timeout      int  `mapstructure:"timeout"`
+    AllowPrivate bool `mapstructure:"allow_private"`
+    CacheTTL     int  `mapstructure:"cache_ttl"`
+}
+
 // ExecToolConfig shell exec settings
 type ExecToolConfig struct {
-    Timeout             int  `mapstructure:"timeout"`
-    RestrictToWorkspace bool `mapstructure:"restrict_to_workspace"`
+    Timeout             int                 `mapstructure:"timeout"`
+    RestrictToWorkspace bool                `mapstructure:"restrict_to_workspace"`
+    Sandbox             SandboxConfig       `mapstructure:"sandbox"`
+    DefaultRules        bool                `mapstructure:"default_rules"`
+    Rules               []CommandRuleConfig `mapstructure:"rules"`
+    MaxBackground       int                 `mapstructure:"max_background"`
+}
+
+// CommandRuleConfig classifies commands run by exec; action is allow, ask or deny.
+// Flags entries list alternatives separated by "|"; args and redirect are regexps.
+type CommandRuleConfig struct {
+    Name     string   `mapstructure:"name"`
+    Action   string   `mapstructure:"action"`
+    Program  string   `mapstructure:"program"`
+    Flags    []string `mapstructure:"flags"`
+    Args     []string `mapstructure:"args"`
+    Redirect string   `mapstructure:"redirect"`
+}
+
+// SandboxConfig linux isolation for exec; zero limits mean unlimited
+type SandboxConfig struct {
+    Enabled       bool   `mapstructure:"enabled"`
+    Backend       string `mapstructure:"backend"`
+    Network       bool   `mapstructure:"network"`
+    MemoryMB      int    `mapstructure:"memory_mb"`
+    CPUSeconds    int    `mapstructure:"cpu_seconds"`
+    MaxFileSizeMB int    `mapstructure:"max_file_size_mb"`
+    MaxOpenFiles  int    `mapstructure:"max_open_files"`
+    MaxProcesses  int    `mapstructure:"max_processes"`
+}
+
+// ApprovalConfig human approval settings for tool calls
+type ApprovalConfig struct {
+    Enabled bool                 `mapstructure:"enabled"`
+    Timeout int                  `mapstructure:"timeout"`
+    Rules   []ApprovalRuleConfig `mapstructure:"rules"`
+}
+
+// ApprovalRuleConfig marks a tool, optionally only when its JSON arguments match Pattern
+type ApprovalRuleConfig struct {
+    Tool    string `mapstructure:"tool"`
+    Pattern string `mapstructure:"pattern"`
+}
+
+// ToolPolicyConfig limits tools for conversations matching channel, sender
+// and session (empty or "*" matches any). All matching policies apply.
+type ToolPolicyConfig struct {
+    Channel   string   `mapstructure:"channel"`
+    SenderID  string   `mapstructure:"sender_id"`
+    Session   string   `mapstructure:"session"`
+    Allow     []string `mapstructure:"allow"`
+    Deny      []string `mapstructure:"deny"`
+    ExecAllow []string `mapstructure:"exec_allow"`
+    Paths     []string `mapstructure:"paths"`
 }
 
 // DefaultConfig returns config with sensible defaults
@@ -124,12 +341,72 @@ func DefaultConfig() *Config {
         Tools: ToolsConfig{
             Web: WebToolsConfig{
                 Search: WebSearchConfig{
+                    Provider:   "brave",
                     MaxResults: 5,
                 },
+                Fetch: WebFetchConfig{
+                    Enabled:  true,
+                    MaxBytes: 5 << 20,
+                    Timeout:  30,
+                    CacheTTL: 900,
+                },
             },
             Exec: ExecToolConfig{
                 Timeout:             60,
                 RestrictToWorkspace: false,
+                Sandbox: SandboxConfig{
+                    Enabled: false,
+                    Backend: "auto",
+                    Network: false,
+                },
+                DefaultRules:  true,
+                MaxBackground: 8,
+            },
+            Output: OutputConfig{
+                MaxBytes:    16384,
+                ArtifactDir: "artifacts",
+            },
+            Spawn: SpawnToolConfig{
+                Enabled:       true,
+                MaxIterations: 10,
+                MaxBackground: 4,
+                Timeout:       600,
+            },
+            MCP: MCPConfig{
+                Timeout: 60,
+            },
+            Plugins: PluginsConfig{
+                Enabled: true,
+                Dir:     filepath.Join(homeDir, ".golem", "plugins"),
+            },
+            Approval: ApprovalConfig{
+                Enabled: false,
+                Timeout: 120,
+                Rules: []ApprovalRuleConfig{
+                    {Tool: "exec"},
+                    {Tool: "write_file"},
+                    {Tool: "edit_file"},
+                },
+            },
+        },
+        Cron: CronConfig{
+            Enabled: true,
+        },
+        Heartbeat: HeartbeatConfig{
+            Enabled:  false,
+            Interval: 1800,
+            Channel:  "telegram",
+        },
+        Sessions: SessionsConfig{
+            Store: "jsonl",
+        },
+        Memory: MemoryConfig{
+            Semantic: SemanticMemoryConfig{
+                Enabled:       false,
+                Provider:      "openai",
+                TopK:          5,
+                MinScore:      0.3,
+                IndexSessions: true,
             },
         },
     }
@@ -202,6 +479,93 @@ func Save(cfg *Config) error {
     return os.WriteFile(configPath, data, 0644)
 }
 
+// AgentNames returns the default agent followed by the profiles in name order
+func (c *Config) AgentNames() []string {
+    names := make([]string, 0, len(c.Agents.Profiles))
+    for name := range c.Agents.Profiles {
+        names = append(names, strings.ToLower(name))
+    }
+    sort.Strings(names)
+    return append([]string{DefaultAgent}, names...)
+}
+
+// ChannelNames returns the enabled chat channels, the ones `golem run`
+// starts and can deliver messages to
+func (c *Config) ChannelNames() []string {
+    var names []string
+    if c.Channels.Telegram.Enabled {
+        names = append(names, "telegram")
+    }
+    return names
+}
+
+// ForAgent returns the config an agent runs with: its profile applied over
+// agents.defaults and its tool list added as a policy. Names are case-insensitive.
+func (c *Config) ForAgent(name string) (*Config, error) {
+    if strings.EqualFold(name, DefaultAgent) {
+        return c, nil
+    }
+    var (
+        p     AgentProfile
+        found bool
+    )
+    for key, profile := range c.Agents.Profiles {
+        if strings.EqualFold(key, name) {
+            p, found = profile, true
+            break
+        }
+    }
+    if !found {
+        return nil, fmt.Errorf("unknown agent %q", name)
+    }
+
+    out := *c
+    d := &out.Agents.Defaults
+    if p.Workspace != "" {
+        d.Workspace = p.Workspace
+        d.WorkspaceMode = "path"
+    }
+    if p.WorkspaceMode != "" {
+        d.WorkspaceMode = p.WorkspaceMode
+    }
+    if p.Model != "" {
+        d.Model = p.Model
+    }
+    if p.MaxTokens > 0 {
+        d.MaxTokens = p.MaxTokens
+    }
+    if p.Temperature != nil {
+        d.Temperature = *p.Temperature
+    }
+    if p.MaxToolIterations > 0 {
+        d.MaxToolIterations = p.MaxToolIterations
+    }
+    if len(p.PromptFiles) > 0 {
+        d.PromptFiles = p.PromptFiles
+    }
+    if len(p.Tools) > 0 {
+        out.Tools.Policies = append([]ToolPolicyConfig{{Allow: p.Tools}}, c.Tools.Policies...)
+    }
+    return &out, nil
+}
+
+// PluginsPath returns the expanded plugins directory. It defaults to
+// ~/.golem/plugins; a relative dir is inside the workspace.
+func (c *Config) PluginsPath() string {
+    dir := strings.TrimSpace(c.Tools.Plugins.Dir)
+    switch {
+    case dir == "":
+        return filepath.Join(ConfigDir(), "plugins")
+    case dir[0] == '~':
+        homeDir, _ := os.UserHomeDir()
+        rest := strings.TrimPrefix(strings.TrimPrefix(dir[1:], string(filepath.Separator)), "/")
+        return filepath.Join(homeDir, rest)
+    case filepath.IsAbs(dir):
+        return dir
+    }
+    return filepath.Join(c.WorkspacePath(), dir)
+}
+
 // WorkspacePath returns the expanded workspace path
 func (c *Config) WorkspacePath() string {
     path, err := c.WorkspacePathChecked()
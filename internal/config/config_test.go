@@ -105,3 +105,90 @@ func TestWorkspacePath_CwdModeUsesCwd(t *testing.T) {
         t.Fatalf("got %s want %s", got, wd)
     }
 }
+
+func TestPluginsPath(t *testing.T) {
+    homeDir, _ := os.UserHomeDir()
+    cfg := DefaultConfig()
+    cfg.Agents.Defaults.WorkspaceMode = "path"
+    cfg.Agents.Defaults.Workspace = filepath.Join(homeDir, "ws")
+
+    for dir, want := range map[string]string{
+        "":                    filepath.Join(ConfigDir(), "plugins"),
+        "~/golem-plugins":     filepath.Join(homeDir, "golem-plugins"),
+        "tools/plugins":       filepath.Join(homeDir, "ws", "tools", "plugins"),
+        cfg.Tools.Plugins.Dir: filepath.Join(ConfigDir(), "plugins"),
+    } {
+        cfg.Tools.Plugins.Dir = dir
+        if got := cfg.PluginsPath(); got != want {
+            t.Errorf("dir %q: got %s want %s", dir, got, want)
+        }
+    }
+}
+
+func TestLoadConfig_AgentProfilesAndRoutes(t *testing.T) {
+    tmpDir := t.TempDir()
+    t.Setenv("HOME", tmpDir)
+    t.Setenv("USERPROFILE", tmpDir)
+
+    configPath := ConfigPath()
+    if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+        t.Fatalf("MkdirAll: %v", err)
+    }
+    raw := `{
+  "agents": {
+    "profiles": {
+      "Ops": {
+        "workspace": "/srv/ops",
+        "model": "openai/gpt-4o-mini",
+        "temperature": 0,
+        "prompt_files": ["OPS.md"],
+        "tools": ["read_file", "exec"]
+      }
+    },
+    "routes": [
+      { "agent": "ops", "channel": "telegram", "chat_id": "-100" },
+      { "agent": "ops", "prefix": "/ops" }
+    ]
+  }
+}`
+    if err := os.WriteFile(configPath, []byte(raw), 0644); err != nil {
+        t.Fatalf("WriteFile: %v", err)
+    }
+
+    cfg, err := Load()
+    if err != nil {
+        t.Fatalf("Load() error: %v", err)
+    }
+    if names := cfg.AgentNames(); len(names) != 2 || names[0] != DefaultAgent || names[1] != "ops" {
+        t.Fatalf("unexpected agent names %v", names)
+    }
+    if len(cfg.Agents.Routes) != 2 || cfg.Agents.Routes[0].ChatID != "-100" || cfg.Agents.Routes[1].Prefix != "/ops" {
+        t.Fatalf("unexpected routes %+v", cfg.Agents.Routes)
+    }
+
+    ops, err := cfg.ForAgent("OPS")
+    if err != nil {
+        t.Fatalf("ForAgent: %v", err)
+    }
+    d := ops.Agents.Defaults
+    if d.Model != "openai/gpt-4o-mini" || d.Temperature != 0 || d.MaxTokens != 8192 {
+        t.Fatalf("expected the profile over the defaults, got %+v", d)
+    }
+    if got, _ := ops.WorkspacePathChecked(); got != "/srv/ops" {
+        t.Fatalf("expected the profile workspace, got %s", got)
+    }
+    if len(d.PromptFiles) != 1 || d.PromptFiles[0] != "OPS.md" {
+        t.Fatalf("expected prompt files, got %v", d.PromptFiles)
+    }
+    if len(ops.Tools.Policies) != 1 || len(ops.Tools.Policies[0].Allow) != 2 {
+        t.Fatalf("expected the tool list as a policy, got %+v", ops.Tools.Policies)
+    }
+
+    // The default agent is unchanged
+    if cfg.Agents.Defaults.Temperature != 0.7 || len(cfg.Tools.Policies) != 0 {
+        t.Fatalf("expected defaults untouched, got %+v", cfg.Agents.Defaults)
+    }
+    if _, err := cfg.ForAgent("missing"); err == nil {
+        t.Fatal("expected an unknown agent to fail")
+    }
+}
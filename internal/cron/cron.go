@@ -0,0 +1,419 @@
+// Package cron keeps scheduled tasks in the workspace and fires them when due.
+// A job is a cron expression, a one-shot time or a fixed interval, and knows
+// the channel and chat its message should be delivered to.
+package cron
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+// FileName is the job file location relative to the workspace
+const FileName = "state/cron.json"
+
+// Schedule kinds
+const (
+	KindCron  = "cron"
+	KindAt    = "at"
+	KindEvery = "every"
+)
+
+// MinInterval is the shortest allowed "every" interval
+const MinInterval = time.Minute
+
+// Job is a scheduled task
+type Job struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Message  string `json:"message"`
+	Kind     string `json:"kind"`
+	Cron     string `json:"cron,omitempty"`
+	At       string `json:"at,omitempty"`
+	Every    string `json:"every,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+
+	Channel  string `json:"channel"`
+	ChatID   string `json:"chat_id"`
+	SenderID string `json:"sender_id,omitempty"`
+	// Agent answers the task when it fires; empty follows the routing rules
+	Agent string `json:"agent,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	NextRun   time.Time `json:"next_run"`
+	LastRun   time.Time `json:"last_run,omitzero"`
+	Runs      int       `json:"runs,omitempty"`
+}
+
+// SessionKey returns the session the job delivers to
+func (j *Job) SessionKey() string {
+	return j.Channel + ":" + j.ChatID
+}
+
+// Describe returns the schedule in words, e.g. "cron 0 9 * * 1-5"
+func (j *Job) Describe() string {
+	var s string
+	switch j.Kind {
+	case KindCron:
+		s = "cron " + j.Cron
+	case KindAt:
+		s = "once at " + j.At
+	case KindEvery:
+		s = "every " + j.Every
+	}
+	if j.Timezone != "" {
+		s += " (" + j.Timezone + ")"
+	}
+	return s
+}
+
+// Validate checks the schedule and fills in Kind
+func (j *Job) Validate() error {
+	j.Message = strings.TrimSpace(j.Message)
+	if j.Message == "" {
+		return fmt.Errorf("task message is empty")
+	}
+	if j.Channel == "" || j.ChatID == "" {
+		return fmt.Errorf("task needs a channel and chat id")
+	}
+	set := 0
+	for kind, v := range map[string]string{KindCron: j.Cron, KindAt: j.At, KindEvery: j.Every} {
+		if strings.TrimSpace(v) != "" {
+			set++
+			j.Kind = kind
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("set exactly one of cron, at or every")
+	}
+	loc, err := j.location()
+	if err != nil {
+		return err
+	}
+	switch j.Kind {
+	case KindCron:
+		_, err = ParseExpr(j.Cron)
+	case KindAt:
+		_, err = ParseTime(j.At, loc)
+	case KindEvery:
+		var d time.Duration
+		d, err = time.ParseDuration(j.Every)
+		if err == nil && d < MinInterval {
+			err = fmt.Errorf("interval must be at least %s", MinInterval)
+		}
+	}
+	return err
+}
+
+func (j *Job) location() (*time.Location, error) {
+	if j.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(j.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", j.Timezone)
+	}
+	return loc, nil
+}
+
+// next returns the run after t, or the zero time when the job is finished
+func (j *Job) next(t time.Time) time.Time {
+	loc, err := j.location()
+	if err != nil {
+		return time.Time{}
+	}
+	switch j.Kind {
+	case KindCron:
+		expr, err := ParseExpr(j.Cron)
+		if err != nil {
+			return time.Time{}
+		}
+		return expr.Next(t.In(loc))
+	case KindAt:
+		at, err := ParseTime(j.At, loc)
+		if err != nil || j.Runs > 0 {
+			return time.Time{}
+		}
+		return at
+	case KindEvery:
+		d, err := time.ParseDuration(j.Every)
+		if err != nil || d <= 0 {
+			return time.Time{}
+		}
+		return t.Add(d)
+	}
+	return time.Time{}
+}
+
+// ParseTime reads RFC 3339 or "2006-01-02 15:04" in loc
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 or YYYY-MM-DD HH:MM", s)
+}
+
+// AtDelay returns the one-shot time a delay such as "20m" after now, in the
+// form Job.At expects
+func AtDelay(delay string, now time.Time) (string, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(delay))
+	if err != nil || d <= 0 {
+		return "", fmt.Errorf("invalid delay %q: use a duration such as 20m", delay)
+	}
+	return now.Add(d).Format(time.RFC3339), nil
+}
+
+// Store reads and writes the job file. Every call reads the file again so
+// the CLI and a running server see each other's changes, and changes hold a
+// lock on a file beside it so concurrent processes do not lose updates.
+type Store struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// NewStore creates a store for the workspace
+func NewStore(workspacePath string) *Store {
+	return &Store{path: filepath.Join(workspacePath, filepath.FromSlash(FileName)), now: time.Now}
+}
+
+// Path returns the job file location
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns all jobs ordered by next run
+func (s *Store) List() ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Add validates a job, computes its first run and saves it
+func (s *Store) Add(job Job) (Job, error) {
+	if err := job.Validate(); err != nil {
+		return Job{}, err
+	}
+	now := s.now()
+	job.ID = newID()
+	job.CreatedAt = now
+	job.LastRun, job.Runs = time.Time{}, 0
+	job.NextRun = job.next(now)
+	if job.NextRun.IsZero() {
+		return Job{}, fmt.Errorf("schedule %s never runs", job.Describe())
+	}
+	if job.Kind == KindAt && job.NextRun.Before(now) {
+		return Job{}, fmt.Errorf("%s is in the past", job.At)
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return Job{}, err
+	}
+	defer unlock()
+	jobs, err := s.load()
+	if err != nil {
+		return Job{}, err
+	}
+	return job, s.save(append(jobs, job))
+}
+
+// Remove deletes a job. When sessionKey is set, only a job delivering to
+// that session may be removed.
+func (s *Store) Remove(id, sessionKey string) (Job, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return Job{}, err
+	}
+	defer unlock()
+	jobs, err := s.load()
+	if err != nil {
+		return Job{}, err
+	}
+	for i, j := range jobs {
+		if j.ID == strings.TrimSpace(id) && (sessionKey == "" || j.SessionKey() == sessionKey) {
+			return j, s.save(append(jobs[:i], jobs[i+1:]...))
+		}
+	}
+	return Job{}, fmt.Errorf("no scheduled task with id %q", id)
+}
+
+// due removes finished jobs, advances the due ones and returns them
+func (s *Store) due(now time.Time) ([]Job, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	jobs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var fired, kept []Job
+	for _, j := range jobs {
+		if !j.NextRun.After(now) {
+			fired = append(fired, j)
+			j.LastRun = now
+			j.Runs++
+			// Runs missed while nothing was running collapse into this one
+			j.NextRun = j.next(now)
+		}
+		if !j.NextRun.IsZero() {
+			kept = append(kept, j)
+		}
+	}
+	if len(fired) == 0 {
+		return nil, nil
+	}
+	return fired, s.save(kept)
+}
+
+// lock serializes read-modify-write cycles within the process and, through
+// an exclusive lock on <file>.lock, with other processes
+func (s *Store) lock() (func(), error) {
+	s.mu.Lock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	f, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		s.mu.Unlock()
+		return nil, fmt.Errorf("lock %s: %w", s.path, err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+		s.mu.Unlock()
+	}, nil
+}
+
+func (s *Store) load() ([]Job, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("read %s: %w", s.path, err)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].NextRun.Before(jobs[j].NextRun) })
+	return jobs, nil
+}
+
+func (s *Store) save(jobs []Job) error {
+	if jobs == nil {
+		jobs = []Job{}
+	}
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".cron.*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func newID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Scheduler checks the store and hands due jobs to Fire
+type Scheduler struct {
+	store *Store
+	fire  func(Job)
+	tick  time.Duration
+}
+
+// NewScheduler creates a scheduler; fire is called once per due run
+func NewScheduler(store *Store, fire func(Job)) *Scheduler {
+	return &Scheduler{store: store, fire: fire, tick: time.Second}
+}
+
+// Run fires due jobs until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for {
+		s.RunDue()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue fires every job that is due now
+func (s *Scheduler) RunDue() {
+	jobs, err := s.store.due(s.store.now())
+	if err != nil {
+		slog.Error("scheduled tasks failed", "error", err)
+		return
+	}
+	for _, j := range jobs {
+		slog.Info("running scheduled task", "id", j.ID, "session", j.SessionKey())
+		s.fire(j)
+	}
+}
+
+// Inbound builds the synthetic message delivered to the job's chat
+func (j *Job) Inbound() *bus.InboundMessage {
+	label := j.ID
+	if j.Name != "" {
+		label += " " + j.Name
+	}
+	msg := &bus.InboundMessage{
+		Channel:   j.Channel,
+		ChatID:    j.ChatID,
+		SenderID:  j.SenderID,
+		Content:   fmt.Sprintf("[Scheduled task %s, %s] %s", label, j.Describe(), j.Message),
+		Timestamp: time.Now(),
+		Metadata:  map[string]any{"cron_job": j.ID},
+	}
+	if j.Agent != "" {
+		msg.Metadata[bus.MetadataAgent] = j.Agent
+	}
+	return msg
+}
@@ -0,0 +1,133 @@
+package cron
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+func newTestStore(ws string, now *time.Time) *Store {
+	s := NewStore(ws)
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestStore_AddValidates(t *testing.T) {
+	now := time.Date(2026, 3, 13, 17, 30, 0, 0, time.UTC)
+	s := newTestStore(t.TempDir(), &now)
+	base := Job{Message: "stand-up", Channel: "telegram", ChatID: "1"}
+
+	bad := []Job{
+		{Channel: "telegram", ChatID: "1", Cron: "@daily"},
+		{Message: "x", Cron: "@daily"},
+		{Message: "x", Channel: "telegram", ChatID: "1"},
+		{Message: "x", Channel: "telegram", ChatID: "1", Cron: "@daily", Every: "1h"},
+		{Message: "x", Channel: "telegram", ChatID: "1", Every: "10s"},
+		{Message: "x", Channel: "telegram", ChatID: "1", At: "2020-01-01 09:00"},
+		{Message: "x", Channel: "telegram", ChatID: "1", Cron: "@daily", Timezone: "Mars/Olympus"},
+	}
+	for _, j := range bad {
+		if _, err := s.Add(j); err == nil {
+			t.Errorf("expected %+v to be rejected", j)
+		}
+	}
+
+	j := base
+	j.Cron = "0 9 * * 1-5"
+	j.Timezone = "Asia/Tokyo"
+	added, err := s.Add(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	if added.Kind != KindCron || !added.NextRun.Equal(time.Date(2026, 3, 16, 9, 0, 0, 0, tokyo)) {
+		t.Fatalf("unexpected job %+v", added)
+	}
+	if added.Describe() != "cron 0 9 * * 1-5 (Asia/Tokyo)" {
+		t.Fatalf("unexpected description %q", added.Describe())
+	}
+}
+
+func TestStore_ConcurrentStoresKeepEveryJob(t *testing.T) {
+	ws := t.TempDir()
+	now := time.Date(2026, 3, 13, 17, 30, 0, 0, time.UTC)
+	// Separate stores share only the file, like the CLI and a running server
+	stores := []*Store{newTestStore(ws, &now), newTestStore(ws, &now)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(s *Store) {
+			defer wg.Done()
+			if _, err := s.Add(Job{Message: "ping", Channel: "cli", ChatID: "1", Every: "1h"}); err != nil {
+				t.Error(err)
+			}
+			if _, err := s.due(now); err != nil {
+				t.Error(err)
+			}
+		}(stores[i%2])
+	}
+	wg.Wait()
+
+	jobs, err := stores[0].List()
+	if err != nil || len(jobs) != 100 {
+		t.Fatalf("expected all 100 jobs, got %d (%v)", len(jobs), err)
+	}
+}
+
+func TestScheduler_FiresAndAdvances(t *testing.T) {
+	now := time.Date(2026, 3, 13, 17, 30, 0, 0, time.UTC)
+	ws := t.TempDir()
+	s := newTestStore(ws, &now)
+	reminder, _ := s.Add(Job{Message: "call mum", Channel: "telegram", ChatID: "1", SenderID: "42", At: "2026-03-13T18:00:00Z"})
+	water, _ := s.Add(Job{Message: "drink water", Channel: "telegram", ChatID: "1", Every: "1h"})
+	other, _ := s.Add(Job{Message: "report", Channel: "telegram", ChatID: "2", Cron: "0 9 * * *"})
+
+	var fired []Job
+	sched := NewScheduler(s, func(j Job) { fired = append(fired, j) })
+	sched.RunDue()
+	if len(fired) != 0 {
+		t.Fatalf("expected nothing due yet, got %+v", fired)
+	}
+
+	// Reopened stores see the same jobs, so the CLI and server share them
+	now = now.Add(2 * time.Hour)
+	sched.store = newTestStore(ws, &now)
+	sched.RunDue()
+	if len(fired) != 2 || fired[0].ID != reminder.ID || fired[1].ID != water.ID {
+		t.Fatalf("expected the reminder and one catch-up water run, got %+v", fired)
+	}
+
+	msg := fired[0].Inbound()
+	if msg.SessionKey() != "telegram:1" || msg.SenderID != "42" || !strings.Contains(msg.Content, "call mum") {
+		t.Fatalf("unexpected synthetic message %+v", msg)
+	}
+	if _, ok := msg.Metadata[bus.MetadataAgent]; ok {
+		t.Fatalf("expected no agent for a job without one, got %+v", msg.Metadata)
+	}
+	agentJob := fired[0]
+	agentJob.Agent = "ops"
+	if got := agentJob.Inbound().Metadata[bus.MetadataAgent]; got != "ops" {
+		t.Fatalf("expected the job's agent in the metadata, got %v", got)
+	}
+
+	jobs, _ := s.List()
+	if len(jobs) != 2 {
+		t.Fatalf("expected the one-shot reminder to be removed, got %+v", jobs)
+	}
+	for _, j := range jobs {
+		if j.ID == water.ID && (!j.NextRun.Equal(now.Add(time.Hour)) || j.Runs != 1) {
+			t.Fatalf("expected interval to restart from the run, got %+v", j)
+		}
+	}
+
+	if _, err := s.Remove(other.ID, "telegram:1"); err == nil {
+		t.Fatal("expected removing another chat's task to fail")
+	}
+	if _, err := s.Remove(other.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpr_Next(t *testing.T) {
+	loc := time.UTC
+	// Friday 2026-03-13 17:30
+	from := time.Date(2026, 3, 13, 17, 30, 0, 0, loc)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 9 * * mon-fri", time.Date(2026, 3, 16, 9, 0, 0, 0, loc)},
+		{"*/15 * * * *", time.Date(2026, 3, 13, 17, 45, 0, 0, loc)},
+		{"30 17 * * *", time.Date(2026, 3, 14, 17, 30, 0, 0, loc)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, loc)},
+		{"0 12 29 feb *", time.Date(2028, 2, 29, 12, 0, 0, 0, loc)},
+		{"0 8 1 * sun", time.Date(2026, 3, 15, 8, 0, 0, 0, loc)}, // day 1 or any Sunday
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, loc)},
+		{"5/20 10 * * *", time.Date(2026, 3, 14, 10, 5, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		e, err := ParseExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if got := e.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%s: next = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseExpr_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * * funday"} {
+		if _, err := ParseExpr(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
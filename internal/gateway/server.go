@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+)
+
+// Server is the HTTP gateway shared by channels that receive pushes
+type Server struct {
+	addr string
+	mux  *http.ServeMux
+}
+
+// New creates a gateway server for the configured host and port
+func New(cfg *config.GatewayConfig) *Server {
+	s := &Server{
+		addr: net.JoinHostPort(cfg.Host, fmt.Sprintf("%d", cfg.Port)),
+		mux:  http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	return s
+}
+
+// Addr returns the listen address
+func (s *Server) Addr() string { return s.addr }
+
+// Handle registers a handler for the given pattern
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Handler returns the root handler, mainly for tests
+func (s *Server) Handler() http.Handler { return s.mux }
+
+// Start listens until ctx is cancelled, then shuts down gracefully
+func (s *Server) Start(ctx context.Context) error {
+	ln, err := s.Listen()
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Listen binds the configured address without serving yet
+func (s *Server) Listen() (net.Listener, error) {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("gateway listen failed: %w", err)
+	}
+	return ln, nil
+}
+
+// Serve serves on an existing listener until ctx is cancelled
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("gateway listening", "addr", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/config"
+)
+
+func TestServer_HealthAndHandle(t *testing.T) {
+	s := New(&config.GatewayConfig{Host: "127.0.0.1", Port: 0})
+	s.Handle("/hook", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected health 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hook", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected hook 202, got %d", rec.Code)
+	}
+}
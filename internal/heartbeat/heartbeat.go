@@ -0,0 +1,119 @@
+// Package heartbeat wakes the agent on an interval to work through the
+// standing tasks in the workspace HEARTBEAT.md, and passes on a report only
+// when the agent says something needs attention.
+package heartbeat
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// FileName lists the standing tasks, relative to the workspace
+	FileName = "HEARTBEAT.md"
+	// OKToken is the reply meaning nothing needs reporting
+	OKToken = "HEARTBEAT_OK"
+)
+
+var htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// Runner runs one agent turn and returns the reply
+type Runner func(ctx context.Context, prompt string) (string, error)
+
+// Notifier delivers a report
+type Notifier func(report string)
+
+// Heartbeat runs the standing tasks on an interval
+type Heartbeat struct {
+	workspacePath string
+	interval      time.Duration
+	run           Runner
+	notify        Notifier
+	now           func() time.Time
+}
+
+// New creates a heartbeat for the workspace
+func New(workspacePath string, interval time.Duration, run Runner, notify Notifier) *Heartbeat {
+	return &Heartbeat{
+		workspacePath: workspacePath,
+		interval:      interval,
+		run:           run,
+		notify:        notify,
+		now:           time.Now,
+	}
+}
+
+// Run beats every interval until ctx is done
+func (h *Heartbeat) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := h.Beat(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("heartbeat failed", "error", err)
+			}
+		}
+	}
+}
+
+// Beat runs the standing tasks once and returns the report that was
+// delivered, which is empty when there was nothing to do or report
+func (h *Heartbeat) Beat(ctx context.Context) (string, error) {
+	tasks := h.tasks()
+	if tasks == "" {
+		return "", nil
+	}
+	reply, err := h.run(ctx, h.prompt(tasks))
+	if err != nil {
+		return "", err
+	}
+	report := Report(reply)
+	if report == "" {
+		slog.Debug("heartbeat: nothing to report")
+		return "", nil
+	}
+	h.notify(report)
+	return report, nil
+}
+
+// tasks returns HEARTBEAT.md without comments, or "" when it has nothing
+// but headings, so an unedited file costs no model call
+func (h *Heartbeat) tasks() string {
+	data, err := os.ReadFile(filepath.Join(h.workspacePath, FileName))
+	if err != nil {
+		return ""
+	}
+	text := strings.TrimSpace(htmlComment.ReplaceAllString(string(data), ""))
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return text
+		}
+	}
+	return ""
+}
+
+func (h *Heartbeat) prompt(tasks string) string {
+	return "[Heartbeat " + h.now().Format("2006-01-02 15:04 MST") + "] " +
+		"This is a scheduled check-in, not a message from the user. " +
+		"Work through the standing tasks below with your tools. " +
+		"If something needs the user's attention, reply with a short report for them; " +
+		"otherwise reply with exactly " + OKToken + ".\n\n" + tasks
+}
+
+// Report returns what should be passed on from a heartbeat reply: "" for
+// a bare HEARTBEAT_OK, otherwise the reply without the token
+func Report(reply string) string {
+	report := strings.TrimSpace(strings.ReplaceAll(reply, OKToken, ""))
+	if strings.Trim(report, " \t\n.!*_`-") == "" {
+		return ""
+	}
+	return report
+}
@@ -0,0 +1,72 @@
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	tests := map[string]string{
+		"HEARTBEAT_OK":                         "",
+		"  **HEARTBEAT_OK**.\n":                "",
+		"Disk is 95% full.":                    "Disk is 95% full.",
+		"HEARTBEAT_OK\n\nNew error in app.log": "New error in app.log",
+	}
+	for reply, want := range tests {
+		if got := Report(reply); got != want {
+			t.Errorf("Report(%q) = %q, want %q", reply, got, want)
+		}
+	}
+}
+
+func TestBeat(t *testing.T) {
+	ws := t.TempDir()
+	var prompts []string
+	reply := "HEARTBEAT_OK"
+	var runErr error
+	var reports []string
+	h := New(ws, time.Minute,
+		func(ctx context.Context, prompt string) (string, error) {
+			prompts = append(prompts, prompt)
+			return reply, runErr
+		},
+		func(report string) { reports = append(reports, report) })
+
+	// Missing and comment-only files do not call the model
+	if _, err := h.Beat(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	template := "# Heartbeat\n\n<!--\n- Check logs/app.log\n-->\n"
+	if err := os.WriteFile(filepath.Join(ws, FileName), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Beat(context.Background()); err != nil || len(prompts) != 0 {
+		t.Fatalf("expected no model call for an empty task list, got %d (%v)", len(prompts), err)
+	}
+
+	tasks := "# Heartbeat\n\n- Check logs/app.log for errors\n"
+	if err := os.WriteFile(filepath.Join(ws, FileName), []byte(tasks), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if report, err := h.Beat(context.Background()); err != nil || report != "" || len(reports) != 0 {
+		t.Fatalf("expected a quiet beat, got %q %v", report, err)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "- Check logs/app.log for errors") || !strings.Contains(prompts[0], OKToken) {
+		t.Fatalf("unexpected prompt %q", prompts)
+	}
+
+	reply = "Three new errors in app.log since 09:00."
+	if report, err := h.Beat(context.Background()); err != nil || report != reply || len(reports) != 1 {
+		t.Fatalf("expected the report to be delivered, got %q %v", report, err)
+	}
+
+	runErr = errors.New("model down")
+	if _, err := h.Beat(context.Background()); err == nil || len(reports) != 1 {
+		t.Fatalf("expected the error to surface without a report, got %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// golemInfo identifies Golem to the other side
+var golemInfo = Implementation{Name: "golem", Version: "dev"}
+
+// transport carries messages over one connection to a server
+type transport interface {
+	// roundTrip sends a request and waits for its response
+	roundTrip(ctx context.Context, req *Message) (*Message, error)
+	notify(ctx context.Context, msg *Message) error
+	// alive reports whether requests can still be sent
+	alive() bool
+	close() error
+}
+
+// errClosed means the connection ended, e.g. because the server exited
+var errClosed = errors.New("connection closed")
+
+// Options configure a server connection: Command (with Args, KEY=value Env
+// entries and Dir) starts a stdio server, URL connects to a streamable HTTP
+// one. Timeout limits each request; zero means none.
+type Options struct {
+	Name    string
+	Command string
+	Args    []string
+	Env     []string
+	Dir     string
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+}
+
+// Client is a connection to one MCP server. A stdio server that exits is
+// started again on the next request, and an expired HTTP session is
+// initialized again; a request in flight when the server went away fails.
+type Client struct {
+	opts   Options
+	nextID atomic.Int64
+
+	mu     sync.Mutex
+	conn   transport
+	info   InitializeResult
+	closed bool
+}
+
+// Connect starts or dials the server and initializes the session
+func Connect(ctx context.Context, opts Options) (*Client, error) {
+	if (opts.Command == "") == (opts.URL == "") {
+		return nil, fmt.Errorf("mcp server %s: set either command or url", opts.Name)
+	}
+	c := &Client{opts: opts}
+	if _, err := c.connection(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Name returns the configured server name
+func (c *Client) Name() string {
+	return c.opts.Name
+}
+
+// ServerInfo returns what the server reported about itself
+func (c *Client) ServerInfo() InitializeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.info
+}
+
+// connection returns the live connection, (re)starting it when needed
+func (c *Client) connection(ctx context.Context) (transport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, fmt.Errorf("mcp server %s: client closed", c.opts.Name)
+	}
+	if c.conn != nil && c.conn.alive() {
+		return c.conn, nil
+	}
+	if c.conn != nil {
+		slog.Warn("mcp server went away, restarting", "server", c.opts.Name)
+		c.conn.close()
+		c.conn = nil
+	}
+
+	var conn transport
+	if c.opts.Command != "" {
+		t, err := startStdio(c.opts)
+		if err != nil {
+			return nil, fmt.Errorf("mcp server %s: %w", c.opts.Name, err)
+		}
+		conn = t
+	} else {
+		conn = newHTTPTransport(c.opts)
+	}
+	info, err := c.initialize(ctx, conn)
+	if err != nil {
+		conn.close()
+		return nil, fmt.Errorf("mcp server %s: initialize: %w", c.opts.Name, err)
+	}
+	c.conn, c.info = conn, info
+	return conn, nil
+}
+
+func (c *Client) initialize(ctx context.Context, conn transport) (InitializeResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	var info InitializeResult
+	req, err := NewRequest(c.nextID.Add(1), "initialize", InitializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    map[string]any{},
+		ClientInfo:      golemInfo,
+	})
+	if err != nil {
+		return info, err
+	}
+	resp, err := conn.roundTrip(ctx, req)
+	if err != nil {
+		return info, err
+	}
+	if err := decodeResult(resp, &info); err != nil {
+		return info, err
+	}
+	note, _ := NewRequest(nil, "notifications/initialized", nil)
+	return info, conn.notify(ctx, note)
+}
+
+// drop forgets conn so the next request reconnects
+func (c *Client) drop(conn transport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == conn {
+		c.conn.close()
+		c.conn = nil
+	}
+}
+
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.opts.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.opts.Timeout)
+}
+
+// call sends a request and decodes its result into out
+func (c *Client) call(ctx context.Context, method string, params, out any) error {
+	conn, err := c.connection(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := NewRequest(c.nextID.Add(1), method, params)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := conn.roundTrip(ctx, req)
+	if errors.Is(err, errSessionExpired) {
+		// The server forgot us before handling the request, so it is safe to resend
+		c.drop(conn)
+		if conn, err = c.connection(ctx); err != nil {
+			return err
+		}
+		resp, err = conn.roundTrip(ctx, req)
+	}
+	if errors.Is(err, errClosed) {
+		c.drop(conn)
+		return fmt.Errorf("mcp server %s: %w", c.opts.Name, err)
+	}
+	if err != nil {
+		return fmt.Errorf("mcp server %s: %w", c.opts.Name, err)
+	}
+	return decodeResult(resp, out)
+}
+
+func decodeResult(resp *Message, out any) error {
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, out)
+}
+
+// ListTools returns all tools of the server, following pagination
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	params := ListToolsParams{}
+	for {
+		var page ListToolsResult
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return nil, err
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		params.Cursor = page.NextCursor
+	}
+}
+
+// CallTool invokes a tool with JSON arguments
+func (c *Client) CallTool(ctx context.Context, name string, args json.RawMessage) (*CallToolResult, error) {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	var result CallToolResult
+	if err := c.call(ctx, "tools/call", CallToolParams{Name: name, Arguments: args}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Close shuts the connection down; a stdio server is stopped
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.close()
+	c.conn = nil
+	return err
+}
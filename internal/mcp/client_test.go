@@ -0,0 +1,104 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/mcp"
+	"github.com/MEKXH/golem/internal/mcp/mcptest"
+)
+
+func TestMain(m *testing.M) {
+	mcptest.Main()
+	os.Exit(m.Run())
+}
+
+func TestClient_Stdio(t *testing.T) {
+	command, env := mcptest.StdioCommand()
+	client, err := mcp.Connect(context.Background(), mcp.Options{Name: "fake", Command: command, Env: env, Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if info := client.ServerInfo(); info.ServerInfo.Name != "fake" {
+		t.Fatalf("unexpected server info %+v", info)
+	}
+	tools, err := client.ListTools(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 4 || tools[0].Name != "echo" || tools[3].Name != "crash" {
+		t.Fatalf("expected both pages of tools, got %+v", tools)
+	}
+
+	result, err := client.CallTool(context.Background(), "echo", json.RawMessage(`{"text":"hi"}`))
+	if err != nil || result.Text() != "hi" {
+		t.Fatalf("echo: %+v %v", result, err)
+	}
+
+	// A server that exits fails the request in flight and is restarted for the next
+	if _, err := client.CallTool(context.Background(), "crash", nil); err == nil {
+		t.Fatal("expected the crashing call to fail")
+	}
+	result, err = client.CallTool(context.Background(), "add", json.RawMessage(`{"a":2,"b":3}`))
+	if err != nil || result.Text() != "5" {
+		t.Fatalf("expected the restarted server to answer, got %+v %v", result, err)
+	}
+}
+
+func TestClient_HTTP(t *testing.T) {
+	fake := mcptest.NewServer()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client, err := mcp.Connect(context.Background(), mcp.Options{Name: "fake", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools, err := client.ListTools(context.Background())
+	if err != nil || len(tools) != 4 {
+		t.Fatalf("list: %+v %v", tools, err)
+	}
+
+	// Tool calls come back as an event stream with a notification first
+	result, err := client.CallTool(context.Background(), "echo", json.RawMessage(`{"text":"over http"}`))
+	if err != nil || result.Text() != "over http" {
+		t.Fatalf("echo: %+v %v", result, err)
+	}
+	result, err = client.CallTool(context.Background(), "fail", nil)
+	if err != nil || !result.IsError || result.Text() != "something broke" {
+		t.Fatalf("expected a tool error result, got %+v %v", result, err)
+	}
+
+	// An expired session is initialized again and the request resent
+	fake.ExpireSessions()
+	result, err = client.CallTool(context.Background(), "add", json.RawMessage(`{"a":1,"b":1}`))
+	if err != nil || result.Text() != "2" {
+		t.Fatalf("expected the call to survive a lost session, got %+v %v", result, err)
+	}
+	if fake.Initialized() != 2 {
+		t.Fatalf("expected two initializations, got %d", fake.Initialized())
+	}
+
+	if _, err := client.CallTool(context.Background(), "missing", nil); err == nil {
+		t.Fatal("expected an unknown tool to fail")
+	}
+	client.Close()
+	if _, err := client.ListTools(context.Background()); err == nil {
+		t.Fatal("expected a closed client to fail")
+	}
+}
+
+func TestConnect_RequiresOneTransport(t *testing.T) {
+	if _, err := mcp.Connect(context.Background(), mcp.Options{Name: "x"}); err == nil {
+		t.Error("expected an error without command or url")
+	}
+	if _, err := mcp.Connect(context.Background(), mcp.Options{Name: "x", Command: "a", URL: "http://b"}); err == nil {
+		t.Error("expected an error with both command and url")
+	}
+}
@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errSessionExpired means the server no longer knows our session and the
+// connection has to be initialized again
+var errSessionExpired = errors.New("session expired")
+
+// httpTransport is the streamable HTTP transport: every message is POSTed
+// and the reply comes back as JSON or as an event stream
+type httpTransport struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	mu      sync.Mutex
+	session string
+	version string
+}
+
+func newHTTPTransport(opts Options) *httpTransport {
+	return &httpTransport{url: opts.URL, headers: opts.Headers, client: &http.Client{}}
+}
+
+func (t *httpTransport) post(ctx context.Context, msg *Message) (*http.Response, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(req)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound && req.Header.Get("Mcp-Session-Id") != "" {
+		resp.Body.Close()
+		return nil, errSessionExpired
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		t.mu.Lock()
+		t.session = id
+		t.mu.Unlock()
+	}
+	return resp, nil
+}
+
+func (t *httpTransport) setHeaders(req *http.Request) {
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.session != "" {
+		req.Header.Set("Mcp-Session-Id", t.session)
+	}
+	if t.version != "" {
+		req.Header.Set("MCP-Protocol-Version", t.version)
+	}
+}
+
+func (t *httpTransport) roundTrip(ctx context.Context, req *Message) (*Message, error) {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var reply *Message
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		reply, err = readEventStream(resp.Body, req.ID)
+	} else {
+		reply = &Message{}
+		err = json.NewDecoder(resp.Body).Decode(reply)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Method == "initialize" && reply.Error == nil {
+		var init InitializeResult
+		if json.Unmarshal(reply.Result, &init) == nil {
+			t.mu.Lock()
+			t.version = init.ProtocolVersion
+			t.mu.Unlock()
+		}
+	}
+	return reply, nil
+}
+
+// readEventStream returns the response to id from a server-sent event
+// stream, skipping the notifications sent before it
+func readEventStream(body io.Reader, id json.RawMessage) (*Message, error) {
+	r := bufio.NewReader(body)
+	var data bytes.Buffer
+	for {
+		line, err := r.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			data.WriteByte('\n')
+		case line == "" && data.Len() > 0:
+			var msg Message
+			if json.Unmarshal(data.Bytes(), &msg) == nil && msg.IsResponse() && bytes.Equal(msg.ID, id) {
+				return &msg, nil
+			}
+			data.Reset()
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%w: event stream ended without a response", errClosed)
+			}
+			return nil, err
+		}
+	}
+}
+
+func (t *httpTransport) notify(ctx context.Context, msg *Message) error {
+	resp, err := t.post(ctx, msg)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (t *httpTransport) alive() bool {
+	return true
+}
+
+// close ends the session on the server, if it keeps one
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	session := t.session
+	t.mu.Unlock()
+	if session == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil)
+	if err != nil {
+		return err
+	}
+	t.setHeaders(req)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
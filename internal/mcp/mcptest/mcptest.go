@@ -0,0 +1,246 @@
+// Package mcptest provides a fake MCP server for tests, over stdio (by
+// running the test binary again) or streamable HTTP.
+//
+// Its tools are echo {text}, add {a, b}, fail (reports a tool error) and
+// crash, which ends a stdio server process.
+package mcptest
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/MEKXH/golem/internal/mcp"
+)
+
+// ServeEnv makes Main serve stdio instead of running the tests
+const ServeEnv = "GOLEM_MCPTEST_SERVE"
+
+// Main serves the fake over stdin/stdout when the process was started by
+// StdioCommand. Call it first in TestMain.
+func Main() {
+	if os.Getenv(ServeEnv) == "" {
+		return
+	}
+	_ = NewServer().ServeStdio(os.Stdin, os.Stdout)
+	os.Exit(0)
+}
+
+// StdioCommand returns how to start the fake as a stdio server: the
+// running test binary with ServeEnv set
+func StdioCommand() (string, []string) {
+	return os.Args[0], []string{ServeEnv + "=1"}
+}
+
+// Server is the fake; it counts initializations and tool calls
+type Server struct {
+	mu          sync.Mutex
+	initialized int
+	calls       []string
+	sessions    map[string]bool
+}
+
+// NewServer creates the fake
+func NewServer() *Server {
+	return &Server{sessions: make(map[string]bool)}
+}
+
+// Initialized returns how many sessions were initialized
+func (s *Server) Initialized() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initialized
+}
+
+// Calls returns the names of the tools called so far
+func (s *Server) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+// ExpireSessions forgets all HTTP sessions, as a restarted server would
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]bool)
+}
+
+var tools = []mcp.Tool{
+	{
+		Name:        "echo",
+		Description: "Echo the text back",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string","description":"Text to echo"}},"required":["text"]}`),
+	},
+	{
+		Name:        "add",
+		Description: "Add two numbers",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"a":{"type":"number"},"b":{"type":"number"}},"required":["a","b"]}`),
+	},
+	{
+		Name:        "fail",
+		Description: "Always reports an error",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	},
+	{
+		Name:        "crash",
+		Description: "Exits the server process",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	},
+}
+
+// Handle answers one message; notifications get no reply
+func (s *Server) Handle(msg *mcp.Message) *mcp.Message {
+	if !msg.IsRequest() {
+		return nil
+	}
+	var (
+		result any
+		err    error
+	)
+	switch msg.Method {
+	case "initialize":
+		s.mu.Lock()
+		s.initialized++
+		s.mu.Unlock()
+		result = mcp.InitializeResult{
+			ProtocolVersion: mcp.ProtocolVersion,
+			Capabilities:    map[string]any{"tools": map[string]any{}},
+			ServerInfo:      mcp.Implementation{Name: "fake", Version: "1"},
+		}
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		// Two pages, to exercise pagination
+		var params mcp.ListToolsParams
+		_ = json.Unmarshal(msg.Params, &params)
+		if params.Cursor == "" {
+			result = mcp.ListToolsResult{Tools: tools[:2], NextCursor: "page2"}
+		} else {
+			result = mcp.ListToolsResult{Tools: tools[2:]}
+		}
+	case "tools/call":
+		result, err = s.call(msg.Params)
+	default:
+		return mcp.NewError(msg.ID, mcp.CodeMethodNotFound, "method not found: "+msg.Method)
+	}
+	if err != nil {
+		return mcp.NewError(msg.ID, mcp.CodeInvalidParams, err.Error())
+	}
+	reply, err := mcp.NewResult(msg.ID, result)
+	if err != nil {
+		return mcp.NewError(msg.ID, mcp.CodeInternalError, err.Error())
+	}
+	return reply
+}
+
+func (s *Server) call(raw json.RawMessage) (*mcp.CallToolResult, error) {
+	var params mcp.CallToolParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.calls = append(s.calls, params.Name)
+	s.mu.Unlock()
+
+	var args struct {
+		Text string  `json:"text"`
+		A    float64 `json:"a"`
+		B    float64 `json:"b"`
+	}
+	if err := json.Unmarshal(params.Arguments, &args); err != nil {
+		return nil, err
+	}
+	switch params.Name {
+	case "echo":
+		return mcp.TextResult(args.Text), nil
+	case "add":
+		return mcp.TextResult(fmt.Sprint(args.A + args.B)), nil
+	case "fail":
+		result := mcp.TextResult("something broke")
+		result.IsError = true
+		return result, nil
+	case "crash":
+		os.Exit(3)
+	}
+	return nil, fmt.Errorf("unknown tool %q", params.Name)
+}
+
+// ServeStdio answers newline-delimited messages until r ends
+func (s *Server) ServeStdio(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		var msg mcp.Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if reply := s.Handle(&msg); reply != nil {
+			if err := enc.Encode(reply); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// ServeHTTP implements the streamable HTTP transport. Tool calls are
+// answered as an event stream, everything else as plain JSON.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	session := r.Header.Get("Mcp-Session-Id")
+	if r.Method == http.MethodDelete {
+		s.mu.Lock()
+		delete(s.sessions, session)
+		s.mu.Unlock()
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var msg mcp.Message
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if msg.Method == "initialize" {
+		b := make([]byte, 8)
+		_, _ = rand.Read(b)
+		session = hex.EncodeToString(b)
+		s.mu.Lock()
+		s.sessions[session] = true
+		s.mu.Unlock()
+		w.Header().Set("Mcp-Session-Id", session)
+	} else {
+		s.mu.Lock()
+		known := s.sessions[session]
+		s.mu.Unlock()
+		if !known {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+	}
+
+	reply := s.Handle(&msg)
+	if reply == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	data, _ := json.Marshal(reply)
+	if msg.Method == "tools/call" {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{}}\n\n")
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
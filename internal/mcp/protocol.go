@@ -0,0 +1,189 @@
+// Package mcp speaks the Model Context Protocol: JSON-RPC 2.0 messages over
+// a child process's stdin/stdout or over streamable HTTP. Only the tool
+// methods are implemented.
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ProtocolVersion is the MCP revision requested during initialize
+const ProtocolVersion = "2025-06-18"
+
+// JSON-RPC error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Message is a JSON-RPC request, notification or response
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// IsRequest reports whether the message expects a response
+func (m *Message) IsRequest() bool {
+	return m.Method != "" && len(m.ID) > 0
+}
+
+// IsResponse reports whether the message answers a request
+func (m *Message) IsResponse() bool {
+	return m.Method == "" && len(m.ID) > 0
+}
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message)
+}
+
+// NewRequest builds a request; id may be nil for a notification
+func NewRequest(id any, method string, params any) (*Message, error) {
+	msg := &Message{JSONRPC: "2.0", Method: method}
+	if id != nil {
+		raw, err := json.Marshal(id)
+		if err != nil {
+			return nil, err
+		}
+		msg.ID = raw
+	}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		msg.Params = raw
+	}
+	return msg, nil
+}
+
+// NewResult builds the response to a request
+func NewResult(id json.RawMessage, result any) (*Message, error) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{JSONRPC: "2.0", ID: id, Result: raw}, nil
+}
+
+// NewError builds an error response to a request
+func NewError(id json.RawMessage, code int, message string) *Message {
+	return &Message{JSONRPC: "2.0", ID: id, Error: &Error{Code: code, Message: message}}
+}
+
+// Implementation names a client or server
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// InitializeParams is sent by the client first
+type InitializeParams struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ClientInfo      Implementation `json:"clientInfo"`
+}
+
+// InitializeResult describes the server
+type InitializeResult struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ServerInfo      Implementation `json:"serverInfo"`
+	Instructions    string         `json:"instructions,omitempty"`
+}
+
+// Tool is a tool offered by a server
+type Tool struct {
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// ListToolsParams pages through tools/list
+type ListToolsParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ListToolsResult is one page of tools
+type ListToolsResult struct {
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// CallToolParams invokes a tool
+type CallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// CallToolResult is a tool's output; IsError marks a failure reported by the tool
+type CallToolResult struct {
+	Content           []Content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// Content is one item of tool output: text, image, audio, resource or resource_link
+type Content struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	Data     string    `json:"data,omitempty"`
+	MimeType string    `json:"mimeType,omitempty"`
+	URI      string    `json:"uri,omitempty"`
+	Resource *Resource `json:"resource,omitempty"`
+}
+
+// Resource is embedded resource content
+type Resource struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// TextResult wraps text as a successful tool result
+func TextResult(text string) *CallToolResult {
+	return &CallToolResult{Content: []Content{{Type: "text", Text: text}}}
+}
+
+// Text renders the result for a model: text items as they are, other
+// items as short placeholders, and structured content when there is no text
+func (r *CallToolResult) Text() string {
+	var parts []string
+	for _, c := range r.Content {
+		switch c.Type {
+		case "text":
+			parts = append(parts, c.Text)
+		case "resource":
+			if c.Resource != nil && c.Resource.Text != "" {
+				parts = append(parts, c.Resource.Text)
+			} else if c.Resource != nil {
+				parts = append(parts, fmt.Sprintf("[resource %s]", c.Resource.URI))
+			}
+		case "resource_link":
+			parts = append(parts, fmt.Sprintf("[resource %s]", c.URI))
+		default:
+			parts = append(parts, fmt.Sprintf("[%s %s]", c.Type, c.MimeType))
+		}
+	}
+	if len(parts) == 0 && len(r.StructuredContent) > 0 {
+		return string(r.StructuredContent)
+	}
+	return strings.Join(parts, "\n")
+}
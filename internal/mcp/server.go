@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// ServerTool is a tool offered by a Server. Errors returned by Call are
+// reported to the client as tool errors, which the client's model can read.
+type ServerTool struct {
+	Tool Tool
+	Call func(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+// Server answers MCP clients with a fixed set of tools
+type Server struct {
+	instructions string
+	tools        []ServerTool
+	byName       map[string]ServerTool
+}
+
+// NewServer creates a server; instructions are shown to clients on initialize
+func NewServer(instructions string, tools []ServerTool) *Server {
+	byName := make(map[string]ServerTool, len(tools))
+	for _, t := range tools {
+		byName[t.Tool.Name] = t
+	}
+	return &Server{instructions: instructions, tools: tools, byName: byName}
+}
+
+// Handle answers one message; notifications get no reply
+func (s *Server) Handle(ctx context.Context, msg *Message) *Message {
+	if !msg.IsRequest() {
+		return nil
+	}
+	var result any
+	switch msg.Method {
+	case "initialize":
+		var params InitializeParams
+		_ = json.Unmarshal(msg.Params, &params)
+		version := ProtocolVersion
+		if params.ProtocolVersion != "" && params.ProtocolVersion < version {
+			// Older clients get their revision; the tool methods are unchanged
+			version = params.ProtocolVersion
+		}
+		result = InitializeResult{
+			ProtocolVersion: version,
+			Capabilities:    map[string]any{"tools": map[string]any{}},
+			ServerInfo:      golemInfo,
+			Instructions:    s.instructions,
+		}
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		list := make([]Tool, 0, len(s.tools))
+		for _, t := range s.tools {
+			list = append(list, t.Tool)
+		}
+		result = ListToolsResult{Tools: list}
+	case "tools/call":
+		var params CallToolParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return NewError(msg.ID, CodeInvalidParams, err.Error())
+		}
+		t, ok := s.byName[params.Name]
+		if !ok {
+			return NewError(msg.ID, CodeInvalidParams, "unknown tool: "+params.Name)
+		}
+		text, err := t.Call(ctx, params.Arguments)
+		if err != nil {
+			res := TextResult(err.Error())
+			res.IsError = true
+			result = res
+		} else {
+			result = TextResult(text)
+		}
+	default:
+		return NewError(msg.ID, CodeMethodNotFound, "method not found: "+msg.Method)
+	}
+	reply, err := NewResult(msg.ID, result)
+	if err != nil {
+		return NewError(msg.ID, CodeInternalError, err.Error())
+	}
+	return reply
+}
+
+// ServeStdio reads newline-delimited messages from r and writes replies to
+// w until r ends or ctx is done. Requests run concurrently, so a long tool
+// call does not hold up pings or other calls.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		writeMu sync.Mutex
+		wg      sync.WaitGroup
+	)
+	enc := json.NewEncoder(w)
+	send := func(msg *Message) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := enc.Encode(msg); err != nil {
+			slog.Warn("mcp: write reply failed", "error", err)
+		}
+	}
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case err := <-readErr:
+			wg.Wait()
+			return err
+		case line := <-lines:
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var msg Message
+			if err := json.Unmarshal(line, &msg); err != nil {
+				send(NewError(json.RawMessage("null"), CodeParseError, fmt.Sprintf("invalid message: %v", err)))
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if reply := s.Handle(ctx, &msg); reply != nil {
+					send(reply)
+				}
+			}()
+		}
+	}
+}
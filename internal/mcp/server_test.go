@@ -0,0 +1,123 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/mcp"
+)
+
+func testServer(release <-chan struct{}) *mcp.Server {
+	return mcp.NewServer("test server", []mcp.ServerTool{
+		{
+			Tool: mcp.Tool{Name: "upper", InputSchema: json.RawMessage(`{"type":"object"}`)},
+			Call: func(ctx context.Context, args json.RawMessage) (string, error) {
+				var in struct{ Text string }
+				if err := json.Unmarshal(args, &in); err != nil {
+					return "", err
+				}
+				return strings.ToUpper(in.Text), nil
+			},
+		},
+		{
+			Tool: mcp.Tool{Name: "broken", InputSchema: json.RawMessage(`{"type":"object"}`)},
+			Call: func(ctx context.Context, args json.RawMessage) (string, error) {
+				return "", errors.New("access denied")
+			},
+		},
+		{
+			Tool: mcp.Tool{Name: "slow", InputSchema: json.RawMessage(`{"type":"object"}`)},
+			Call: func(ctx context.Context, args json.RawMessage) (string, error) {
+				<-release
+				return "finally", nil
+			},
+		},
+	})
+}
+
+func TestServer_ServeStdio(t *testing.T) {
+	release := make(chan struct{})
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- testServer(release).ServeStdio(context.Background(), inR, outW)
+		outW.Close()
+	}()
+
+	dec := json.NewDecoder(outR)
+	send := func(line string) {
+		t.Helper()
+		if _, err := io.WriteString(inW, line+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recv := func() *mcp.Message {
+		t.Helper()
+		var msg mcp.Message
+		if err := dec.Decode(&msg); err != nil {
+			t.Fatal(err)
+		}
+		return &msg
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"t","version":"1"}}}`)
+	var init mcp.InitializeResult
+	if err := json.Unmarshal(recv().Result, &init); err != nil || init.ProtocolVersion != "2025-03-26" || init.Instructions != "test server" {
+		t.Fatalf("expected the client's older revision, got %+v %v", init, err)
+	}
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(``)
+
+	send(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	var list mcp.ListToolsResult
+	if err := json.Unmarshal(recv().Result, &list); err != nil || len(list.Tools) != 3 {
+		t.Fatalf("unexpected tools %+v %v", list, err)
+	}
+
+	// A slow call does not hold up the ones after it
+	send(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"slow"}}`)
+	send(`{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"upper","arguments":{"text":"hi"}}}`)
+	msg := recv()
+	var result mcp.CallToolResult
+	if string(msg.ID) != `"a"` || json.Unmarshal(msg.Result, &result) != nil || result.Text() != "HI" {
+		t.Fatalf("unexpected reply %s %s", msg.ID, msg.Result)
+	}
+	close(release)
+	if msg := recv(); string(msg.ID) != "3" {
+		t.Fatalf("expected the slow reply, got %s", msg.ID)
+	}
+
+	send(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"broken"}}`)
+	result = mcp.CallToolResult{}
+	if err := json.Unmarshal(recv().Result, &result); err != nil || !result.IsError || result.Text() != "access denied" {
+		t.Fatalf("expected a tool error result, got %+v %v", result, err)
+	}
+	send(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"missing"}}`)
+	if msg := recv(); msg.Error == nil || msg.Error.Code != mcp.CodeInvalidParams {
+		t.Fatalf("expected invalid params, got %+v", msg)
+	}
+	send(`{"jsonrpc":"2.0","id":6,"method":"resources/list"}`)
+	if msg := recv(); msg.Error == nil || msg.Error.Code != mcp.CodeMethodNotFound {
+		t.Fatalf("expected method not found, got %+v", msg)
+	}
+	send(`not json`)
+	if msg := recv(); msg.Error == nil || msg.Error.Code != mcp.CodeParseError {
+		t.Fatalf("expected a parse error, got %+v", msg)
+	}
+
+	inW.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a clean end, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop at end of input")
+	}
+}
@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// stdioTransport runs the server as a child process exchanging
+// newline-delimited JSON on stdin and stdout; stderr goes to the debug log
+type stdioTransport struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[string]chan *Message
+	done    chan struct{}
+	err     error
+}
+
+func startStdio(opts Options) (*stdioTransport, error) {
+	cmd := exec.Command(opts.Command, opts.Args...)
+	cmd.Dir = opts.Dir
+	cmd.Env = append(os.Environ(), opts.Env...)
+	cmd.Stderr = &stderrLog{server: opts.Name}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", opts.Command, err)
+	}
+	t := &stdioTransport{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[string]chan *Message),
+		done:    make(chan struct{}),
+	}
+	go t.readLoop(stdout)
+	return t, nil
+}
+
+func (t *stdioTransport) readLoop(stdout io.Reader) {
+	r := bufio.NewReader(stdout)
+	var err error
+	for {
+		var line []byte
+		line, err = r.ReadBytes('\n')
+		if len(line) > 0 {
+			t.dispatch(line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if waitErr := t.cmd.Wait(); waitErr != nil {
+		err = fmt.Errorf("%w: %v", errClosed, waitErr)
+	} else {
+		err = errClosed
+	}
+	t.mu.Lock()
+	t.err = err
+	t.mu.Unlock()
+	close(t.done)
+}
+
+func (t *stdioTransport) dispatch(line []byte) {
+	var msg Message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		slog.Debug("mcp: skipping invalid message", "error", err)
+		return
+	}
+	switch {
+	case msg.IsResponse():
+		t.mu.Lock()
+		ch := t.pending[string(msg.ID)]
+		delete(t.pending, string(msg.ID))
+		t.mu.Unlock()
+		if ch != nil {
+			ch <- &msg
+		}
+	case msg.IsRequest():
+		// Servers may ping; other requests need capabilities we do not offer
+		reply := NewError(msg.ID, CodeMethodNotFound, "method not supported: "+msg.Method)
+		if msg.Method == "ping" {
+			reply, _ = NewResult(msg.ID, struct{}{})
+		}
+		_ = t.write(reply)
+	}
+}
+
+func (t *stdioTransport) write(msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("%w: %v", errClosed, err)
+	}
+	return nil
+}
+
+func (t *stdioTransport) roundTrip(ctx context.Context, req *Message) (*Message, error) {
+	ch := make(chan *Message, 1)
+	key := string(req.ID)
+	t.mu.Lock()
+	t.pending[key] = ch
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, key)
+		t.mu.Unlock()
+	}()
+
+	if err := t.write(req); err != nil {
+		return nil, err
+	}
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-t.done:
+		return nil, t.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *stdioTransport) notify(ctx context.Context, msg *Message) error {
+	return t.write(msg)
+}
+
+func (t *stdioTransport) alive() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// close ends the server by closing its stdin, killing it if it lingers
+func (t *stdioTransport) close() error {
+	t.stdin.Close()
+	select {
+	case <-t.done:
+	case <-time.After(2 * time.Second):
+		_ = t.cmd.Process.Kill()
+		<-t.done
+	}
+	return nil
+}
+
+// stderrLog forwards server stderr lines to the debug log
+type stderrLog struct {
+	server string
+}
+
+func (l *stderrLog) Write(p []byte) (int, error) {
+	slog.Debug("mcp server stderr", "server", l.server, "output", string(p))
+	return len(p), nil
+}
@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Embedding provider names
+const (
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+	ProviderHash   = "hash"
+)
+
+const (
+	defaultHashDim   = 256
+	embedTimeout     = 60 * time.Second
+	maxEmbedResponse = 64 << 20
+)
+
+// Embedder turns texts into vectors. Model identifies the vector space so
+// an index built with a different model is rebuilt rather than mixed.
+type Embedder interface {
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderOptions select and configure an embedding provider
+type EmbedderOptions struct {
+	Provider string
+	BaseURL  string
+	APIKey   string
+	Model    string
+}
+
+// NewEmbedder creates an embedder: an OpenAI-compatible /embeddings
+// endpoint, Ollama, or the offline hashing embedder
+func NewEmbedder(opts EmbedderOptions) (Embedder, error) {
+	client := &http.Client{Timeout: embedTimeout}
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+	switch provider := strings.ToLower(strings.TrimSpace(opts.Provider)); provider {
+	case ProviderOpenAI, "":
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
+		model := opts.Model
+		if model == "" {
+			model = "text-embedding-3-small"
+		}
+		return &openAIEmbedder{baseURL: baseURL, apiKey: opts.APIKey, model: model, client: client}, nil
+	case ProviderOllama:
+		if baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+		model := opts.Model
+		if model == "" {
+			model = "nomic-embed-text"
+		}
+		return &ollamaEmbedder{baseURL: baseURL, model: model, client: client}, nil
+	case ProviderHash:
+		return NewHashEmbedder(defaultHashDim), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", provider)
+	}
+}
+
+type openAIEmbedder struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+func (e *openAIEmbedder) Model() string { return ProviderOpenAI + ":" + e.model }
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	headers := map[string]string{}
+	if e.apiKey != "" {
+		headers["Authorization"] = "Bearer " + e.apiKey
+	}
+	body := map[string]any{"model": e.model, "input": texts}
+	if err := postJSON(ctx, e.client, e.baseURL+"/embeddings", headers, body, &resp); err != nil {
+		return nil, fmt.Errorf("openai embeddings: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	return vectors, checkVectors(vectors)
+}
+
+type ollamaEmbedder struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func (e *ollamaEmbedder) Model() string { return ProviderOllama + ":" + e.model }
+
+func (e *ollamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	body := map[string]any{"model": e.model, "input": texts}
+	if err := postJSON(ctx, e.client, e.baseURL+"/api/embed", nil, body, &resp); err != nil {
+		return nil, fmt.Errorf("ollama embeddings: %w", err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama embeddings: got %d vectors for %d texts", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, checkVectors(resp.Embeddings)
+}
+
+func checkVectors(vectors [][]float32) error {
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return fmt.Errorf("missing embedding for input %d", i)
+		}
+	}
+	return nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxEmbedResponse))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 512 {
+			msg = msg[:512] + "..."
+		}
+		return fmt.Errorf("%s: %s", resp.Status, msg)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// HashEmbedder maps words into a fixed number of buckets. It needs no model
+// or network and is deterministic, which suits tests and offline use, but it
+// only matches shared words, not meaning.
+type HashEmbedder struct {
+	dim int
+}
+
+// NewHashEmbedder creates a hashing embedder with dim dimensions
+func NewHashEmbedder(dim int) *HashEmbedder {
+	if dim <= 0 {
+		dim = defaultHashDim
+	}
+	return &HashEmbedder{dim: dim}
+}
+
+// Model implements Embedder
+func (h *HashEmbedder) Model() string { return fmt.Sprintf("%s:%d", ProviderHash, h.dim) }
+
+// Embed implements Embedder
+func (h *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, h.dim)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		for _, w := range words {
+			f := fnv.New32a()
+			_, _ = f.Write([]byte(w))
+			sum := f.Sum32()
+			sign := float32(1)
+			if sum&(1<<31) != 0 {
+				sign = -1
+			}
+			v[int(sum%uint32(h.dim))] += sign
+		}
+		vectors[i] = normalize(v)
+	}
+	return vectors, nil
+}
+
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// cosine returns the cosine similarity of two vectors of equal length
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
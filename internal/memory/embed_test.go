@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHashEmbedder_SharedWordsAreCloser(t *testing.T) {
+	e := NewHashEmbedder(64)
+	vectors, err := e.Embed(context.Background(), []string{
+		"The user likes oat milk", "OAT milk, please", "Trip to Lisbon",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := e.Embed(context.Background(), []string{"The user likes oat milk"})
+	if cosine(vectors[0], again[0]) < 0.999 {
+		t.Fatal("expected embedding to be deterministic")
+	}
+	if cosine(vectors[0], vectors[1]) <= cosine(vectors[0], vectors[2]) {
+		t.Fatal("expected texts sharing words to be more similar")
+	}
+}
+
+func TestNewEmbedder_HTTPProviders(t *testing.T) {
+	var auth string
+	var got struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		switch r.URL.Path {
+		case "/v1/embeddings":
+			// Out of order on purpose; index decides placement
+			_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+		case "/api/embed":
+			_, _ = w.Write([]byte(`{"embeddings":[[1,0],[0,1]]}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	openai, err := NewEmbedder(EmbedderOptions{Provider: "openai", BaseURL: srv.URL + "/v1/", APIKey: "sk-test", Model: "small"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors, err := openai.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 || auth != "Bearer sk-test" || got.Model != "small" || len(got.Input) != 2 {
+		t.Fatalf("unexpected openai exchange: %v auth=%q req=%+v", vectors, auth, got)
+	}
+	if openai.Model() != "openai:small" {
+		t.Fatalf("unexpected model id %q", openai.Model())
+	}
+
+	ollama, _ := NewEmbedder(EmbedderOptions{Provider: "ollama", BaseURL: srv.URL})
+	vectors, err = ollama.Embed(context.Background(), []string{"a", "b"})
+	if err != nil || len(vectors) != 2 || got.Model != "nomic-embed-text" {
+		t.Fatalf("unexpected ollama result %v, %v (model %q)", vectors, err, got.Model)
+	}
+
+	broken, _ := NewEmbedder(EmbedderOptions{Provider: "openai", BaseURL: srv.URL + "/missing"})
+	if _, err := broken.Embed(context.Background(), []string{"a"}); err == nil {
+		t.Fatal("expected http error to surface")
+	}
+	if _, err := NewEmbedder(EmbedderOptions{Provider: "word2vec"}); err == nil {
+		t.Fatal("expected unknown provider to be rejected")
+	}
+}
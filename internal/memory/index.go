@@ -0,0 +1,661 @@
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/session"
+)
+
+// Kinds of indexed text
+const (
+	KindMemory  = "memory"
+	KindNote    = "note"
+	KindSession = "session"
+)
+
+const (
+	// IndexFile is the index location relative to the workspace
+	IndexFile       = "state/memory_index.json"
+	embedBatchSize  = 64
+	maxSessionChars = 1200
+	defaultTopK     = 5
+	// maxAppended is how many updates beyond one per source the index file
+	// collects before it is written whole again
+	maxAppended = 256
+)
+
+// IndexOptions configure a semantic index over a workspace
+type IndexOptions struct {
+	Workspace string
+	Embedder  Embedder
+	// Sessions also indexes past conversations from <workspace>/sessions.
+	// Searches only recall turns of the conversation they are made for,
+	// unless AllSessions shares turns between chats.
+	Sessions    bool
+	AllSessions bool
+	// SessionStore, when set, is read for past conversations instead of
+	// the JSONL files, e.g. for the SQLite store
+	SessionStore session.Store
+	// SkipRecent is how many of the latest messages of the current session
+	// are already in the prompt and are not recalled
+	SkipRecent int
+	// MinScore drops results with a lower cosine similarity
+	MinScore float64
+}
+
+// Recall is a piece of memory or conversation relevant to a query
+type Recall struct {
+	Kind   string  `json:"kind"`
+	Source string  `json:"source"`
+	Date   string  `json:"date,omitempty"`
+	Text   string  `json:"text"`
+	Score  float64 `json:"score"`
+}
+
+// Index keeps embeddings of memory entries, daily notes and past session
+// turns in a JSON file. Sources are re-embedded only when they change, and
+// changes are appended to the file rather than rewriting it.
+type Index struct {
+	opts IndexOptions
+	mu   sync.Mutex
+	data *indexData
+}
+
+type indexData struct {
+	Model   string
+	Sources map[string]sourceStamp
+	// Items holds the items of each source
+	Items map[string][]indexItem
+	// records counts the updates appended since the file was written whole
+	records int
+	// compact writes the file whole on the next sync
+	compact bool
+}
+
+// indexRecord is one JSON value of the index file. The file starts with a
+// snapshot of the whole index; each later record replaces the items of one
+// source, keeping the first Keep of them with After moved by Shift, or drops
+// the source when Stamp is nil. Vectors are only written with the first item
+// of their hash.
+type indexRecord struct {
+	Model   string                 `json:"model,omitempty"`
+	Sources map[string]sourceStamp `json:"sources,omitempty"`
+	Source  string                 `json:"source,omitempty"`
+	Stamp   *sourceStamp           `json:"stamp,omitempty"`
+	Keep    int                    `json:"keep,omitempty"`
+	Shift   int                    `json:"shift,omitempty"`
+	Items   []indexItem            `json:"items,omitempty"`
+}
+
+type sourceStamp struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	// key names the session of a source read from SessionStore
+	key string
+}
+
+type indexItem struct {
+	Source string    `json:"source"`
+	Kind   string    `json:"kind"`
+	Date   string    `json:"date,omitempty"`
+	Text   string    `json:"text"`
+	Hash   string    `json:"hash"`
+	Vector []float32 `json:"vector,omitempty"`
+	// After counts the session messages that follow a turn
+	After int `json:"after,omitempty"`
+}
+
+// chunk is text waiting to be embedded
+type chunk struct {
+	kind, date, text string
+	after            int
+}
+
+// NewIndex creates an index; nothing is read until the first Sync or Search
+func NewIndex(opts IndexOptions) *Index {
+	return &Index{opts: opts}
+}
+
+// Path returns the index file location
+func (x *Index) Path() string {
+	return filepath.Join(x.opts.Workspace, filepath.FromSlash(IndexFile))
+}
+
+// Len returns the number of indexed items
+func (x *Index) Len() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.data == nil {
+		return 0
+	}
+	return x.data.len()
+}
+
+// Sync embeds new and changed sources and drops deleted ones
+func (x *Index) Sync(ctx context.Context) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.sync(ctx, false)
+}
+
+// Rebuild discards the index and embeds every source again
+func (x *Index) Rebuild(ctx context.Context) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.sync(ctx, true)
+}
+
+// Search syncs the index and returns up to k items most similar to query.
+// Session turns come from the current session (a session key) only, unless
+// AllSessions is set, and its latest SkipRecent messages are left out.
+func (x *Index) Search(ctx context.Context, query string, k int, current string) ([]Recall, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	if k <= 0 {
+		k = defaultTopK
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.sync(ctx, false); err != nil {
+		return nil, err
+	}
+	if x.data.len() == 0 {
+		return nil, nil
+	}
+	vectors, err := x.opts.Embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	own := ""
+	if current != "" {
+		own = sessionSource(session.FileName(current))
+	}
+	var recalls []Recall
+	seen := make(map[string]bool)
+	for _, source := range slices.Sorted(maps.Keys(x.data.Items)) {
+		for _, item := range x.data.Items[source] {
+			if seen[item.Hash] {
+				continue
+			}
+			if item.Kind == KindSession {
+				if item.Source == own && item.After < x.opts.SkipRecent {
+					continue
+				}
+				if item.Source != own && !x.opts.AllSessions {
+					continue
+				}
+			}
+			score := cosine(vectors[0], item.Vector)
+			if score < x.opts.MinScore {
+				continue
+			}
+			seen[item.Hash] = true
+			recalls = append(recalls, Recall{Kind: item.Kind, Source: item.Source, Date: item.Date, Text: item.Text, Score: score})
+		}
+	}
+	sort.SliceStable(recalls, func(i, j int) bool { return recalls[i].Score > recalls[j].Score })
+	if len(recalls) > k {
+		recalls = recalls[:k]
+	}
+	return recalls, nil
+}
+
+func (x *Index) sync(ctx context.Context, rebuild bool) error {
+	if x.data == nil && !rebuild {
+		x.data = x.load()
+	}
+	if rebuild || x.data == nil || x.data.Model != x.opts.Embedder.Model() {
+		x.data = &indexData{Model: x.opts.Embedder.Model(), Sources: map[string]sourceStamp{}, Items: map[string][]indexItem{}, compact: true}
+	}
+
+	current, err := x.sources(ctx)
+	if err != nil {
+		return err
+	}
+	changed := make(map[string]bool)
+	for source, stamp := range current {
+		if old, ok := x.data.Sources[source]; !ok || old.Size != stamp.Size || !old.ModTime.Equal(stamp.ModTime) {
+			changed[source] = true
+		}
+	}
+	for source := range x.data.Sources {
+		if _, ok := current[source]; !ok {
+			changed[source] = true
+		}
+	}
+	if len(changed) == 0 && !x.data.compact {
+		return nil
+	}
+
+	// Vectors are reused by content hash, so editing one entry only embeds that entry
+	known := make(map[string][]float32)
+	for _, items := range x.data.Items {
+		for _, item := range items {
+			known[item.Hash] = item.Vector
+		}
+	}
+
+	sources := slices.Sorted(maps.Keys(changed))
+	updated := make(map[string][]indexItem, len(sources))
+	var pending []*indexItem
+	for _, source := range sources {
+		if _, ok := current[source]; !ok {
+			continue
+		}
+		chunks := x.chunks(ctx, source, current[source])
+		items := make([]indexItem, len(chunks))
+		for i, c := range chunks {
+			items[i] = indexItem{Source: source, Kind: c.kind, Date: c.date, Text: c.text, Hash: hashText(c.text), After: c.after}
+			if v, ok := known[items[i].Hash]; ok {
+				items[i].Vector = v
+			} else {
+				pending = append(pending, &items[i])
+			}
+		}
+		updated[source] = items
+	}
+
+	embedded := make(map[string]bool, len(pending))
+	for start := 0; start < len(pending); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(pending))
+		texts := make([]string, 0, end-start)
+		for _, item := range pending[start:end] {
+			texts = append(texts, item.Text)
+		}
+		vectors, err := x.opts.Embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("embed memory: %w", err)
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("embed memory: got %d vectors for %d texts", len(vectors), len(texts))
+		}
+		for j, item := range pending[start:end] {
+			item.Vector = vectors[j]
+			embedded[item.Hash] = true
+		}
+	}
+
+	records := make([]indexRecord, 0, len(sources))
+	for _, source := range sources {
+		stamp, ok := current[source]
+		if !ok {
+			records = append(records, indexRecord{Source: source})
+			delete(x.data.Items, source)
+			continue
+		}
+		items := updated[source]
+		keep, shift := unchangedPrefix(x.data.Items[source], items)
+		rec := indexRecord{Source: source, Stamp: &stamp, Keep: keep, Shift: shift}
+		for _, item := range items[keep:] {
+			if !embedded[item.Hash] {
+				item.Vector = nil
+			}
+			delete(embedded, item.Hash)
+			rec.Items = append(rec.Items, item)
+		}
+		records = append(records, rec)
+		x.data.Items[source] = items
+	}
+	x.data.Sources = current
+
+	if x.data.compact || x.data.records+len(records) > len(x.data.Sources)+maxAppended {
+		return x.save()
+	}
+	return x.append(records)
+}
+
+// unchangedPrefix counts the leading items that are the same in both lists,
+// allowing After to move by one shift as when messages follow a session turn
+func unchangedPrefix(old, items []indexItem) (keep, shift int) {
+	for keep < len(old) && keep < len(items) {
+		a, b := old[keep], items[keep]
+		if a.Hash != b.Hash || a.Kind != b.Kind || a.Date != b.Date {
+			break
+		}
+		if keep == 0 {
+			shift = b.After - a.After
+		} else if b.After-a.After != shift {
+			break
+		}
+		keep++
+	}
+	if keep == 0 {
+		shift = 0
+	}
+	return keep, shift
+}
+
+// sources lists the files to index, keyed by path relative to the workspace.
+// Sessions from SessionStore are keyed as if they were JSONL files.
+func (x *Index) sources(ctx context.Context) (map[string]sourceStamp, error) {
+	sources := make(map[string]sourceStamp)
+	add := func(dir string, keep func(name string) bool) {
+		files, _ := os.ReadDir(filepath.Join(x.opts.Workspace, dir))
+		for _, f := range files {
+			if f.IsDir() || !keep(f.Name()) {
+				continue
+			}
+			info, err := f.Info()
+			if err != nil {
+				continue
+			}
+			sources[dir+"/"+f.Name()] = sourceStamp{Size: info.Size(), ModTime: info.ModTime()}
+		}
+	}
+	add("memory", func(name string) bool { return name == FileName || dailyName.MatchString(name) })
+	switch {
+	case !x.opts.Sessions:
+	case x.opts.SessionStore != nil:
+		infos, err := x.opts.SessionStore.List(ctx, session.Filter{})
+		if err != nil {
+			return nil, fmt.Errorf("list sessions: %w", err)
+		}
+		for _, info := range infos {
+			sources[sessionSource(session.FileName(info.Key))] = sourceStamp{Size: info.Size, ModTime: info.Updated, key: info.Key}
+		}
+	default:
+		add("sessions", func(name string) bool { return strings.HasSuffix(name, ".jsonl") })
+	}
+	return sources, nil
+}
+
+func sessionSource(name string) string {
+	return "sessions/" + name
+}
+
+func (x *Index) chunks(ctx context.Context, source string, stamp sourceStamp) []chunk {
+	path := filepath.Join(x.opts.Workspace, filepath.FromSlash(source))
+	name := filepath.Base(source)
+	switch {
+	case stamp.key != "":
+		msgs, err := x.opts.SessionStore.Messages(ctx, stamp.key, 0, 0)
+		if err != nil {
+			return nil
+		}
+		return sessionChunks(msgs)
+	case strings.HasPrefix(source, "sessions/"):
+		return sessionChunks(readSessionFile(path))
+	case name == FileName:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		return memoryChunks(string(data))
+	default:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		return noteChunks(strings.TrimSuffix(name, ".md"), string(data))
+	}
+}
+
+// memoryChunks yields each entry and each hand-written paragraph
+func memoryChunks(text string) []chunk {
+	var chunks []chunk
+	for _, b := range parse(text) {
+		if b.entry != nil {
+			content := b.entry.Content
+			for _, t := range b.entry.Tags {
+				content += " #" + t
+			}
+			chunks = append(chunks, chunk{kind: KindMemory, date: b.entry.Date, text: content})
+			continue
+		}
+		for _, para := range strings.Split(b.raw, "\n\n") {
+			para = strings.TrimSpace(para)
+			if para == "" || (!strings.Contains(para, "\n") && strings.HasPrefix(para, "#")) {
+				continue
+			}
+			chunks = append(chunks, chunk{kind: KindMemory, text: para})
+		}
+	}
+	return chunks
+}
+
+func noteChunks(date, text string) []chunk {
+	var chunks []chunk
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		chunks = append(chunks, chunk{kind: KindNote, date: date, text: strings.TrimPrefix(line, "- ")})
+	}
+	return chunks
+}
+
+// readSessionFile returns the messages of a JSONL session file
+func readSessionFile(path string) []*session.Message {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var msgs []*session.Message
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var msg session.Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err == nil {
+			msgs = append(msgs, &msg)
+		}
+	}
+	return msgs
+}
+
+// sessionChunks pairs each user message with the reply that follows it
+func sessionChunks(msgs []*session.Message) []chunk {
+	var chunks []chunk
+	var pending *session.Message
+	// positions holds the index of each chunk's user message
+	var positions []int
+	flush := func(reply string) {
+		if pending == nil {
+			return
+		}
+		text := "User: " + clip(pending.Content)
+		if reply != "" {
+			text += "\nAssistant: " + clip(reply)
+		}
+		date := ""
+		if !pending.Timestamp.IsZero() {
+			date = pending.Timestamp.Format(dateLayout)
+		}
+		chunks = append(chunks, chunk{kind: KindSession, date: date, text: text})
+		pending = nil
+	}
+
+	for i, msg := range msgs {
+		if strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		switch msg.Role {
+		case "user":
+			flush("")
+			pending = msg
+			positions = append(positions, i)
+		case "assistant":
+			flush(msg.Content)
+		}
+	}
+	flush("")
+	for i := range chunks {
+		chunks[i].after = len(msgs) - 1 - positions[i]
+	}
+	return chunks
+}
+
+func clip(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxSessionChars {
+		return string(r[:maxSessionChars]) + "..."
+	}
+	return s
+}
+
+func hashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:12])
+}
+
+func (d *indexData) len() int {
+	n := 0
+	for _, items := range d.Items {
+		n += len(items)
+	}
+	return n
+}
+
+func (x *Index) load() *indexData {
+	f, err := os.Open(x.Path())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	var snap indexRecord
+	if err := dec.Decode(&snap); err != nil || snap.Model == "" {
+		return nil
+	}
+	d := &indexData{Model: snap.Model, Sources: snap.Sources, Items: map[string][]indexItem{}}
+	if d.Sources == nil {
+		d.Sources = map[string]sourceStamp{}
+	}
+	vectors := make(map[string][]float32)
+	for _, item := range snap.Items {
+		d.Items[item.Source] = append(d.Items[item.Source], item)
+		if item.Vector != nil {
+			vectors[item.Hash] = item.Vector
+		}
+	}
+	for {
+		var rec indexRecord
+		if err := dec.Decode(&rec); err != nil {
+			// A record cut short by a crash is dropped when the file is rewritten
+			d.compact = err != io.EOF
+			break
+		}
+		d.records++
+		if rec.Stamp == nil {
+			delete(d.Sources, rec.Source)
+			delete(d.Items, rec.Source)
+			continue
+		}
+		old := d.Items[rec.Source]
+		keep := min(rec.Keep, len(old))
+		items := make([]indexItem, 0, keep+len(rec.Items))
+		for _, item := range old[:keep] {
+			item.After += rec.Shift
+			items = append(items, item)
+		}
+		for _, item := range rec.Items {
+			item.Source = rec.Source
+			if item.Vector != nil {
+				vectors[item.Hash] = item.Vector
+			}
+			items = append(items, item)
+		}
+		d.Sources[rec.Source] = *rec.Stamp
+		d.Items[rec.Source] = items
+	}
+
+	// Sources with a vector missing from the file are embedded again
+	for source, items := range d.Items {
+		for i := range items {
+			if items[i].Vector = vectors[items[i].Hash]; items[i].Vector == nil {
+				delete(d.Sources, source)
+				delete(d.Items, source)
+				break
+			}
+		}
+	}
+	return d
+}
+
+// save writes the whole index as a snapshot
+func (x *Index) save() error {
+	snap := indexRecord{Model: x.data.Model, Sources: x.data.Sources}
+	written := make(map[string]bool)
+	for _, source := range slices.Sorted(maps.Keys(x.data.Items)) {
+		for _, item := range x.data.Items[source] {
+			if written[item.Hash] {
+				item.Vector = nil
+			}
+			written[item.Hash] = true
+			snap.Items = append(snap.Items, item)
+		}
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(x.Path())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".memory_index.*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), x.Path()); err != nil {
+		return err
+	}
+	x.data.records = 0
+	x.data.compact = false
+	return nil
+}
+
+// append adds records to the end of the index file
+func (x *Index) append(records []indexRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(x.Path(), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return x.save()
+	}
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		x.data.compact = true
+		return err
+	}
+	x.data.records += len(records)
+	return nil
+}
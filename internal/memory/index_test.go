@@ -0,0 +1,297 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/session"
+)
+
+// countingEmbedder records how many texts were embedded
+type countingEmbedder struct {
+	*HashEmbedder
+	texts int
+}
+
+func (c *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	c.texts += len(texts)
+	return c.HashEmbedder.Embed(ctx, texts)
+}
+
+func writeSession(t *testing.T, ws, key string, msgs ...session.Message) {
+	t.Helper()
+	dir := filepath.Join(ws, "sessions")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	for _, m := range msgs {
+		data, _ := json.Marshal(m)
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(filepath.Join(dir, session.FileName(key)), []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndex_SearchMemoriesAndSessions(t *testing.T) {
+	ws := t.TempDir()
+	store := NewStore(filepath.Join(ws, "memory"))
+	if _, err := store.Save("User is allergic to peanuts", []string{"health"}); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = store.Save("Favourite editor is helix", nil)
+	_, _ = store.AddNote("Booked flights to Lisbon", nil)
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	writeSession(t, ws, "telegram:1",
+		session.Message{Role: "user", Content: "Which wine goes with grilled sardines?", Timestamp: at},
+		session.Message{Role: "assistant", Content: "A crisp vinho verde works well."})
+	writeSession(t, ws, "cli:direct",
+		session.Message{Role: "user", Content: "Tell me about sardines"})
+
+	embedder := &countingEmbedder{HashEmbedder: NewHashEmbedder(512)}
+	idx := NewIndex(IndexOptions{Workspace: ws, Embedder: embedder, Sessions: true, AllSessions: true, SkipRecent: 50, MinScore: 0.1})
+	ctx := context.Background()
+
+	recalls, err := idx.Search(ctx, "does the user have peanuts allergies", 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recalls) == 0 || recalls[0].Kind != KindMemory || !strings.Contains(recalls[0].Text, "peanuts") {
+		t.Fatalf("expected the allergy entry first, got %+v", recalls)
+	}
+
+	recalls, _ = idx.Search(ctx, "sardines wine", 5, "cli:direct")
+	if len(recalls) == 0 || recalls[0].Kind != KindSession || recalls[0].Date != "2026-03-01" ||
+		!strings.Contains(recalls[0].Text, "Assistant: A crisp vinho verde") {
+		t.Fatalf("expected the earlier conversation, got %+v", recalls)
+	}
+	for _, r := range recalls {
+		if r.Source == "sessions/cli_direct.jsonl" {
+			t.Fatalf("expected the recent turns of the current session to be skipped, got %+v", r)
+		}
+	}
+	if _, err := os.Stat(idx.Path()); err != nil {
+		t.Fatalf("expected index on disk: %v", err)
+	}
+
+	// Unchanged sources are not embedded again, and a fresh index reuses the file
+	before := embedder.texts
+	reopened := NewIndex(IndexOptions{Workspace: ws, Embedder: embedder, Sessions: true})
+	if err := reopened.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if embedder.texts != before || reopened.Len() != idx.Len() {
+		t.Fatalf("expected nothing re-embedded, embedded %d more", embedder.texts-before)
+	}
+
+	// A new entry only embeds itself; forgotten entries leave the index
+	_, _ = store.Save("Prefers tea over coffee", nil)
+	allergy, _ := store.Entries()
+	if _, err := store.Forget(allergy[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	before = embedder.texts
+	recalls, _ = idx.Search(ctx, "peanuts allergy", 5, "")
+	if embedder.texts-before != 2 {
+		t.Fatalf("expected the new entry and the query to be embedded, got %d", embedder.texts-before)
+	}
+	for _, r := range recalls {
+		if strings.Contains(r.Text, "peanuts") {
+			t.Fatalf("expected forgotten entry to be gone, got %+v", r)
+		}
+	}
+
+	count := idx.Len()
+	before = embedder.texts
+	if err := idx.Rebuild(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if idx.Len() != count || embedder.texts-before != count {
+		t.Fatalf("expected rebuild to embed all %d items, embedded %d", count, embedder.texts-before)
+	}
+}
+
+func TestIndex_SessionRecallStaysInChat(t *testing.T) {
+	ws := t.TempDir()
+	writeSession(t, ws, "telegram:alice",
+		session.Message{Role: "user", Content: "My bank PIN hint is sardines"},
+		session.Message{Role: "assistant", Content: "Noted."})
+	writeSession(t, ws, "telegram:bob",
+		session.Message{Role: "user", Content: "We talked about sardines and wine last week"},
+		session.Message{Role: "assistant", Content: "Yes, vinho verde."},
+		session.Message{Role: "user", Content: "Thanks"},
+		session.Message{Role: "assistant", Content: "Anytime."})
+	ctx := context.Background()
+
+	idx := NewIndex(IndexOptions{Workspace: ws, Embedder: NewHashEmbedder(512), Sessions: true, SkipRecent: 2})
+	recalls, err := idx.Search(ctx, "sardines", 5, "telegram:bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recalls) != 1 || !strings.Contains(recalls[0].Text, "vinho verde") {
+		t.Fatalf("expected only bob's earlier turn, got %+v", recalls)
+	}
+	recalls, _ = idx.Search(ctx, "sardines", 5, "cli:direct")
+	if len(recalls) != 0 {
+		t.Fatalf("expected no turns from other chats, got %+v", recalls)
+	}
+
+	shared := NewIndex(IndexOptions{Workspace: ws, Embedder: NewHashEmbedder(512), Sessions: true, AllSessions: true, SkipRecent: 2})
+	recalls, _ = shared.Search(ctx, "sardines PIN", 5, "telegram:bob")
+	found := false
+	for _, r := range recalls {
+		found = found || strings.Contains(r.Text, "PIN")
+	}
+	if !found {
+		t.Fatalf("expected other chats to be recalled with AllSessions, got %+v", recalls)
+	}
+}
+
+func TestIndex_ReadsSessionStore(t *testing.T) {
+	ws := t.TempDir()
+	store, err := session.OpenStore(session.StoreSQLite, ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	err = store.Append(ctx, "telegram:1", []*session.Message{
+		{Role: "user", Content: "Which wine goes with grilled sardines?", Timestamp: at},
+		{Role: "assistant", Content: "A crisp vinho verde works well.", Timestamp: at},
+		{Role: "user", Content: "Thanks", Timestamp: at},
+		{Role: "assistant", Content: "Anytime.", Timestamp: at},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	embedder := &countingEmbedder{HashEmbedder: NewHashEmbedder(512)}
+	idx := NewIndex(IndexOptions{Workspace: ws, Embedder: embedder, Sessions: true, SessionStore: store, SkipRecent: 2})
+	recalls, err := idx.Search(ctx, "sardines wine", 5, "telegram:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recalls) != 1 || recalls[0].Date != "2026-03-01" || !strings.Contains(recalls[0].Text, "vinho verde") {
+		t.Fatalf("expected the earlier turn from the database, got %+v", recalls)
+	}
+
+	// Unchanged sessions are not embedded again; new messages are picked up
+	before := embedder.texts
+	if err := idx.Sync(ctx); err != nil || embedder.texts != before {
+		t.Fatalf("expected nothing re-embedded, embedded %d more (%v)", embedder.texts-before, err)
+	}
+	err = store.Append(ctx, "telegram:1", []*session.Message{
+		{Role: "user", Content: "And with octopus?", Timestamp: at.Add(time.Hour)},
+		{Role: "assistant", Content: "Try an albarino.", Timestamp: at.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if idx.Len() != 3 {
+		t.Fatalf("expected three indexed turns, got %d", idx.Len())
+	}
+}
+
+func TestIndex_ModelChangeRebuilds(t *testing.T) {
+	ws := t.TempDir()
+	_, _ = NewStore(filepath.Join(ws, "memory")).Save("Lives in Porto", nil)
+	ctx := context.Background()
+
+	if err := NewIndex(IndexOptions{Workspace: ws, Embedder: NewHashEmbedder(32)}).Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	embedder := &countingEmbedder{HashEmbedder: NewHashEmbedder(64)}
+	recalls, err := NewIndex(IndexOptions{Workspace: ws, Embedder: embedder}).Search(ctx, "porto", 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if embedder.texts != 2 || len(recalls) != 1 || len(recalls[0].Text) == 0 {
+		t.Fatalf("expected vectors from the old model to be replaced, embedded %d, got %+v", embedder.texts, recalls)
+	}
+}
+
+func TestIndex_AppendsChangesToFile(t *testing.T) {
+	ws := t.TempDir()
+	_, _ = NewStore(filepath.Join(ws, "memory")).Save("Lives in Porto", nil)
+	first := session.Message{Role: "user", Content: "Plan a trip to Lisbon"}
+	reply := session.Message{Role: "assistant", Content: "Take the train along the coast."}
+	writeSession(t, ws, "telegram:1", first, reply)
+	ctx := context.Background()
+
+	idx := NewIndex(IndexOptions{Workspace: ws, Embedder: NewHashEmbedder(64), Sessions: true, SkipRecent: 2})
+	if err := idx.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(idx.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new turn is appended without rewriting what is already on disk
+	writeSession(t, ws, "telegram:1", first, reply,
+		session.Message{Role: "user", Content: "What about Sintra?"},
+		session.Message{Role: "assistant", Content: "Sintra is a short ride away."})
+	if _, err := idx.Search(ctx, "lisbon", 5, ""); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.ReadFile(idx.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(after), string(before)) || len(after) == len(before) {
+		t.Fatalf("expected the change to be appended, got %q", after[len(before):])
+	}
+	if strings.Contains(string(after[len(before):]), "Lisbon") {
+		t.Fatalf("expected only the new turn to be written, got %q", after[len(before):])
+	}
+
+	// A fresh index replays the file, and the older turn moved out of the recent messages
+	embedder := &countingEmbedder{HashEmbedder: NewHashEmbedder(64)}
+	reopened := NewIndex(IndexOptions{Workspace: ws, Embedder: embedder, Sessions: true, SkipRecent: 2})
+	recalls, err := reopened.Search(ctx, "trip to lisbon", 5, "telegram:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if embedder.texts != 1 || reopened.Len() != 3 {
+		t.Fatalf("expected only the query embedded and 3 items, embedded %d, got %d items", embedder.texts, reopened.Len())
+	}
+	if len(recalls) == 0 || !strings.Contains(recalls[0].Text, "Lisbon") {
+		t.Fatalf("expected the first turn to be recalled, got %+v", recalls)
+	}
+	for _, r := range recalls {
+		if strings.Contains(r.Text, "Sintra") {
+			t.Fatalf("expected the recent turn to be skipped, got %+v", r)
+		}
+	}
+
+	// A record cut short is dropped and the file is written whole again
+	f, err := os.OpenFile(idx.Path(), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"source":"memory/MEMORY.md","stamp":`)
+	f.Close()
+	embedder.texts = 0
+	torn := NewIndex(IndexOptions{Workspace: ws, Embedder: embedder, Sessions: true})
+	if err := torn.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if embedder.texts != 0 || torn.Len() != 3 {
+		t.Fatalf("expected the index to survive a torn record, embedded %d, got %d items", embedder.texts, torn.Len())
+	}
+	data, _ := os.ReadFile(idx.Path())
+	if strings.Count(string(data), "\n") != 1 {
+		t.Fatalf("expected the file to be rewritten as one snapshot, got %q", data)
+	}
+}
@@ -0,0 +1,407 @@
+// Package memory manages the assistant's long-term memory in the workspace:
+// dated, tagged entries in memory/MEMORY.md and a daily notes file per day,
+// plus an optional embeddings index for recalling them by meaning.
+package memory
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FileName is the long-term memory file read into the system prompt
+	FileName   = "MEMORY.md"
+	dateLayout = "2006-01-02"
+	header     = "# Long-term Memory\n"
+)
+
+// entryHeader matches "## [id] 2006-01-02 #tag #tag"
+var entryHeader = regexp.MustCompile(`^## \[([0-9a-z-]+)\] (\d{4}-\d{2}-\d{2})((?: #[^\s#]+)*)\s*$`)
+
+var dailyName = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\.md$`)
+
+// Entry is one remembered fact
+type Entry struct {
+	ID      string   `json:"id"`
+	Date    string   `json:"date"`
+	Tags    []string `json:"tags,omitempty"`
+	Content string   `json:"content"`
+}
+
+// Hit is a search result from MEMORY.md or a daily notes file
+type Hit struct {
+	Source  string   `json:"source"`
+	ID      string   `json:"id,omitempty"`
+	Date    string   `json:"date"`
+	Tags    []string `json:"tags,omitempty"`
+	Content string   `json:"content"`
+	Score   int      `json:"score"`
+}
+
+// Store reads and writes the memory directory
+type Store struct {
+	dir string
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewStore creates a store for dir, normally <workspace>/memory
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, now: time.Now}
+}
+
+// Dir returns the memory directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// block is either a structured entry or text kept verbatim
+type block struct {
+	entry *Entry
+	raw   string
+}
+
+func (s *Store) path() string {
+	return filepath.Join(s.dir, FileName)
+}
+
+func (s *Store) load() ([]block, error) {
+	data, err := os.ReadFile(s.path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parse(string(data)), nil
+}
+
+func parse(text string) []block {
+	var blocks []block
+	var raw, body []string
+	var current *Entry
+	flush := func() {
+		if current != nil {
+			current.Content = strings.TrimSpace(strings.Join(body, "\n"))
+			blocks = append(blocks, block{entry: current})
+			current, body = nil, nil
+		} else if len(raw) > 0 {
+			blocks = append(blocks, block{raw: strings.Join(raw, "\n")})
+			raw = nil
+		}
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if m := entryHeader.FindStringSubmatch(line); m != nil {
+			flush()
+			current = &Entry{ID: m[1], Date: m[2], Tags: parseTags(m[3])}
+			continue
+		}
+		if current != nil && strings.HasPrefix(line, "## ") {
+			// A hand-written section ends the entry
+			flush()
+		}
+		if current != nil {
+			body = append(body, line)
+		} else {
+			raw = append(raw, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+func parseTags(s string) []string {
+	var tags []string
+	for _, f := range strings.Fields(s) {
+		tags = append(tags, strings.TrimPrefix(f, "#"))
+	}
+	return tags
+}
+
+func render(blocks []block) string {
+	var b strings.Builder
+	for _, bl := range blocks {
+		if bl.entry == nil {
+			text := strings.TrimRight(bl.raw, "\n")
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			b.WriteString(text)
+			b.WriteString("\n\n")
+			continue
+		}
+		e := bl.entry
+		fmt.Fprintf(&b, "## [%s] %s", e.ID, e.Date)
+		for _, t := range e.Tags {
+			b.WriteString(" #" + t)
+		}
+		b.WriteString("\n" + e.Content + "\n\n")
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// Entries returns the structured entries of MEMORY.md
+func (s *Store) Entries() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blocks, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, b := range blocks {
+		if b.entry != nil {
+			entries = append(entries, *b.entry)
+		}
+	}
+	return entries, nil
+}
+
+// Save appends an entry to MEMORY.md
+func (s *Store) Save(content string, tags []string) (Entry, error) {
+	content = cleanContent(content)
+	if content == "" {
+		return Entry{}, fmt.Errorf("memory content is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blocks, err := s.load()
+	if err != nil {
+		return Entry{}, err
+	}
+	if len(blocks) == 0 {
+		blocks = append(blocks, block{raw: header})
+	}
+	entry := Entry{
+		ID:      newID(),
+		Date:    s.now().Format(dateLayout),
+		Tags:    normalizeTags(tags),
+		Content: content,
+	}
+	blocks = append(blocks, block{entry: &entry})
+	return entry, s.write(FileName, render(blocks))
+}
+
+// Forget removes the entry with the given id from MEMORY.md
+func (s *Store) Forget(id string) (Entry, error) {
+	id = strings.TrimSpace(strings.Trim(id, "[]"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blocks, err := s.load()
+	if err != nil {
+		return Entry{}, err
+	}
+	for i, b := range blocks {
+		if b.entry != nil && b.entry.ID == id {
+			removed := *b.entry
+			blocks = append(blocks[:i], blocks[i+1:]...)
+			return removed, s.write(FileName, render(blocks))
+		}
+	}
+	return Entry{}, fmt.Errorf("no memory with id %q", id)
+}
+
+// AddNote appends a timestamped line to today's daily notes file and
+// returns the file name
+func (s *Store) AddNote(content string, tags []string) (string, error) {
+	content = strings.Join(strings.Fields(content), " ")
+	if content == "" {
+		return "", fmt.Errorf("note is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	name := now.Format(dateLayout) + ".md"
+	path := filepath.Join(s.dir, name)
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	text := string(existing)
+	if text == "" {
+		text = "# " + now.Format(dateLayout) + "\n\n"
+	} else if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	line := "- " + now.Format("15:04") + " " + content
+	for _, t := range normalizeTags(tags) {
+		line += " #" + t
+	}
+	return name, s.write(name, text+line+"\n")
+}
+
+// RecentNotes returns the content of the daily notes for today and the
+// previous days, newest first
+func (s *Store) RecentNotes(days int) []string {
+	now := s.now()
+	var notes []string
+	for i := 0; i < days; i++ {
+		name := now.AddDate(0, 0, -i).Format(dateLayout) + ".md"
+		if data, err := os.ReadFile(filepath.Join(s.dir, name)); err == nil {
+			if text := strings.TrimSpace(string(data)); text != "" {
+				notes = append(notes, text)
+			}
+		}
+	}
+	return notes
+}
+
+// Search finds entries and daily note lines containing the query terms.
+// Every tag in tags must be present. Results are ordered by the number of
+// matching terms, then newest first.
+func (s *Store) Search(query string, tags []string, limit int) ([]Hit, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	want := normalizeTags(tags)
+	if len(terms) == 0 && len(want) == 0 {
+		return nil, fmt.Errorf("query or tags required")
+	}
+
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var hits []Hit
+	for _, e := range entries {
+		if hit, ok := match(terms, want, e.Content, e.Tags); ok {
+			hit.Source, hit.ID, hit.Date = FileName, e.ID, e.Date
+			hits = append(hits, hit)
+		}
+	}
+
+	files, _ := os.ReadDir(s.dir)
+	for _, f := range files {
+		if f.IsDir() || !dailyName.MatchString(f.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		date := strings.TrimSuffix(f.Name(), ".md")
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			text, lineTags := splitTags(strings.TrimPrefix(line, "- "))
+			if hit, ok := match(terms, want, text, lineTags); ok {
+				hit.Source, hit.Date = f.Name(), date
+				hits = append(hits, hit)
+			}
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Date > hits[j].Date
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func match(terms, want []string, content string, tags []string) (Hit, bool) {
+	have := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		have[strings.ToLower(t)] = true
+	}
+	for _, t := range want {
+		if !have[t] {
+			return Hit{}, false
+		}
+	}
+	haystack := strings.ToLower(content + " " + strings.Join(tags, " "))
+	score := 0
+	for _, term := range terms {
+		if strings.Contains(haystack, term) {
+			score++
+		}
+	}
+	if len(terms) > 0 && score == 0 {
+		return Hit{}, false
+	}
+	return Hit{Tags: tags, Content: content, Score: score}, true
+}
+
+// splitTags separates trailing #tags from a daily note line
+func splitTags(line string) (string, []string) {
+	fields := strings.Fields(line)
+	end := len(fields)
+	for end > 0 && strings.HasPrefix(fields[end-1], "#") && len(fields[end-1]) > 1 {
+		end--
+	}
+	var tags []string
+	for _, f := range fields[end:] {
+		tags = append(tags, f[1:])
+	}
+	return strings.Join(fields[:end], " "), tags
+}
+
+func normalizeTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(t), "#")), "-"))
+		t = strings.ReplaceAll(t, "#", "")
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// cleanContent keeps entry text from being mistaken for an entry header
+func cleanContent(content string) string {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(content, "\r\n", "\n")), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func newID() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// write replaces a file in the memory directory atomically
+func (s *Store) write(name, content string) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, "."+name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
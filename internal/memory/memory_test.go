@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s := NewStore(filepath.Join(t.TempDir(), "memory"))
+	s.now = func() time.Time { return time.Date(2026, 3, 14, 9, 30, 0, 0, time.Local) }
+	return s
+}
+
+func TestStore_SaveSearchForget(t *testing.T) {
+	s := newTestStore(t)
+
+	coffee, err := s.Save("User drinks oat milk flat whites", []string{"Preferences", "#food", "food"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coffee.Date != "2026-03-14" || len(coffee.Tags) != 2 || coffee.Tags[0] != "preferences" {
+		t.Fatalf("unexpected entry %+v", coffee)
+	}
+	trip, _ := s.Save("Trip to Lisbon in May\n## not a header", []string{"travel"})
+
+	data, _ := os.ReadFile(filepath.Join(s.Dir(), FileName))
+	text := string(data)
+	if !strings.HasPrefix(text, "# Long-term Memory\n\n## ["+coffee.ID+"] 2026-03-14 #preferences #food\n") ||
+		!strings.Contains(text, "\n### not a header\n") {
+		t.Fatalf("unexpected file:\n%s", text)
+	}
+
+	hits, err := s.Search("MILK lisbon", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || hits[0].Score != 1 {
+		t.Fatalf("expected both entries to match one term, got %+v", hits)
+	}
+	hits, _ = s.Search("", []string{"travel"}, 0)
+	if len(hits) != 1 || hits[0].ID != trip.ID || hits[0].Source != FileName {
+		t.Fatalf("expected tag filter to find the trip, got %+v", hits)
+	}
+	if _, err := s.Search(" ", nil, 0); err == nil {
+		t.Fatal("expected empty search to fail")
+	}
+
+	removed, err := s.Forget("[" + coffee.ID + "]")
+	if err != nil || removed.Content != coffee.Content {
+		t.Fatalf("forget failed: %+v, %v", removed, err)
+	}
+	if _, err := s.Forget(coffee.ID); err == nil {
+		t.Fatal("expected second forget to fail")
+	}
+	entries, _ := s.Entries()
+	if len(entries) != 1 || entries[0].ID != trip.ID {
+		t.Fatalf("expected only the trip to remain, got %+v", entries)
+	}
+}
+
+func TestStore_KeepsHandWrittenSections(t *testing.T) {
+	s := newTestStore(t)
+	original := "# Memory\n\nThe user's name is Sam.\n\n## [abc123] 2026-01-02 #work\nWorks at Acme.\n\n## Projects\n- golem\n"
+	if err := os.MkdirAll(s.Dir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Dir(), FileName), []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := s.Entries()
+	if len(entries) != 1 || entries[0].Content != "Works at Acme." || entries[0].Tags[0] != "work" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if _, err := s.Forget("abc123"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(s.Dir(), FileName))
+	if string(data) != "# Memory\n\nThe user's name is Sam.\n\n## Projects\n- golem\n" {
+		t.Fatalf("hand-written text changed:\n%s", data)
+	}
+}
+
+func TestStore_DailyNotes(t *testing.T) {
+	s := newTestStore(t)
+	name, err := s.AddNote("Reviewed the   quarterly budget", []string{"finance"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "2026-03-14.md" {
+		t.Fatalf("unexpected note file %q", name)
+	}
+	_, _ = s.AddNote("Called the dentist", nil)
+
+	data, _ := os.ReadFile(filepath.Join(s.Dir(), name))
+	want := "# 2026-03-14\n\n- 09:30 Reviewed the quarterly budget #finance\n- 09:30 Called the dentist\n"
+	if string(data) != want {
+		t.Fatalf("expected %q, got %q", want, data)
+	}
+
+	hits, _ := s.Search("budget", []string{"finance"}, 0)
+	if len(hits) != 1 || hits[0].Source != name || hits[0].Content != "09:30 Reviewed the quarterly budget" {
+		t.Fatalf("unexpected note hits %+v", hits)
+	}
+
+	notes := s.RecentNotes(2)
+	if len(notes) != 1 || !strings.Contains(notes[0], "dentist") {
+		t.Fatalf("unexpected recent notes %v", notes)
+	}
+}
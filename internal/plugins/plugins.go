@@ -0,0 +1,123 @@
+// Package plugins reads executable tool plugins. A plugin is a folder holding
+// a plugin.json manifest that names and describes one tool, gives the JSON
+// schema of its arguments and says which executable implements it. The
+// executable receives the arguments as JSON on stdin and writes its result
+// as JSON to stdout.
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ManifestName is the manifest file every plugin folder must contain
+const ManifestName = "plugin.json"
+
+// validName matches the tool names model APIs accept
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Manifest declares a plugin tool. Command is run with Args; a relative path
+// such as ./run.py is resolved against the plugin folder, a bare name is
+// looked up on PATH.
+type Manifest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+	Command     string          `json:"command"`
+	Args        []string        `json:"args,omitempty"`
+}
+
+// Plugin is a manifest found on disk
+type Plugin struct {
+	Manifest
+	// Dir is the plugin folder
+	Dir string
+}
+
+// Executable returns the program to run
+func (p Plugin) Executable() string {
+	if filepath.IsAbs(p.Command) || !strings.ContainsAny(p.Command, `/\`) {
+		return p.Command
+	}
+	return filepath.Join(p.Dir, filepath.FromSlash(p.Command))
+}
+
+// Load reads the plugin in dir
+func Load(dir string) (Plugin, error) {
+	path := filepath.Join(dir, ManifestName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Plugin{}, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Plugin{}, fmt.Errorf("%s: %w", path, err)
+	}
+	m.Name = strings.TrimSpace(m.Name)
+	m.Description = strings.TrimSpace(m.Description)
+	m.Command = strings.TrimSpace(m.Command)
+	switch {
+	case !validName.MatchString(m.Name):
+		return Plugin{}, fmt.Errorf("%s: invalid name %q: use up to 64 letters, digits, '_' and '-'", path, m.Name)
+	case m.Description == "":
+		return Plugin{}, fmt.Errorf("%s: description is required", path)
+	case m.Command == "":
+		return Plugin{}, fmt.Errorf("%s: command is required", path)
+	}
+	if len(m.Parameters) > 0 {
+		var schema map[string]any
+		if err := json.Unmarshal(m.Parameters, &schema); err != nil {
+			return Plugin{}, fmt.Errorf("%s: parameters must be a JSON schema object: %w", path, err)
+		}
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Plugin{}, err
+	}
+	return Plugin{Manifest: m, Dir: abs}, nil
+}
+
+// Discover loads every plugin folder in dir, sorted by name. Folders without
+// a manifest are ignored; invalid manifests and duplicate names are returned
+// as errors alongside the plugins that loaded.
+func Discover(dir string) ([]Plugin, []error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+	var (
+		found []Plugin
+		errs  []error
+	)
+	seen := make(map[string]string)
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		pluginDir := filepath.Join(dir, e.Name())
+		p, err := Load(pluginDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := seen[p.Name]; ok {
+			errs = append(errs, fmt.Errorf("%s: plugin %q is already defined in %s", pluginDir, p.Name, other))
+			continue
+		}
+		seen[p.Name] = pluginDir
+		found = append(found, p)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found, errs
+}
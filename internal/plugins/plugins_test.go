@@ -0,0 +1,63 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeManifest(t *testing.T, dir, manifest string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, filepath.Join(dir, "weather"), `{
+		"name": "weather",
+		"description": "Current weather for a city",
+		"parameters": {"type": "object", "properties": {"city": {"type": "string"}}, "required": ["city"]},
+		"command": "./weather.sh",
+		"args": ["--metric"]
+	}`)
+	writeManifest(t, filepath.Join(dir, "jq"), `{"name": "jq_query", "description": "Query JSON", "command": "jq"}`)
+	writeManifest(t, filepath.Join(dir, "weather-copy"), `{"name": "weather", "description": "Again", "command": "true"}`)
+	writeManifest(t, filepath.Join(dir, "bad-name"), `{"name": "no spaces", "description": "x", "command": "true"}`)
+	writeManifest(t, filepath.Join(dir, "no-command"), `{"name": "idle", "description": "x"}`)
+	writeManifest(t, filepath.Join(dir, "bad-schema"), `{"name": "odd", "description": "x", "command": "true", "parameters": [1]}`)
+	if err := os.MkdirAll(filepath.Join(dir, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	found, errs := Discover(dir)
+	if len(found) != 2 || found[0].Name != "jq_query" || found[1].Name != "weather" {
+		t.Fatalf("unexpected plugins %+v", found)
+	}
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %v", errs)
+	}
+
+	weather := found[1]
+	if want := filepath.Join(dir, "weather", "weather.sh"); weather.Executable() != want {
+		t.Fatalf("expected %s, got %s", want, weather.Executable())
+	}
+	if !strings.Contains(string(weather.Parameters), `"city"`) || len(weather.Args) != 1 {
+		t.Fatalf("unexpected manifest %+v", weather.Manifest)
+	}
+	if found[0].Executable() != "jq" {
+		t.Fatalf("expected bare commands to be looked up on PATH, got %s", found[0].Executable())
+	}
+}
+
+func TestDiscover_MissingDir(t *testing.T) {
+	found, errs := Discover(filepath.Join(t.TempDir(), "none"))
+	if len(found) != 0 || len(errs) != 0 {
+		t.Fatalf("expected nothing, got %v %v", found, errs)
+	}
+}
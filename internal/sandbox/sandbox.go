@@ -0,0 +1,97 @@
+// Package sandbox runs shell commands in an isolated environment where the
+// workspace is the only writable location and network access is optional.
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Backend names
+const (
+	BackendAuto      = "auto"
+	BackendBwrap     = "bwrap"
+	BackendNamespace = "namespace"
+)
+
+// specEnv carries the init spec to the re-executed helper process
+const specEnv = "GOLEM_SANDBOX_SPEC"
+
+// Limits are resource limits applied to the sandboxed process tree.
+// Zero means unlimited.
+type Limits struct {
+	MemoryBytes   uint64
+	CPUSeconds    uint64
+	FileSizeBytes uint64
+	OpenFiles     uint64
+	Processes     uint64
+}
+
+// Options configure a sandbox
+type Options struct {
+	Backend   string
+	Workspace string
+	Network   bool
+	Limits    Limits
+}
+
+// Sandbox builds commands that run inside the isolation backend
+type Sandbox interface {
+	Name() string
+	Command(ctx context.Context, script, dir string) (*exec.Cmd, error)
+}
+
+// spec is handed to the helper process started in place of the shell
+type spec struct {
+	Script      string `json:"script"`
+	Dir         string `json:"dir"`
+	Workspace   string `json:"workspace"`
+	SetupMounts bool   `json:"setup_mounts"`
+	Limits      Limits `json:"limits"`
+}
+
+// New creates a sandbox for the given options
+func New(opts Options) (Sandbox, error) {
+	if opts.Workspace == "" {
+		return nil, fmt.Errorf("sandbox requires a workspace")
+	}
+	backend := strings.ToLower(strings.TrimSpace(opts.Backend))
+	if backend == "" {
+		backend = BackendAuto
+	}
+	return newBackend(backend, opts)
+}
+
+// Init must be called at the very start of main (and TestMain) of any binary
+// that runs sandboxed commands. In the re-executed helper it applies the
+// isolation and replaces itself with the shell; otherwise it returns.
+func Init() {
+	raw := os.Getenv(specEnv)
+	if raw == "" {
+		return
+	}
+	os.Unsetenv(specEnv)
+
+	var s spec
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		fatal(fmt.Errorf("invalid sandbox spec: %w", err))
+	}
+	fatal(runInit(&s))
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+	os.Exit(126)
+}
+
+func encodeSpec(s *spec) (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return specEnv + "=" + string(data), nil
+}
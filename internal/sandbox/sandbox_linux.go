@@ -0,0 +1,339 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func newBackend(backend string, opts Options) (Sandbox, error) {
+	workspace, err := filepath.Abs(opts.Workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(workspace); err == nil {
+		workspace = resolved
+	}
+	opts.Workspace = workspace
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	switch backend {
+	case BackendAuto:
+		if path, err := exec.LookPath("bwrap"); err == nil {
+			return &bwrapSandbox{opts: opts, exe: exe, bwrap: path}, nil
+		}
+		return &namespaceSandbox{opts: opts, exe: exe}, nil
+	case BackendBwrap:
+		path, err := exec.LookPath("bwrap")
+		if err != nil {
+			return nil, fmt.Errorf("bwrap backend requested but bwrap not found: %w", err)
+		}
+		return &bwrapSandbox{opts: opts, exe: exe, bwrap: path}, nil
+	case BackendNamespace:
+		return &namespaceSandbox{opts: opts, exe: exe}, nil
+	default:
+		return nil, fmt.Errorf("unknown sandbox backend: %s", backend)
+	}
+}
+
+// namespaceSandbox isolates with user, mount, pid, ipc, uts and (optionally)
+// network namespaces created directly by the Go runtime
+type namespaceSandbox struct {
+	opts Options
+	exe  string
+}
+
+func (n *namespaceSandbox) Name() string { return BackendNamespace }
+
+func (n *namespaceSandbox) Command(ctx context.Context, script, dir string) (*exec.Cmd, error) {
+	env, err := encodeSpec(&spec{
+		Script:      script,
+		Dir:         dir,
+		Workspace:   n.opts.Workspace,
+		SetupMounts: true,
+		Limits:      n.opts.Limits,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	flags := uintptr(unix.CLONE_NEWUSER | unix.CLONE_NEWNS | unix.CLONE_NEWPID | unix.CLONE_NEWIPC | unix.CLONE_NEWUTS)
+	if !n.opts.Network {
+		flags |= unix.CLONE_NEWNET
+	}
+
+	cmd := exec.CommandContext(ctx, n.exe)
+	cmd.Env = append(os.Environ(), env)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  flags,
+		Pdeathsig:   syscall.SIGKILL,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+	}
+	return cmd, nil
+}
+
+// bwrapSandbox delegates namespace and mount setup to bubblewrap
+type bwrapSandbox struct {
+	opts  Options
+	exe   string
+	bwrap string
+}
+
+func (b *bwrapSandbox) Name() string { return BackendBwrap }
+
+func (b *bwrapSandbox) Command(ctx context.Context, script, dir string) (*exec.Cmd, error) {
+	env, err := encodeSpec(&spec{
+		Script:    script,
+		Dir:       dir,
+		Workspace: b.opts.Workspace,
+		Limits:    b.opts.Limits,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"--die-with-parent",
+		"--new-session",
+		"--unshare-user", "--unshare-pid", "--unshare-ipc", "--unshare-uts", "--unshare-cgroup-try",
+	}
+	if !b.opts.Network {
+		args = append(args, "--unshare-net")
+	}
+	args = append(args,
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	)
+	for _, d := range socketDirs() {
+		args = append(args, "--tmpfs", d)
+	}
+	args = append(args,
+		"--bind", b.opts.Workspace, b.opts.Workspace,
+		"--chdir", dir,
+		"--", b.exe,
+	)
+
+	cmd := exec.CommandContext(ctx, b.bwrap, args...)
+	cmd.Env = append(os.Environ(), env)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: syscall.SIGKILL}
+	return cmd, nil
+}
+
+func runInit(s *spec) error {
+	runtime.LockOSThread()
+
+	if s.SetupMounts {
+		if err := setupMounts(s.Workspace); err != nil {
+			return fmt.Errorf("mount setup failed: %w", err)
+		}
+	}
+	if s.Dir != "" {
+		if err := os.Chdir(s.Dir); err != nil {
+			return fmt.Errorf("chdir failed: %w", err)
+		}
+	}
+	if err := applyLimits(s.Limits); err != nil {
+		return fmt.Errorf("rlimit failed: %w", err)
+	}
+	dropCapabilities()
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("no_new_privs failed: %w", err)
+	}
+	if err := installSeccomp(); err != nil {
+		return fmt.Errorf("seccomp failed: %w", err)
+	}
+
+	return syscall.Exec("/bin/sh", []string{"sh", "-c", s.Script}, os.Environ())
+}
+
+// setupMounts makes every mount read-only except a fresh /tmp and the
+// workspace, and hides the runtime socket directories. It runs as root of a
+// new user and mount namespace.
+func setupMounts(workspace string) error {
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make private: %w", err)
+	}
+
+	// Keep a handle on the workspace; it may live under /tmp, which gets replaced
+	wsFD, err := unix.Open(workspace, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("open workspace: %w", err)
+	}
+	defer unix.Close(wsFD)
+
+	mounts, err := readMounts()
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		if skipReadOnly(m.point) {
+			continue
+		}
+		flags := uintptr(unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY) | m.locked
+		if err := unix.Mount("", m.point, "", flags, ""); err != nil {
+			// A mount point that no longer exists cannot be reached to write
+			// to; anything else would stay writable, so refuse to run
+			if errors.Is(err, unix.ENOENT) {
+				continue
+			}
+			return fmt.Errorf("remount %s read-only: %w", m.point, err)
+		}
+	}
+
+	if err := unix.Mount("tmpfs", "/tmp", "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777"); err != nil {
+		return fmt.Errorf("mount /tmp: %w", err)
+	}
+	for _, d := range socketDirs() {
+		if err := unix.Mount("tmpfs", d, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=0755"); err != nil {
+			return fmt.Errorf("mount %s: %w", d, err)
+		}
+	}
+	if err := os.MkdirAll(workspace, 0755); err != nil {
+		return fmt.Errorf("recreate workspace mountpoint: %w", err)
+	}
+	src := fmt.Sprintf("/proc/self/fd/%d", wsFD)
+	if err := unix.Mount(src, workspace, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind workspace: %w", err)
+	}
+	if err := unix.Mount("", workspace, "", unix.MS_BIND|unix.MS_REMOUNT, ""); err != nil {
+		return fmt.Errorf("remount workspace writable: %w", err)
+	}
+
+	// A proc instance for the new pid namespace; keep the inherited one if not permitted
+	_ = unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "")
+	return nil
+}
+
+// socketDirs returns the directories holding host sockets such as
+// docker.sock and the D-Bus system bus. A read-only mount still lets a
+// socket be connected to, so they are covered with an empty tmpfs. A
+// symlink such as /var/run -> /run is hidden through its target.
+func socketDirs() []string {
+	var dirs []string
+	for _, d := range []string{"/run", "/var/run"} {
+		if info, err := os.Lstat(d); err == nil && info.IsDir() {
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs
+}
+
+// skipReadOnly keeps /proc and the device nodes usable
+func skipReadOnly(point string) bool {
+	switch point {
+	case "/dev", "/dev/pts":
+		return true
+	}
+	return point == "/proc" || strings.HasPrefix(point, "/proc/")
+}
+
+type mountEntry struct {
+	point  string
+	locked uintptr
+}
+
+// readMounts lists mount points with the flags that must be preserved on remount
+func readMounts() ([]mountEntry, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []mountEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		var locked uintptr
+		for _, opt := range strings.Split(fields[5], ",") {
+			switch opt {
+			case "nosuid":
+				locked |= unix.MS_NOSUID
+			case "nodev":
+				locked |= unix.MS_NODEV
+			case "noexec":
+				locked |= unix.MS_NOEXEC
+			case "noatime":
+				locked |= unix.MS_NOATIME
+			case "nodiratime":
+				locked |= unix.MS_NODIRATIME
+			case "relatime":
+				locked |= unix.MS_RELATIME
+			}
+		}
+		mounts = append(mounts, mountEntry{point: unescapeMount(fields[4]), locked: locked})
+	}
+	return mounts, scanner.Err()
+}
+
+// unescapeMount decodes the octal escapes used in mountinfo
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func applyLimits(l Limits) error {
+	limits := []struct {
+		resource int
+		value    uint64
+	}{
+		{unix.RLIMIT_AS, l.MemoryBytes},
+		{unix.RLIMIT_CPU, l.CPUSeconds},
+		{unix.RLIMIT_FSIZE, l.FileSizeBytes},
+		{unix.RLIMIT_NOFILE, l.OpenFiles},
+		{unix.RLIMIT_NPROC, l.Processes},
+	}
+	for _, lim := range limits {
+		if lim.value == 0 {
+			continue
+		}
+		rl := &unix.Rlimit{Cur: lim.value, Max: lim.value}
+		if err := unix.Setrlimit(lim.resource, rl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropCapabilities empties the bounding set so the shell cannot regain
+// namespace capabilities on exec. Fails silently without CAP_SETPCAP.
+func dropCapabilities() {
+	for c := 0; c <= unix.CAP_LAST_CAP; c++ {
+		_ = unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0)
+	}
+}
@@ -0,0 +1,200 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestMain(m *testing.M) {
+	Init()
+	os.Exit(m.Run())
+}
+
+func newTestSandbox(t *testing.T, opts Options) Sandbox {
+	t.Helper()
+	if err := exec.Command("unshare", "-Ur", "true").Run(); err != nil {
+		t.Skipf("user namespaces unavailable: %v", err)
+	}
+	sb, err := New(opts)
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	return sb
+}
+
+func runSandboxed(t *testing.T, sb Sandbox, script, dir string) (string, error) {
+	t.Helper()
+	cmd, err := sb.Command(context.Background(), script, dir)
+	if err != nil {
+		t.Fatalf("Command error: %v", err)
+	}
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestNamespaceSandbox_WorkspaceIsOnlyWritableMount(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	sb := newTestSandbox(t, Options{Backend: BackendNamespace, Workspace: workspace})
+
+	out, err := runSandboxed(t, sb, "echo hi > inside.txt && pwd", workspace)
+	if err != nil {
+		t.Fatalf("write in workspace failed: %v: %s", err, out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(workspace, "inside.txt")); string(data) != "hi\n" {
+		t.Fatalf("expected file written through sandbox, got %q", data)
+	}
+
+	target := filepath.Join(outside, "escape.txt")
+	out, err = runSandboxed(t, sb, "echo pwned > "+target, workspace)
+	if err == nil {
+		t.Fatalf("expected write outside workspace to fail, got: %s", out)
+	}
+	if _, statErr := os.Stat(target); statErr == nil {
+		t.Fatal("SECURITY FAILURE: file created outside workspace")
+	}
+
+	if out, err := runSandboxed(t, sb, "echo tmp > /tmp/scratch && cat /tmp/scratch", workspace); err != nil || !strings.Contains(out, "tmp") {
+		t.Fatalf("expected private /tmp to be writable: %v: %s", err, out)
+	}
+}
+
+func TestNamespaceSandbox_HidesHostSockets(t *testing.T) {
+	sock := filepath.Join("/run", fmt.Sprintf("golem-sandbox-test-%d.sock", os.Getpid()))
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("cannot create a socket under /run: %v", err)
+	}
+	defer ln.Close()
+
+	workspace := t.TempDir()
+	sb := newTestSandbox(t, Options{Backend: BackendNamespace, Workspace: workspace})
+	out, err := runSandboxed(t, sb, "if [ -e "+sock+" ]; then echo visible; else echo hidden; fi; echo x > /run/probe", workspace)
+	if !strings.Contains(out, "hidden") {
+		t.Fatalf("expected %s to be hidden in the sandbox, got %v: %s", sock, err, out)
+	}
+	if _, err := os.Stat("/run/probe"); err == nil {
+		os.Remove("/run/probe")
+		t.Fatal("SECURITY FAILURE: sandbox wrote to the host /run")
+	}
+}
+
+func TestNamespaceSandbox_NetworkDisabledByDefault(t *testing.T) {
+	workspace := t.TempDir()
+	sb := newTestSandbox(t, Options{Backend: BackendNamespace, Workspace: workspace})
+
+	out, err := runSandboxed(t, sb, "cat /proc/net/dev", workspace)
+	if err != nil {
+		t.Fatalf("read net devices failed: %v: %s", err, out)
+	}
+	for _, line := range strings.Split(out, "\n")[2:] {
+		name := strings.TrimSpace(strings.SplitN(line, ":", 2)[0])
+		if name != "" && name != "lo" {
+			t.Fatalf("expected only loopback, found %q", name)
+		}
+	}
+}
+
+func TestNamespaceSandbox_SeccompAndLimits(t *testing.T) {
+	workspace := t.TempDir()
+	sb := newTestSandbox(t, Options{
+		Backend:   BackendNamespace,
+		Workspace: workspace,
+		Limits:    Limits{FileSizeBytes: 4096},
+	})
+
+	if out, err := runSandboxed(t, sb, "unshare -r true", workspace); err == nil {
+		t.Fatalf("expected unshare to be blocked, got: %s", out)
+	}
+
+	out, err := runSandboxed(t, sb, "head -c 100000 /dev/zero > big.bin", workspace)
+	if err == nil {
+		t.Fatalf("expected file size limit to stop the write, got: %s", out)
+	}
+	if info, statErr := os.Stat(filepath.Join(workspace, "big.bin")); statErr == nil && info.Size() > 4096 {
+		t.Fatalf("file exceeded limit: %d bytes", info.Size())
+	}
+}
+
+// runFilter evaluates the seccomp program for a syscall with its first argument
+func runFilter(t *testing.T, prog []unix.SockFilter, nr uint32, arg0 uint64) uint32 {
+	t.Helper()
+	arch, err := auditArch()
+	if err != nil {
+		t.Skip(err)
+	}
+	data := make([]byte, 64)
+	binary.LittleEndian.PutUint32(data[offsetNr:], nr)
+	binary.LittleEndian.PutUint32(data[offsetArch:], arch)
+	binary.LittleEndian.PutUint64(data[offsetArg0:], arg0)
+
+	var acc uint32
+	for pc := 0; pc < len(prog); pc++ {
+		ins := prog[pc]
+		switch ins.Code {
+		case bpfLoadWord:
+			acc = binary.LittleEndian.Uint32(data[ins.K:])
+		case bpfJumpEq, bpfJumpGe, bpfJumpSet:
+			taken := (ins.Code == bpfJumpEq && acc == ins.K) ||
+				(ins.Code == bpfJumpGe && acc >= ins.K) ||
+				(ins.Code == bpfJumpSet && acc&ins.K != 0)
+			if taken {
+				pc += int(ins.Jt)
+			} else {
+				pc += int(ins.Jf)
+			}
+		case bpfReturn:
+			return ins.K
+		default:
+			t.Fatalf("unexpected instruction %#x", ins.Code)
+		}
+	}
+	t.Fatal("filter did not return")
+	return 0
+}
+
+func TestSeccompFilter_BlocksNewUserNamespaces(t *testing.T) {
+	prog, err := buildFilter()
+	if err != nil {
+		t.Skip(err)
+	}
+	eperm := unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)
+	tests := []struct {
+		name string
+		nr   uint32
+		arg0 uint64
+		want uint32
+	}{
+		{"fork", unix.SYS_CLONE, uint64(unix.SIGCHLD), unix.SECCOMP_RET_ALLOW},
+		{"thread", unix.SYS_CLONE, unix.CLONE_VM | unix.CLONE_THREAD | unix.CLONE_SIGHAND, unix.SECCOMP_RET_ALLOW},
+		{"new user namespace", unix.SYS_CLONE, unix.CLONE_NEWUSER | uint64(unix.SIGCHLD), eperm},
+		{"clone3", unix.SYS_CLONE3, 0, unix.SECCOMP_RET_ERRNO | uint32(unix.ENOSYS)},
+		{"unshare", unix.SYS_UNSHARE, unix.CLONE_NEWUSER, eperm},
+		{"read", unix.SYS_READ, 0, unix.SECCOMP_RET_ALLOW},
+	}
+	for _, tc := range tests {
+		if got := runFilter(t, prog, tc.nr, tc.arg0); got != tc.want {
+			t.Errorf("%s: got %#x, want %#x", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNew_UnknownBackend(t *testing.T) {
+	if _, err := New(Options{Backend: "chroot", Workspace: t.TempDir()}); err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+	if _, err := New(Options{Backend: BackendNamespace}); err == nil {
+		t.Fatal("expected error without workspace")
+	}
+}
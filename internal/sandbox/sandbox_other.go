@@ -0,0 +1,13 @@
+//go:build !linux
+
+package sandbox
+
+import "fmt"
+
+func newBackend(backend string, opts Options) (Sandbox, error) {
+	return nil, fmt.Errorf("sandbox backend %q is only supported on linux", backend)
+}
+
+func runInit(s *spec) error {
+	return fmt.Errorf("sandbox is only supported on linux")
+}
@@ -0,0 +1,101 @@
+package session
+
+import (
+    "encoding/json"
+    "fmt"
+    "html/template"
+    "io"
+    "strings"
+    "time"
+)
+
+// Export formats
+const (
+    FormatMarkdown = "md"
+    FormatJSON     = "json"
+    FormatHTML     = "html"
+)
+
+// timeLayout is how exports show message times
+const timeLayout = "2006-01-02 15:04"
+
+// Transcript is a session with its messages, as exported
+type Transcript struct {
+    Session  Info       `json:"session"`
+    Messages []*Message `json:"messages"`
+}
+
+// Export writes the transcript in format (md, json or html)
+func Export(w io.Writer, t Transcript, format string) error {
+    switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+    case FormatMarkdown, "markdown":
+        return exportMarkdown(w, t)
+    case FormatJSON:
+        enc := json.NewEncoder(w)
+        enc.SetIndent("", "  ")
+        return enc.Encode(t)
+    case FormatHTML, "htm":
+        return htmlTranscript.Execute(w, t)
+    default:
+        return fmt.Errorf("unknown export format %q: use md, json or html", format)
+    }
+}
+
+// RoleLabel names a message role for people: User, Assistant, ...
+func RoleLabel(role string) string {
+    if role == "" {
+        return "Unknown"
+    }
+    return strings.ToUpper(role[:1]) + role[1:]
+}
+
+func formatTime(t time.Time) string {
+    if t.IsZero() {
+        return "unknown"
+    }
+    return t.Local().Format(timeLayout)
+}
+
+func exportMarkdown(w io.Writer, t Transcript) error {
+    var b strings.Builder
+    fmt.Fprintf(&b, "# Session %s\n\n", t.Session.Key)
+    fmt.Fprintf(&b, "- Channel: %s\n- Chat: %s\n- Messages: %d\n- Started: %s\n- Last activity: %s\n",
+        t.Session.Channel, t.Session.ChatID, t.Session.Messages, formatTime(t.Session.Created), formatTime(t.Session.Updated))
+    for _, msg := range t.Messages {
+        fmt.Fprintf(&b, "\n## %s · %s\n\n%s\n", RoleLabel(msg.Role), formatTime(msg.Timestamp), strings.TrimSpace(msg.Content))
+    }
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+var htmlTranscript = template.Must(template.New("transcript").Funcs(template.FuncMap{
+    "role": RoleLabel,
+    "time": formatTime,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Session {{.Session.Key}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+header p { color: #666; margin: 0.2rem 0; }
+.message { border-radius: 8px; padding: 0.75rem 1rem; margin: 1rem 0; }
+.user { background: #eef4ff; }
+.assistant { background: #f3f3f3; }
+.meta { font-size: 0.85rem; color: #666; margin-bottom: 0.4rem; }
+.content { white-space: pre-wrap; overflow-wrap: anywhere; }
+</style>
+</head>
+<body>
+<header>
+<h1>Session {{.Session.Key}}</h1>
+<p>Channel: {{.Session.Channel}} · Chat: {{.Session.ChatID}} · {{.Session.Messages}} messages</p>
+<p>Started {{time .Session.Created}} · Last activity {{time .Session.Updated}}</p>
+</header>
+{{range .Messages}}<div class="message {{.Role}}">
+<div class="meta"><strong>{{role .Role}}</strong> · {{time .Timestamp}}</div>
+<div class="content">{{.Content}}</div>
+</div>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,54 @@
+package session
+
+import (
+    "bytes"
+    "encoding/json"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestExport(t *testing.T) {
+    base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+    transcript := Transcript{
+        Session:  Info{Key: "telegram:42", Channel: "telegram", ChatID: "42", Messages: 2, Created: base, Updated: base.Add(time.Second)},
+        Messages: messages(base, "Is <script>alert(1)</script> safe?", "**No**, it is escaped."),
+    }
+
+    var md bytes.Buffer
+    if err := Export(&md, transcript, "md"); err != nil {
+        t.Fatal(err)
+    }
+    for _, want := range []string{"# Session telegram:42\n", "- Messages: 2\n", "\n## User · ", "\n## Assistant · ", "**No**, it is escaped."} {
+        if !strings.Contains(md.String(), want) {
+            t.Errorf("expected %q in markdown:\n%s", want, md.String())
+        }
+    }
+
+    var js bytes.Buffer
+    if err := Export(&js, transcript, ".json"); err != nil {
+        t.Fatal(err)
+    }
+    var decoded Transcript
+    if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+        t.Fatal(err)
+    }
+    if decoded.Session.Key != "telegram:42" || len(decoded.Messages) != 2 || !decoded.Messages[0].Timestamp.Equal(base) {
+        t.Fatalf("unexpected JSON export %+v", decoded)
+    }
+
+    var page bytes.Buffer
+    if err := Export(&page, transcript, "html"); err != nil {
+        t.Fatal(err)
+    }
+    if strings.Contains(page.String(), "<script>") || !strings.Contains(page.String(), "&lt;script&gt;") {
+        t.Fatalf("expected message content to be escaped:\n%s", page.String())
+    }
+    if !strings.Contains(page.String(), `<div class="message assistant">`) {
+        t.Fatalf("expected messages styled by role:\n%s", page.String())
+    }
+
+    if err := Export(&bytes.Buffer{}, transcript, "pdf"); err == nil {
+        t.Fatal("expected an unknown format to fail")
+    }
+}
@@ -0,0 +1,177 @@
+package session
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// maxLineBytes is the longest message line the JSONL store reads
+const maxLineBytes = 16 << 20
+
+// JSONLStore keeps each session in its own file of JSON lines. Appends are
+// written with a single O_APPEND write, so concurrent writers do not
+// interleave lines.
+type JSONLStore struct {
+    dir string
+    mu  sync.Mutex
+}
+
+// NewJSONLStore creates a store in dir
+func NewJSONLStore(dir string) *JSONLStore {
+    os.MkdirAll(dir, 0755)
+    return &JSONLStore{dir: dir}
+}
+
+func (s *JSONLStore) path(key string) string {
+    return filepath.Join(s.dir, FileName(key))
+}
+
+// Append adds messages to the session file
+func (s *JSONLStore) Append(ctx context.Context, key string, msgs []*Message) error {
+    if len(msgs) == 0 {
+        return nil
+    }
+    var buf bytes.Buffer
+    enc := json.NewEncoder(&buf)
+    for _, msg := range msgs {
+        if err := enc.Encode(msg); err != nil {
+            return err
+        }
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if err := os.MkdirAll(s.dir, 0755); err != nil {
+        return err
+    }
+    f, err := os.OpenFile(s.path(key), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+    if err != nil {
+        return err
+    }
+    if _, err := f.Write(buf.Bytes()); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
+
+// read returns all messages of a session
+func (s *JSONLStore) read(key string) ([]*Message, error) {
+    f, err := os.Open(s.path(key))
+    if os.IsNotExist(err) {
+        return nil, ErrNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    var msgs []*Message
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+    for scanner.Scan() {
+        var msg Message
+        if err := json.Unmarshal(scanner.Bytes(), &msg); err == nil {
+            msgs = append(msgs, &msg)
+        }
+    }
+    return msgs, scanner.Err()
+}
+
+// Messages returns a page of a session's messages
+func (s *JSONLStore) Messages(ctx context.Context, key string, offset, limit int) ([]*Message, error) {
+    msgs, err := s.read(key)
+    if err != nil {
+        return nil, err
+    }
+    return page(msgs, offset, limit), nil
+}
+
+// Tail returns the last n messages
+func (s *JSONLStore) Tail(ctx context.Context, key string, n int) ([]*Message, error) {
+    msgs, err := s.read(key)
+    if err != nil {
+        return nil, err
+    }
+    if n > 0 && len(msgs) > n {
+        msgs = msgs[len(msgs)-n:]
+    }
+    return msgs, nil
+}
+
+// Get summarizes a session
+func (s *JSONLStore) Get(ctx context.Context, key string) (Info, error) {
+    return s.info(key)
+}
+
+func (s *JSONLStore) info(key string) (Info, error) {
+    msgs, err := s.read(key)
+    if err != nil {
+        return Info{}, err
+    }
+    channel, chatID := SplitKey(key)
+    info := Info{Key: key, Channel: channel, ChatID: chatID, Messages: len(msgs)}
+    if len(msgs) > 0 {
+        info.Created = msgs[0].Timestamp
+        info.Updated = msgs[len(msgs)-1].Timestamp
+    }
+    if stat, err := os.Stat(s.path(key)); err == nil {
+        info.Size = stat.Size()
+        if info.Updated.IsZero() {
+            info.Updated = stat.ModTime()
+        }
+    }
+    return info, nil
+}
+
+// List reads every session file. File names do not keep the key exactly,
+// so it is rebuilt as channel:chat_id from the first underscore.
+func (s *JSONLStore) List(ctx context.Context, f Filter) ([]Info, error) {
+    entries, err := os.ReadDir(s.dir)
+    if os.IsNotExist(err) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    var infos []Info
+    for _, e := range entries {
+        name, ok := strings.CutSuffix(e.Name(), ".jsonl")
+        if e.IsDir() || !ok {
+            continue
+        }
+        key := strings.Replace(name, "_", ":", 1)
+        info, err := s.info(key)
+        if err != nil {
+            continue
+        }
+        if f.matches(info) {
+            infos = append(infos, info)
+        }
+    }
+    sort.SliceStable(infos, func(i, j int) bool { return infos[i].Updated.After(infos[j].Updated) })
+    return page(infos, f.Offset, f.Limit), nil
+}
+
+// Delete removes the session file
+func (s *JSONLStore) Delete(ctx context.Context, key string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    err := os.Remove(s.path(key))
+    if os.IsNotExist(err) {
+        return ErrNotFound
+    }
+    return err
+}
+
+// Close does nothing; files are closed after each operation
+func (s *JSONLStore) Close() error {
+    return nil
+}
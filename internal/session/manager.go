@@ -1,9 +1,10 @@
 package session
 
 import (
-    "bufio"
-    "encoding/json"
-    "os"
+    "container/list"
+    "context"
+    "errors"
+    "log/slog"
     "path/filepath"
     "strings"
     "sync"
@@ -17,11 +18,14 @@ type Message struct {
     Timestamp time.Time `json:"timestamp"`
 }
 
-// Session represents a conversation session
+// Session represents a conversation session. Messages holds the recent
+// part of the conversation; older messages stay in the store.
 type Session struct {
     Key      string
     Messages []*Message
     mu       sync.RWMutex
+    // saved counts the leading Messages already in the store
+    saved int
 }
 
 // AddMessage adds a message to the session
@@ -53,20 +57,34 @@ func (s *Session) GetHistory(limit int) []*Message {
     return result
 }
 
-// Manager manages sessions
+const (
+    // cacheSize is how many sessions a Manager keeps in memory
+    cacheSize = 128
+    // keepMessages is how many recent messages a cached session holds
+    keepMessages = 200
+)
+
+// Manager manages sessions. It keeps the recently used ones in memory and
+// appends new messages to the store on Save.
 type Manager struct {
-    dir      string
-    sessions map[string]*Session
-    mu       sync.RWMutex
+    store    Store
+    sessions map[string]*list.Element
+    recent   *list.List
+    mu       sync.Mutex
 }
 
-// NewManager creates a session manager
+// NewManager creates a session manager backed by JSONL files in
+// <baseDir>/sessions
 func NewManager(baseDir string) *Manager {
-    dir := filepath.Join(baseDir, "sessions")
-    os.MkdirAll(dir, 0755)
+    return NewManagerWithStore(NewJSONLStore(filepath.Join(baseDir, "sessions")))
+}
+
+// NewManagerWithStore creates a session manager backed by store
+func NewManagerWithStore(store Store) *Manager {
     return &Manager{
-        dir:      dir,
-        sessions: make(map[string]*Session),
+        store:    store,
+        sessions: make(map[string]*list.Element),
+        recent:   list.New(),
     }
 }
 
@@ -75,59 +93,98 @@ func (m *Manager) GetOrCreate(key string) *Session {
     m.mu.Lock()
     defer m.mu.Unlock()
 
-    if sess, ok := m.sessions[key]; ok {
-        return sess
+    if el, ok := m.sessions[key]; ok {
+        m.recent.MoveToFront(el)
+        return el.Value.(*Session)
     }
 
     sess := &Session{Key: key}
-    m.loadFromDisk(sess)
-    m.sessions[key] = sess
+    msgs, err := m.store.Tail(context.Background(), key, keepMessages)
+    if err != nil && !errors.Is(err, ErrNotFound) {
+        slog.Warn("load session failed", "session", key, "error", err)
+    }
+    sess.Messages, sess.saved = msgs, len(msgs)
+
+    m.sessions[key] = m.recent.PushFront(sess)
+    if m.recent.Len() > cacheSize {
+        oldest := m.recent.Back()
+        m.recent.Remove(oldest)
+        delete(m.sessions, oldest.Value.(*Session).Key)
+    }
     return sess
 }
 
-// Save persists session to disk
+// Save appends the session's new messages to the store
 func (m *Manager) Save(sess *Session) error {
-    sess.mu.RLock()
-    defer sess.mu.RUnlock()
+    sess.mu.Lock()
+    defer sess.mu.Unlock()
 
-    if len(sess.Messages) == 0 {
-        return nil
-    }
-
-    path := m.sessionPath(sess.Key)
-    f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-    if err != nil {
-        return err
-    }
-    defer f.Close()
-
-    enc := json.NewEncoder(f)
-    for _, msg := range sess.Messages {
-        if err := enc.Encode(msg); err != nil {
+    if sess.saved < len(sess.Messages) {
+        if err := m.store.Append(context.Background(), sess.Key, sess.Messages[sess.saved:]); err != nil {
             return err
         }
+        sess.saved = len(sess.Messages)
+    }
+    if drop := len(sess.Messages) - keepMessages; drop > 0 {
+        sess.Messages = append([]*Message(nil), sess.Messages[drop:]...)
+        sess.saved -= drop
     }
     return nil
 }
 
-func (m *Manager) loadFromDisk(sess *Session) {
-    path := m.sessionPath(sess.Key)
-    f, err := os.Open(path)
-    if err != nil {
-        return
+// List returns stored sessions matching f, most recently updated first
+func (m *Manager) List(ctx context.Context, f Filter) ([]Info, error) {
+    return m.store.List(ctx, f)
+}
+
+// Get summarizes a stored session
+func (m *Manager) Get(ctx context.Context, key string) (Info, error) {
+    return m.store.Get(ctx, key)
+}
+
+// Messages returns a page of a stored session's messages, oldest first
+func (m *Manager) Messages(ctx context.Context, key string, offset, limit int) ([]*Message, error) {
+    return m.store.Messages(ctx, key, offset, limit)
+}
+
+// Tail returns the last n stored messages of a session, oldest first
+func (m *Manager) Tail(ctx context.Context, key string, n int) ([]*Message, error) {
+    return m.store.Tail(ctx, key, n)
+}
+
+// Delete removes a session from the store and from memory
+func (m *Manager) Delete(ctx context.Context, key string) error {
+    m.mu.Lock()
+    if el, ok := m.sessions[key]; ok {
+        m.recent.Remove(el)
+        delete(m.sessions, key)
     }
-    defer f.Close()
+    m.mu.Unlock()
+    return m.store.Delete(ctx, key)
+}
 
-    scanner := bufio.NewScanner(f)
-    for scanner.Scan() {
-        var msg Message
-        if err := json.Unmarshal(scanner.Bytes(), &msg); err == nil {
-            sess.Messages = append(sess.Messages, &msg)
+// Prune deletes the sessions last updated before cutoff and returns them
+func (m *Manager) Prune(ctx context.Context, cutoff time.Time) ([]Info, error) {
+    old, err := m.store.List(ctx, Filter{UpdatedBefore: cutoff})
+    if err != nil {
+        return nil, err
+    }
+    pruned := old[:0]
+    for _, info := range old {
+        if err := m.Delete(ctx, info.Key); err != nil && !errors.Is(err, ErrNotFound) {
+            return pruned, err
         }
+        pruned = append(pruned, info)
     }
+    return pruned, nil
+}
+
+// Close closes the store
+func (m *Manager) Close() error {
+    return m.store.Close()
 }
 
-func (m *Manager) sessionPath(key string) string {
-    safeKey := strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(key)
-    return filepath.Join(m.dir, safeKey+".jsonl")
+// FileName returns the file name a session is stored under in the sessions directory
+func FileName(key string) string {
+    return strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(key) + ".jsonl"
 }
@@ -1,6 +1,12 @@
 package session
 
-import "testing"
+import (
+    "context"
+    "fmt"
+    "path/filepath"
+    "testing"
+    "time"
+)
 
 func TestSession_AddMessage(t *testing.T) {
     sess := &Session{Key: "test"}
@@ -26,3 +32,91 @@ func TestManager_GetOrCreate(t *testing.T) {
         t.Error("expected same session instance")
     }
 }
+
+func TestManager_SaveAppendsNewMessages(t *testing.T) {
+    store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "sessions.db"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    mgr := NewManagerWithStore(store)
+    defer mgr.Close()
+
+    sess := mgr.GetOrCreate("cli:direct")
+    sess.AddMessage("user", "hello")
+    sess.AddMessage("assistant", "hi")
+    if err := mgr.Save(sess); err != nil {
+        t.Fatal(err)
+    }
+    sess.AddMessage("user", "again")
+    if err := mgr.Save(sess); err != nil {
+        t.Fatal(err)
+    }
+    if err := mgr.Save(sess); err != nil {
+        t.Fatal(err)
+    }
+
+    info, err := store.Get(context.Background(), "cli:direct")
+    if err != nil || info.Messages != 3 {
+        t.Fatalf("expected 3 stored messages, got %+v %v", info, err)
+    }
+    reloaded := NewManagerWithStore(store).GetOrCreate("cli:direct")
+    if history := reloaded.GetHistory(0); len(history) != 3 || history[2].Content != "again" {
+        t.Fatalf("unexpected history %+v", history)
+    }
+}
+
+func TestManager_KeepsRecentSessionsAndMessages(t *testing.T) {
+    mgr := NewManager(t.TempDir())
+
+    first := mgr.GetOrCreate("telegram:0")
+    for i := 0; i < keepMessages+10; i++ {
+        first.AddMessage("user", fmt.Sprint(i))
+    }
+    if err := mgr.Save(first); err != nil {
+        t.Fatal(err)
+    }
+    if len(first.Messages) != keepMessages || first.Messages[0].Content != "10" {
+        t.Fatalf("expected the last %d messages to stay in memory, got %d", keepMessages, len(first.Messages))
+    }
+
+    for i := 1; i <= cacheSize; i++ {
+        mgr.GetOrCreate(fmt.Sprintf("telegram:%d", i))
+    }
+    again := mgr.GetOrCreate("telegram:0")
+    if again == first {
+        t.Fatal("expected the least recently used session to be evicted")
+    }
+    if len(again.Messages) != keepMessages || again.Messages[keepMessages-1].Content != fmt.Sprint(keepMessages+9) {
+        t.Fatalf("expected the session to be reloaded from the store, got %d messages", len(again.Messages))
+    }
+}
+
+func TestManager_DeleteAndPrune(t *testing.T) {
+    ctx := context.Background()
+    mgr := NewManager(t.TempDir())
+    now := time.Now()
+    for key, age := range map[string]time.Duration{"telegram:old": 40 * 24 * time.Hour, "telegram:new": time.Hour, "cli:old": 31 * 24 * time.Hour} {
+        if err := mgr.store.Append(ctx, key, messages(now.Add(-age), "q", "a")); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    cached := mgr.GetOrCreate("telegram:new")
+    if err := mgr.Delete(ctx, "telegram:new"); err != nil {
+        t.Fatal(err)
+    }
+    if again := mgr.GetOrCreate("telegram:new"); again == cached || len(again.Messages) != 0 {
+        t.Fatal("expected a deleted session to be dropped from memory")
+    }
+
+    pruned, err := mgr.Prune(ctx, now.Add(-30*24*time.Hour))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(pruned) != 2 || pruned[0].Key != "cli:old" || pruned[1].Key != "telegram:old" {
+        t.Fatalf("unexpected pruned sessions %+v", pruned)
+    }
+    if left, _ := mgr.List(ctx, Filter{}); len(left) != 0 {
+        t.Fatalf("expected no sessions left, got %+v", left)
+    }
+}
@@ -0,0 +1,254 @@
+package session
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    _ "modernc.org/sqlite"
+)
+
+// sqliteSchema indexes sessions by channel, chat and last update, and
+// messages by session and time
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+    key        TEXT PRIMARY KEY,
+    channel    TEXT NOT NULL,
+    chat_id    TEXT NOT NULL,
+    messages   INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_channel_chat ON sessions (channel, chat_id);
+CREATE INDEX IF NOT EXISTS sessions_updated ON sessions (updated_at);
+CREATE TABLE IF NOT EXISTS messages (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_key TEXT NOT NULL,
+    role        TEXT NOT NULL,
+    content     TEXT NOT NULL,
+    created_at  INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS messages_session ON messages (session_key, id);
+CREATE INDEX IF NOT EXISTS messages_created ON messages (created_at);
+`
+
+// SQLiteStore keeps sessions in a SQLite database. It uses WAL mode and
+// takes the write lock when a write transaction begins, so several
+// goroutines and processes can share the file; writers wait up to
+// busyTimeout for each other.
+type SQLiteStore struct {
+    db *sql.DB
+}
+
+const busyTimeout = 10 * time.Second
+
+// NewSQLiteStore opens or creates the database at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return nil, err
+    }
+    dsn := fmt.Sprintf("%s?_txlock=immediate&_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)",
+        filepath.ToSlash(path), busyTimeout.Milliseconds())
+    db, err := sql.Open("sqlite", dsn)
+    if err != nil {
+        return nil, err
+    }
+    if _, err := db.Exec(sqliteSchema); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("open session database %s: %w", path, err)
+    }
+    return &SQLiteStore{db: db}, nil
+}
+
+// Append inserts messages and updates the session row in one transaction
+func (s *SQLiteStore) Append(ctx context.Context, key string, msgs []*Message) error {
+    if len(msgs) == 0 {
+        return nil
+    }
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    stmt, err := tx.PrepareContext(ctx, `INSERT INTO messages (session_key, role, content, created_at) VALUES (?, ?, ?, ?)`)
+    if err != nil {
+        return err
+    }
+    defer stmt.Close()
+    first, last := msgs[0].Timestamp, msgs[0].Timestamp
+    for _, msg := range msgs {
+        if _, err := stmt.ExecContext(ctx, key, msg.Role, msg.Content, msg.Timestamp.UnixNano()); err != nil {
+            return err
+        }
+        if msg.Timestamp.Before(first) {
+            first = msg.Timestamp
+        }
+        if msg.Timestamp.After(last) {
+            last = msg.Timestamp
+        }
+    }
+
+    channel, chatID := SplitKey(key)
+    _, err = tx.ExecContext(ctx, `
+        INSERT INTO sessions (key, channel, chat_id, messages, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+        ON CONFLICT (key) DO UPDATE SET
+            messages = messages + excluded.messages,
+            updated_at = max(updated_at, excluded.updated_at)`,
+        key, channel, chatID, len(msgs), first.UnixNano(), last.UnixNano())
+    if err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (s *SQLiteStore) queryMessages(ctx context.Context, key, query string, args ...any) ([]*Message, error) {
+    rows, err := s.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    var msgs []*Message
+    for rows.Next() {
+        var (
+            msg Message
+            ts  int64
+        )
+        if err := rows.Scan(&msg.Role, &msg.Content, &ts); err != nil {
+            return nil, err
+        }
+        msg.Timestamp = time.Unix(0, ts)
+        msgs = append(msgs, &msg)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    if len(msgs) == 0 {
+        if _, err := s.Get(ctx, key); err != nil {
+            return nil, err
+        }
+    }
+    return msgs, nil
+}
+
+// Messages returns a page of a session's messages
+func (s *SQLiteStore) Messages(ctx context.Context, key string, offset, limit int) ([]*Message, error) {
+    if limit <= 0 {
+        limit = -1
+    }
+    return s.queryMessages(ctx, key,
+        `SELECT role, content, created_at FROM messages WHERE session_key = ? ORDER BY id LIMIT ? OFFSET ?`,
+        key, limit, max(offset, 0))
+}
+
+// Tail returns the last n messages
+func (s *SQLiteStore) Tail(ctx context.Context, key string, n int) ([]*Message, error) {
+    if n <= 0 {
+        return s.Messages(ctx, key, 0, 0)
+    }
+    return s.queryMessages(ctx, key, `
+        SELECT role, content, created_at FROM (
+            SELECT id, role, content, created_at FROM messages WHERE session_key = ? ORDER BY id DESC LIMIT ?
+        ) ORDER BY id`,
+        key, n)
+}
+
+// Get summarizes a session
+func (s *SQLiteStore) Get(ctx context.Context, key string) (Info, error) {
+    infos, err := s.querySessions(ctx, `WHERE key = ?`, key)
+    if err != nil {
+        return Info{}, err
+    }
+    if len(infos) == 0 {
+        return Info{}, ErrNotFound
+    }
+    return infos[0], nil
+}
+
+// List returns matching sessions from the index
+func (s *SQLiteStore) List(ctx context.Context, f Filter) ([]Info, error) {
+    var (
+        where []string
+        args  []any
+    )
+    if f.Channel != "" {
+        where = append(where, "channel = ?")
+        args = append(args, f.Channel)
+    }
+    if f.ChatID != "" {
+        where = append(where, "chat_id = ?")
+        args = append(args, f.ChatID)
+    }
+    if !f.UpdatedAfter.IsZero() {
+        where = append(where, "updated_at > ?")
+        args = append(args, f.UpdatedAfter.UnixNano())
+    }
+    if !f.UpdatedBefore.IsZero() {
+        where = append(where, "updated_at < ?")
+        args = append(args, f.UpdatedBefore.UnixNano())
+    }
+    clause := ""
+    if len(where) > 0 {
+        clause = "WHERE " + strings.Join(where, " AND ")
+    }
+    limit := f.Limit
+    if limit <= 0 {
+        limit = -1
+    }
+    clause += " ORDER BY updated_at DESC, key LIMIT ? OFFSET ?"
+    args = append(args, limit, max(f.Offset, 0))
+    return s.querySessions(ctx, clause, args...)
+}
+
+func (s *SQLiteStore) querySessions(ctx context.Context, clause string, args ...any) ([]Info, error) {
+    rows, err := s.db.QueryContext(ctx, `
+        SELECT key, channel, chat_id, messages, created_at, updated_at,
+            (SELECT coalesce(sum(length(CAST(content AS BLOB))), 0) FROM messages WHERE session_key = sessions.key)
+        FROM sessions `+clause, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    var infos []Info
+    for rows.Next() {
+        var (
+            info             Info
+            created, updated int64
+        )
+        if err := rows.Scan(&info.Key, &info.Channel, &info.ChatID, &info.Messages, &created, &updated, &info.Size); err != nil {
+            return nil, err
+        }
+        info.Created, info.Updated = time.Unix(0, created), time.Unix(0, updated)
+        infos = append(infos, info)
+    }
+    return infos, rows.Err()
+}
+
+// Delete removes a session and its messages
+func (s *SQLiteStore) Delete(ctx context.Context, key string) error {
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    res, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE key = ?`, key)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return ErrNotFound
+    }
+    if _, err := tx.ExecContext(ctx, `DELETE FROM messages WHERE session_key = ?`, key); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+    return s.db.Close()
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+// Store backends
+const (
+    StoreJSONL  = "jsonl"
+    StoreSQLite = "sqlite"
+)
+
+// ErrNotFound is returned for sessions that have no stored messages
+var ErrNotFound = errors.New("session not found")
+
+// Info summarizes a stored session. Size is the stored size in bytes: the
+// file for JSONL, the message contents for SQLite.
+type Info struct {
+    Key      string    `json:"key"`
+    Channel  string    `json:"channel"`
+    ChatID   string    `json:"chat_id"`
+    Messages int       `json:"messages"`
+    Size     int64     `json:"size"`
+    Created  time.Time `json:"created"`
+    Updated  time.Time `json:"updated"`
+}
+
+// Filter selects sessions for List. Zero fields match everything; the
+// time bounds apply to the last update.
+type Filter struct {
+    Channel       string
+    ChatID        string
+    UpdatedAfter  time.Time
+    UpdatedBefore time.Time
+    Offset        int
+    Limit         int
+}
+
+// Store persists session messages. Writes only ever append, and stores are
+// safe for concurrent use.
+type Store interface {
+    // Append adds messages to the end of a session, creating it if needed
+    Append(ctx context.Context, key string, msgs []*Message) error
+    // Messages returns a page of a session's messages, oldest first; a
+    // limit <= 0 returns everything from offset on
+    Messages(ctx context.Context, key string, offset, limit int) ([]*Message, error)
+    // Tail returns the last n messages of a session, oldest first
+    Tail(ctx context.Context, key string, n int) ([]*Message, error)
+    // Get summarizes one session
+    Get(ctx context.Context, key string) (Info, error)
+    // List returns matching sessions, most recently updated first
+    List(ctx context.Context, f Filter) ([]Info, error)
+    // Delete removes a session and its messages
+    Delete(ctx context.Context, key string) error
+    Close() error
+}
+
+// OpenStore opens the backend that keeps the sessions of a workspace, under
+// <workspace>/sessions
+func OpenStore(backend, workspace string) (Store, error) {
+    dir := filepath.Join(workspace, "sessions")
+    switch strings.ToLower(strings.TrimSpace(backend)) {
+    case "", StoreJSONL:
+        return NewJSONLStore(dir), nil
+    case StoreSQLite:
+        return NewSQLiteStore(filepath.Join(dir, "sessions.db"))
+    default:
+        return nil, fmt.Errorf("unknown session store: %s", backend)
+    }
+}
+
+// SplitKey splits a channel:chat_id session key
+func SplitKey(key string) (channel, chatID string) {
+    channel, chatID, _ = strings.Cut(key, ":")
+    return channel, chatID
+}
+
+// matches reports whether a session passes the non-paging parts of f
+func (f Filter) matches(info Info) bool {
+    if f.Channel != "" && info.Channel != f.Channel {
+        return false
+    }
+    if f.ChatID != "" && info.ChatID != f.ChatID {
+        return false
+    }
+    if !f.UpdatedAfter.IsZero() && !info.Updated.After(f.UpdatedAfter) {
+        return false
+    }
+    if !f.UpdatedBefore.IsZero() && !info.Updated.Before(f.UpdatedBefore) {
+        return false
+    }
+    return true
+}
+
+// page applies offset and limit to a slice
+func page[T any](items []T, offset, limit int) []T {
+    if offset > len(items) {
+        offset = len(items)
+    }
+    items = items[max(offset, 0):]
+    if limit > 0 && limit < len(items) {
+        items = items[:limit]
+    }
+    return items
+}
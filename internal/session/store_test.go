@@ -0,0 +1,201 @@
+package session
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "path/filepath"
+    "sync"
+    "testing"
+    "time"
+)
+
+// stores returns a fresh store of each backend, plus a function opening a
+// second handle on the same data, as another process would
+func stores(t *testing.T) map[string]func() Store {
+    dir := t.TempDir()
+    open := map[string]func() Store{
+        StoreJSONL: func() Store { return NewJSONLStore(filepath.Join(dir, "jsonl")) },
+        StoreSQLite: func() Store {
+            s, err := NewSQLiteStore(filepath.Join(dir, "sqlite", "sessions.db"))
+            if err != nil {
+                t.Fatal(err)
+            }
+            return s
+        },
+    }
+    return open
+}
+
+func messages(start time.Time, contents ...string) []*Message {
+    msgs := make([]*Message, 0, len(contents))
+    for i, c := range contents {
+        role := "user"
+        if i%2 == 1 {
+            role = "assistant"
+        }
+        msgs = append(msgs, &Message{Role: role, Content: c, Timestamp: start.Add(time.Duration(i) * time.Second)})
+    }
+    return msgs
+}
+
+func TestStore_AppendAndRead(t *testing.T) {
+    ctx := context.Background()
+    base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+    for name, open := range stores(t) {
+        t.Run(name, func(t *testing.T) {
+            store := open()
+            defer store.Close()
+
+            if err := store.Append(ctx, "telegram:42", messages(base, "hi", "hello", "how are you?")); err != nil {
+                t.Fatal(err)
+            }
+            if err := store.Append(ctx, "telegram:42", messages(base.Add(time.Minute), "fine", "good")); err != nil {
+                t.Fatal(err)
+            }
+
+            all, err := store.Messages(ctx, "telegram:42", 0, 0)
+            if err != nil || len(all) != 5 || all[0].Content != "hi" || all[4].Content != "good" {
+                t.Fatalf("unexpected messages %v %v", all, err)
+            }
+            if !all[0].Timestamp.Equal(base) || all[1].Role != "assistant" {
+                t.Fatalf("expected roles and timestamps to round-trip, got %+v", all[:2])
+            }
+            paged, err := store.Messages(ctx, "telegram:42", 2, 2)
+            if err != nil || len(paged) != 2 || paged[0].Content != "how are you?" || paged[1].Content != "fine" {
+                t.Fatalf("unexpected page %v %v", paged, err)
+            }
+            tail, err := store.Tail(ctx, "telegram:42", 2)
+            if err != nil || len(tail) != 2 || tail[0].Content != "fine" || tail[1].Content != "good" {
+                t.Fatalf("unexpected tail %v %v", tail, err)
+            }
+
+            info, err := store.Get(ctx, "telegram:42")
+            if err != nil {
+                t.Fatal(err)
+            }
+            if info.Channel != "telegram" || info.ChatID != "42" || info.Messages != 5 ||
+                !info.Created.Equal(base) || !info.Updated.Equal(base.Add(time.Minute+time.Second)) {
+                t.Fatalf("unexpected info %+v", info)
+            }
+
+            if _, err := store.Tail(ctx, "telegram:7", 10); !errors.Is(err, ErrNotFound) {
+                t.Fatalf("expected ErrNotFound, got %v", err)
+            }
+            if _, err := store.Get(ctx, "telegram:7"); !errors.Is(err, ErrNotFound) {
+                t.Fatalf("expected ErrNotFound, got %v", err)
+            }
+        })
+    }
+}
+
+func TestStore_ListAndDelete(t *testing.T) {
+    ctx := context.Background()
+    base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+    for name, open := range stores(t) {
+        t.Run(name, func(t *testing.T) {
+            store := open()
+            defer store.Close()
+
+            for i, key := range []string{"telegram:1", "telegram:2", "cli:direct", "mcp:ide"} {
+                if err := store.Append(ctx, key, messages(base.Add(time.Duration(i)*time.Hour), "q", "a")); err != nil {
+                    t.Fatal(err)
+                }
+            }
+
+            keys := func(f Filter) []string {
+                t.Helper()
+                infos, err := store.List(ctx, f)
+                if err != nil {
+                    t.Fatal(err)
+                }
+                var out []string
+                for _, info := range infos {
+                    out = append(out, info.Key)
+                }
+                return out
+            }
+            for _, tc := range []struct {
+                filter Filter
+                want   string
+            }{
+                {Filter{}, "[mcp:ide cli:direct telegram:2 telegram:1]"},
+                {Filter{Channel: "telegram"}, "[telegram:2 telegram:1]"},
+                {Filter{Channel: "telegram", ChatID: "1"}, "[telegram:1]"},
+                {Filter{UpdatedBefore: base.Add(90 * time.Minute)}, "[telegram:2 telegram:1]"},
+                {Filter{UpdatedAfter: base.Add(90 * time.Minute)}, "[mcp:ide cli:direct]"},
+                {Filter{Offset: 1, Limit: 2}, "[cli:direct telegram:2]"},
+                {Filter{Offset: 10}, "[]"},
+            } {
+                if got := fmt.Sprint(keys(tc.filter)); got != tc.want {
+                    t.Errorf("List(%+v) = %s, want %s", tc.filter, got, tc.want)
+                }
+            }
+
+            if err := store.Delete(ctx, "telegram:1"); err != nil {
+                t.Fatal(err)
+            }
+            if err := store.Delete(ctx, "telegram:1"); !errors.Is(err, ErrNotFound) {
+                t.Fatalf("expected ErrNotFound, got %v", err)
+            }
+            if got := fmt.Sprint(keys(Filter{Channel: "telegram"})); got != "[telegram:2]" {
+                t.Fatalf("expected the deleted session to be gone, got %s", got)
+            }
+            if _, err := store.Messages(ctx, "telegram:1", 0, 0); !errors.Is(err, ErrNotFound) {
+                t.Fatalf("expected the messages to be deleted, got %v", err)
+            }
+        })
+    }
+}
+
+func TestStore_ConcurrentAppends(t *testing.T) {
+    ctx := context.Background()
+    const writers, appends = 8, 15
+    for name, open := range stores(t) {
+        t.Run(name, func(t *testing.T) {
+            // Half the writers use a second handle, as another process would
+            first, second := open(), open()
+            defer first.Close()
+            defer second.Close()
+
+            var wg sync.WaitGroup
+            errs := make(chan error, writers*appends)
+            for w := 0; w < writers; w++ {
+                store := first
+                if w%2 == 1 {
+                    store = second
+                }
+                wg.Add(1)
+                go func() {
+                    defer wg.Done()
+                    for i := 0; i < appends; i++ {
+                        msgs := messages(time.Now(), fmt.Sprintf("w%d-%d", w, i), "ok")
+                        if err := store.Append(ctx, "telegram:busy", msgs); err != nil {
+                            errs <- err
+                        }
+                    }
+                }()
+            }
+            wg.Wait()
+            close(errs)
+            for err := range errs {
+                t.Fatal(err)
+            }
+
+            all, err := first.Messages(ctx, "telegram:busy", 0, 0)
+            if err != nil || len(all) != writers*appends*2 {
+                t.Fatalf("expected %d messages, got %d %v", writers*appends*2, len(all), err)
+            }
+            // Each append stays together
+            for i := 0; i < len(all); i += 2 {
+                if all[i].Role != "user" || all[i+1].Content != "ok" {
+                    t.Fatalf("appends interleaved at %d: %+v %+v", i, all[i], all[i+1])
+                }
+            }
+            info, err := second.Get(ctx, "telegram:busy")
+            if err != nil || info.Messages != len(all) {
+                t.Fatalf("unexpected info %+v %v", info, err)
+            }
+        })
+    }
+}
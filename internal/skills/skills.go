@@ -0,0 +1,296 @@
+// Package skills reads skills from the workspace. A skill is a folder holding
+// a SKILL.md, whose frontmatter says what the skill is for and whose body
+// holds the instructions, plus any scripts or files those instructions use.
+package skills
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileName is the instructions file every skill folder must contain
+	FileName = "SKILL.md"
+	maxFiles = 100
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// Skill describes an installed skill
+type Skill struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	WhenToUse   string `json:"when_to_use,omitempty"`
+	// Dir is the skill folder
+	Dir string `json:"-"`
+}
+
+type frontmatter struct {
+	Name          string `yaml:"name"`
+	Description   string `yaml:"description"`
+	WhenToUse     string `yaml:"when_to_use"`
+	WhenToUseDash string `yaml:"when-to-use"`
+}
+
+// Loader finds skills in a directory, normally <workspace>/skills
+type Loader struct {
+	dir string
+}
+
+// NewLoader creates a loader for dir
+func NewLoader(dir string) *Loader {
+	return &Loader{dir: dir}
+}
+
+// Dir returns the skills directory
+func (l *Loader) Dir() string {
+	return l.dir
+}
+
+// List returns the installed skills sorted by name. Folders without a
+// readable SKILL.md are skipped.
+func (l *Loader) List() ([]Skill, error) {
+	entries, err := os.ReadDir(l.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var skills []Skill
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		skill, _, err := readSkill(filepath.Join(l.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		skills = append(skills, skill)
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].Name < skills[j].Name })
+	return skills, nil
+}
+
+// Get finds a skill by name
+func (l *Loader) Get(name string) (Skill, error) {
+	name = strings.TrimSpace(name)
+	skills, err := l.List()
+	if err != nil {
+		return Skill{}, err
+	}
+	for _, s := range skills {
+		if strings.EqualFold(s.Name, name) {
+			return s, nil
+		}
+	}
+	return Skill{}, fmt.Errorf("no skill named %q", name)
+}
+
+// Load returns a skill's instructions and the other files in its folder,
+// relative to the folder
+func (l *Loader) Load(name string) (Skill, string, []string, error) {
+	skill, err := l.Get(name)
+	if err != nil {
+		return Skill{}, "", nil, err
+	}
+	_, body, err := readSkill(skill.Dir)
+	if err != nil {
+		return Skill{}, "", nil, err
+	}
+	var files []string
+	_ = filepath.WalkDir(skill.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == skill.Dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || d.Name() == FileName {
+			return nil
+		}
+		if len(files) >= maxFiles {
+			return filepath.SkipAll
+		}
+		rel, _ := filepath.Rel(skill.Dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return skill, body, files, nil
+}
+
+// Add installs a skill from a local folder or a git repository URL and
+// returns it. The folder is named after the skill.
+func (l *Loader) Add(ctx context.Context, source string) (Skill, error) {
+	src := source
+	if isGitURL(source) {
+		tmp, err := os.MkdirTemp("", "golem-skill-*")
+		if err != nil {
+			return Skill{}, err
+		}
+		defer os.RemoveAll(tmp)
+		src = filepath.Join(tmp, "repo")
+		cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--quiet", source, src)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return Skill{}, fmt.Errorf("git clone failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	skill, _, err := readSkill(src)
+	if err != nil {
+		return Skill{}, err
+	}
+	if !validName.MatchString(skill.Name) {
+		return Skill{}, fmt.Errorf("invalid skill name %q: use lowercase letters, digits, '.', '_' and '-'", skill.Name)
+	}
+	if _, err := l.Get(skill.Name); err == nil {
+		return Skill{}, fmt.Errorf("skill %q is already installed", skill.Name)
+	}
+	dest := filepath.Join(l.dir, skill.Name)
+	if _, err := os.Lstat(dest); err == nil {
+		return Skill{}, fmt.Errorf("%s already exists", dest)
+	}
+	if err := copyTree(src, dest); err != nil {
+		os.RemoveAll(dest)
+		return Skill{}, err
+	}
+	skill.Dir = dest
+	return skill, nil
+}
+
+// Remove deletes an installed skill
+func (l *Loader) Remove(name string) (Skill, error) {
+	skill, err := l.Get(name)
+	if err != nil {
+		return Skill{}, err
+	}
+	return skill, os.RemoveAll(skill.Dir)
+}
+
+// readSkill parses dir/SKILL.md. The name defaults to the folder name and
+// the description to the first paragraph of the body.
+func readSkill(dir string) (Skill, string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		return Skill{}, "", err
+	}
+	meta, body, err := parseFrontmatter(string(data))
+	if err != nil {
+		return Skill{}, "", fmt.Errorf("%s: %w", filepath.Join(dir, FileName), err)
+	}
+	skill := Skill{
+		Name:        strings.TrimSpace(meta.Name),
+		Description: oneLine(meta.Description),
+		WhenToUse:   oneLine(meta.WhenToUse),
+		Dir:         dir,
+	}
+	if skill.WhenToUse == "" {
+		skill.WhenToUse = oneLine(meta.WhenToUseDash)
+	}
+	if skill.Name == "" {
+		skill.Name = filepath.Base(dir)
+	}
+	if skill.Description == "" {
+		for _, para := range strings.Split(body, "\n\n") {
+			if para = strings.TrimSpace(para); para != "" && !strings.HasPrefix(para, "#") {
+				skill.Description = oneLine(para)
+				break
+			}
+		}
+	}
+	return skill, body, nil
+}
+
+func parseFrontmatter(text string) (frontmatter, string, error) {
+	var meta frontmatter
+	text = strings.TrimPrefix(strings.ReplaceAll(text, "\r\n", "\n"), "\ufeff")
+	if !strings.HasPrefix(text, "---\n") {
+		return meta, strings.TrimSpace(text), nil
+	}
+	rest := text[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return meta, "", fmt.Errorf("frontmatter is not closed with ---")
+	}
+	if err := yaml.Unmarshal([]byte(rest[:end]), &meta); err != nil {
+		return meta, "", fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	body := rest[end+len("\n---"):]
+	if i := strings.Index(body, "\n"); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = ""
+	}
+	return meta, strings.TrimSpace(body), nil
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func isGitURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://") ||
+		strings.HasPrefix(s, "git@") || strings.HasPrefix(s, "ssh://") || strings.HasSuffix(s, ".git")
+}
+
+// copyTree copies regular files and directories, keeping permission bits
+// so scripts stay executable. Symlinks and the .git folder are skipped.
+func copyTree(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(src, dest string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,102 @@
+package skills
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeSkill(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "scripts", "run.sh"), []byte("#!/bin/sh\necho hi\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoader_ListAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeSkill(t, filepath.Join(dir, "pdf"), "---\nname: pdf-tools\ndescription: Fill and merge PDF forms\nwhen_to_use: >\n  The user sends a PDF\n  form\n---\n# PDF\n\nRun scripts/run.sh first.\n")
+	writeSkill(t, filepath.Join(dir, "notes"), "# Notes\n\nKeep meeting notes tidy.\n")
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeSkill(t, filepath.Join(dir, "broken"), "---\nname: [unclosed\n---\n")
+
+	loader := NewLoader(dir)
+	list, err := loader.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "notes" || list[1].Name != "pdf-tools" {
+		t.Fatalf("unexpected skills %+v", list)
+	}
+	if list[0].Description != "Keep meeting notes tidy." {
+		t.Fatalf("expected description from the body, got %q", list[0].Description)
+	}
+	if list[1].WhenToUse != "The user sends a PDF form" {
+		t.Fatalf("unexpected when_to_use %q", list[1].WhenToUse)
+	}
+
+	skill, body, files, err := loader.Load("PDF-Tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skill.Dir != filepath.Join(dir, "pdf") || body != "# PDF\n\nRun scripts/run.sh first." {
+		t.Fatalf("unexpected load %+v %q", skill, body)
+	}
+	if len(files) != 1 || files[0] != "scripts/run.sh" {
+		t.Fatalf("unexpected files %v", files)
+	}
+	if _, _, _, err := loader.Load("missing"); err == nil {
+		t.Fatal("expected unknown skill to fail")
+	}
+}
+
+func TestLoader_AddAndRemove(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "download")
+	writeSkill(t, src, "---\nname: release-notes\ndescription: Draft release notes from git log\n---\nSteps.\n")
+	loader := NewLoader(filepath.Join(t.TempDir(), "skills"))
+
+	skill, err := loader.Add(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skill.Dir != filepath.Join(loader.Dir(), "release-notes") {
+		t.Fatalf("expected folder named after the skill, got %s", skill.Dir)
+	}
+	info, err := os.Stat(filepath.Join(skill.Dir, "scripts", "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0100 == 0 {
+		t.Fatalf("expected script to stay executable, got %v", info.Mode())
+	}
+	if _, err := loader.Add(context.Background(), src); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Fatalf("expected duplicate install to fail, got %v", err)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad")
+	writeSkill(t, bad, "---\nname: ../escape\n---\n")
+	if _, err := loader.Add(context.Background(), bad); err == nil {
+		t.Fatal("expected invalid name to be rejected")
+	}
+
+	if _, err := loader.Remove("release-notes"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(skill.Dir); !os.IsNotExist(err) {
+		t.Fatalf("expected skill folder removed, got %v", err)
+	}
+	if _, err := loader.Remove("release-notes"); err == nil {
+		t.Fatal("expected removing a missing skill to fail")
+	}
+}
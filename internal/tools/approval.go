@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ErrApprovalDenied is returned when a tool call is not approved
+var ErrApprovalDenied = errors.New("tool call denied")
+
+// DefaultApprovalTimeout bounds how long a tool call waits for a decision
+const DefaultApprovalTimeout = 2 * time.Minute
+
+// ApprovalDecision is the human answer to an approval request
+type ApprovalDecision int
+
+const (
+	ApprovalDenied ApprovalDecision = iota
+	ApprovalApproved
+	ApprovalAlwaysAllow
+)
+
+// ApprovalRequest describes a pending tool call
+type ApprovalRequest struct {
+	ID         string
+	Tool       string
+	Args       string
+	Reason     string
+	Invocation Invocation
+}
+
+// Approver asks a human to approve a tool call. Implementations must
+// return promptly once ctx is done.
+type Approver interface {
+	RequestApproval(ctx context.Context, req *ApprovalRequest) (ApprovalDecision, error)
+}
+
+// ApprovalRule requires approval for Tool ("*" for any), optionally only
+// when the raw JSON arguments match Pattern
+type ApprovalRule struct {
+	Tool    string
+	Pattern string
+}
+
+type compiledApprovalRule struct {
+	tool    string
+	pattern *regexp.Regexp
+}
+
+// ApprovalPolicy decides which tool calls need a human decision and routes
+// the request to the approver of the originating channel
+type ApprovalPolicy struct {
+	mu        sync.RWMutex
+	rules     []compiledApprovalRule
+	timeout   time.Duration
+	approvers map[string]Approver
+	always    map[string]bool
+}
+
+// NewApprovalPolicy creates a policy from rules
+func NewApprovalPolicy(rules []ApprovalRule, timeout time.Duration) (*ApprovalPolicy, error) {
+	p := &ApprovalPolicy{
+		approvers: make(map[string]Approver),
+		always:    make(map[string]bool),
+	}
+	if err := p.SetRules(rules, timeout); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetRules replaces the approval rules
+func (p *ApprovalPolicy) SetRules(rules []ApprovalRule, timeout time.Duration) error {
+	compiled := make([]compiledApprovalRule, 0, len(rules))
+	for _, r := range rules {
+		if r.Tool == "" {
+			return fmt.Errorf("approval rule missing tool")
+		}
+		c := compiledApprovalRule{tool: r.Tool}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid approval pattern for %s: %w", r.Tool, err)
+			}
+			c.pattern = re
+		}
+		compiled = append(compiled, c)
+	}
+	if timeout <= 0 {
+		timeout = DefaultApprovalTimeout
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rules = compiled
+	p.timeout = timeout
+	return nil
+}
+
+// SetApprover registers the approver for a channel
+func (p *ApprovalPolicy) SetApprover(channel string, a Approver) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if a == nil {
+		delete(p.approvers, channel)
+		return
+	}
+	p.approvers[channel] = a
+}
+
+// Match reports whether a call needs approval and which rule matched
+func (p *ApprovalPolicy) Match(name, argsJSON string) (string, bool) {
+	_, reason, ok := p.match(name, argsJSON)
+	return reason, ok
+}
+
+// match returns the first matching rule as an identifier along with the
+// reason shown to the approver
+func (p *ApprovalPolicy) match(name, argsJSON string) (string, string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, r := range p.rules {
+		if r.tool != "*" && r.tool != name {
+			continue
+		}
+		if r.pattern == nil {
+			return "tool " + r.tool, fmt.Sprintf("tool %s requires approval", name), true
+		}
+		if r.pattern.MatchString(argsJSON) {
+			return "pattern " + r.pattern.String(), fmt.Sprintf("arguments match %q", r.pattern.String()), true
+		}
+	}
+	return "", "", false
+}
+
+// Authorize applies the rules to a call, asking for approval when one matches
+func (p *ApprovalPolicy) Authorize(ctx context.Context, name, argsJSON string) error {
+	rule, reason, ok := p.match(name, argsJSON)
+	if !ok {
+		return nil
+	}
+	return p.Require(ctx, name, argsJSON, rule, reason)
+}
+
+// Require asks the originating channel's approver and waits for a decision.
+// rule identifies what triggered the request: an always-allow answer only
+// covers later calls of the same tool matching the same rule in the same chat.
+func (p *ApprovalPolicy) Require(ctx context.Context, name, argsJSON, rule, reason string) error {
+	inv, _ := InvocationFromContext(ctx)
+	alwaysKey := inv.SessionKey() + "\x00" + name + "\x00" + rule
+
+	p.mu.RLock()
+	allowed := p.always[alwaysKey]
+	approver := p.approvers[inv.Channel]
+	timeout := p.timeout
+	p.mu.RUnlock()
+
+	if allowed {
+		return nil
+	}
+	if approver == nil {
+		return fmt.Errorf("%w: %s (no approver available on channel %q)", ErrApprovalDenied, reason, inv.Channel)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	decision, err := approver.RequestApproval(waitCtx, &ApprovalRequest{
+		ID:         newApprovalID(),
+		Tool:       name,
+		Args:       argsJSON,
+		Reason:     reason,
+		Invocation: inv,
+	})
+	if err != nil {
+		if errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: approval timed out after %s", ErrApprovalDenied, timeout)
+		}
+		return fmt.Errorf("%w: %v", ErrApprovalDenied, err)
+	}
+
+	switch decision {
+	case ApprovalAlwaysAllow:
+		p.mu.Lock()
+		p.always[alwaysKey] = true
+		p.mu.Unlock()
+		return nil
+	case ApprovalApproved:
+		return nil
+	default:
+		return fmt.Errorf("%w by user", ErrApprovalDenied)
+	}
+}
+
+type approvalPolicyKey struct{}
+
+// RequireApproval lets a tool ask for approval of an operation it considers
+// risky, naming the rule that flagged it. It uses the policy of the registry
+// executing the tool.
+func RequireApproval(ctx context.Context, name, argsJSON, rule, reason string) error {
+	p, ok := ctx.Value(approvalPolicyKey{}).(*ApprovalPolicy)
+	if !ok || p == nil {
+		return fmt.Errorf("%w: %s (approval unavailable)", ErrApprovalDenied, reason)
+	}
+	return p.Require(ctx, name, argsJSON, rule, reason)
+}
+
+func newApprovalID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
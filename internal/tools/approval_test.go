@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+type stubApprover struct {
+	decision ApprovalDecision
+	block    bool
+	requests []*ApprovalRequest
+}
+
+func (s *stubApprover) RequestApproval(ctx context.Context, req *ApprovalRequest) (ApprovalDecision, error) {
+	s.requests = append(s.requests, req)
+	if s.block {
+		<-ctx.Done()
+		return ApprovalDenied, ctx.Err()
+	}
+	return s.decision, nil
+}
+
+type countingTool struct {
+	name  string
+	calls int
+}
+
+func (c *countingTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: c.name, Desc: "counts calls"}, nil
+}
+
+func (c *countingTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	c.calls++
+	return "ran", nil
+}
+
+func newApprovalRegistry(t *testing.T, rules []ApprovalRule, timeout time.Duration) (*Registry, *countingTool) {
+	t.Helper()
+	reg := NewRegistry()
+	ct := &countingTool{name: "exec"}
+	if err := reg.Register(ct); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	if err := reg.Approvals().SetRules(rules, timeout); err != nil {
+		t.Fatalf("SetRules error: %v", err)
+	}
+	return reg, ct
+}
+
+func cliContext() context.Context {
+	return WithInvocation(context.Background(), Invocation{Channel: "cli", ChatID: "direct"})
+}
+
+func TestApprovalPolicy_Match(t *testing.T) {
+	p, err := NewApprovalPolicy([]ApprovalRule{
+		{Tool: "write_file"},
+		{Tool: "exec", Pattern: `git\s+push`},
+	}, 0)
+	if err != nil {
+		t.Fatalf("NewApprovalPolicy error: %v", err)
+	}
+
+	if _, ok := p.Match("write_file", `{}`); !ok {
+		t.Error("expected write_file to require approval")
+	}
+	if _, ok := p.Match("exec", `{"command":"git status"}`); ok {
+		t.Error("expected git status not to require approval")
+	}
+	if reason, ok := p.Match("exec", `{"command":"git  push origin"}`); !ok || !strings.Contains(reason, "git") {
+		t.Errorf("expected git push to require approval, got %q %v", reason, ok)
+	}
+	if _, ok := p.Match("read_file", `{}`); ok {
+		t.Error("expected read_file to be unrestricted")
+	}
+
+	if _, err := NewApprovalPolicy([]ApprovalRule{{Tool: "exec", Pattern: "("}}, 0); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
+
+func TestRegistry_ExecuteWaitsForApproval(t *testing.T) {
+	reg, ct := newApprovalRegistry(t, []ApprovalRule{{Tool: "exec"}}, time.Second)
+	approver := &stubApprover{decision: ApprovalApproved}
+	reg.Approvals().SetApprover("cli", approver)
+
+	out, err := reg.Execute(cliContext(), "exec", `{"command":"ls"}`)
+	if err != nil || out != "ran" {
+		t.Fatalf("expected approved call to run, got %q %v", out, err)
+	}
+	if len(approver.requests) != 1 || approver.requests[0].Invocation.ChatID != "direct" {
+		t.Fatalf("expected one request with invocation, got %+v", approver.requests)
+	}
+	if ct.calls != 1 {
+		t.Fatalf("expected tool to run once, got %d", ct.calls)
+	}
+}
+
+func TestRegistry_ExecuteDenied(t *testing.T) {
+	reg, ct := newApprovalRegistry(t, []ApprovalRule{{Tool: "exec"}}, time.Second)
+	reg.Approvals().SetApprover("cli", &stubApprover{decision: ApprovalDenied})
+
+	_, err := reg.Execute(cliContext(), "exec", `{}`)
+	if !errors.Is(err, ErrApprovalDenied) {
+		t.Fatalf("expected denial, got %v", err)
+	}
+	if ct.calls != 0 {
+		t.Fatal("denied tool must not run")
+	}
+}
+
+func TestRegistry_ExecuteAlwaysAllowIsPerSession(t *testing.T) {
+	reg, ct := newApprovalRegistry(t, []ApprovalRule{{Tool: "exec"}}, time.Second)
+	approver := &stubApprover{decision: ApprovalAlwaysAllow}
+	reg.Approvals().SetApprover("cli", approver)
+
+	for i := 0; i < 3; i++ {
+		if _, err := reg.Execute(cliContext(), "exec", `{}`); err != nil {
+			t.Fatalf("Execute error: %v", err)
+		}
+	}
+	if len(approver.requests) != 1 {
+		t.Fatalf("expected a single prompt, got %d", len(approver.requests))
+	}
+
+	other := WithInvocation(context.Background(), Invocation{Channel: "cli", ChatID: "other"})
+	if _, err := reg.Execute(other, "exec", `{}`); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if len(approver.requests) != 2 {
+		t.Fatalf("expected another session to be prompted again, got %d", len(approver.requests))
+	}
+	if ct.calls != 4 {
+		t.Fatalf("expected 4 runs, got %d", ct.calls)
+	}
+}
+
+func TestRegistry_ExecuteAlwaysAllowIsPerRule(t *testing.T) {
+	reg, ct := newApprovalRegistry(t, []ApprovalRule{
+		{Tool: "exec", Pattern: `git\s+push`},
+		{Tool: "exec", Pattern: `rm\s`},
+	}, time.Second)
+	approver := &stubApprover{decision: ApprovalAlwaysAllow}
+	reg.Approvals().SetApprover("cli", approver)
+
+	for _, args := range []string{`{"command":"git push"}`, `{"command":"git push -f"}`} {
+		if _, err := reg.Execute(cliContext(), "exec", args); err != nil {
+			t.Fatalf("Execute error: %v", err)
+		}
+	}
+	if len(approver.requests) != 1 {
+		t.Fatalf("expected the same rule to be allowed after one prompt, got %d", len(approver.requests))
+	}
+
+	approver.decision = ApprovalDenied
+	if _, err := reg.Execute(cliContext(), "exec", `{"command":"rm -rf build"}`); !errors.Is(err, ErrApprovalDenied) {
+		t.Fatalf("expected another rule to be prompted and denied, got %v", err)
+	}
+	if len(approver.requests) != 2 || ct.calls != 2 {
+		t.Fatalf("expected 2 prompts and 2 runs, got %d and %d", len(approver.requests), ct.calls)
+	}
+
+	approver.decision = ApprovalApproved
+	ctx := context.WithValue(cliContext(), approvalPolicyKey{}, reg.Approvals())
+	if err := RequireApproval(ctx, "exec", `{}`, "command rule power", "command matches rule power"); err != nil {
+		t.Fatalf("RequireApproval error: %v", err)
+	}
+	if len(approver.requests) != 3 {
+		t.Fatalf("expected a command rule to be prompted separately, got %d", len(approver.requests))
+	}
+}
+
+func TestRegistry_ExecuteApprovalTimeout(t *testing.T) {
+	reg, ct := newApprovalRegistry(t, []ApprovalRule{{Tool: "exec"}}, 20*time.Millisecond)
+	reg.Approvals().SetApprover("cli", &stubApprover{block: true})
+
+	_, err := reg.Execute(cliContext(), "exec", `{}`)
+	if !errors.Is(err, ErrApprovalDenied) || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout denial, got %v", err)
+	}
+	if ct.calls != 0 {
+		t.Fatal("timed out tool must not run")
+	}
+}
+
+func TestRegistry_ExecuteWithoutApproverDenies(t *testing.T) {
+	reg, _ := newApprovalRegistry(t, []ApprovalRule{{Tool: "*"}}, time.Second)
+
+	_, err := reg.Execute(cliContext(), "exec", `{}`)
+	if !errors.Is(err, ErrApprovalDenied) || !strings.Contains(err.Error(), "no approver") {
+		t.Fatalf("expected denial without approver, got %v", err)
+	}
+}
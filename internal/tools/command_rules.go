@@ -0,0 +1,650 @@
+package tools
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// RuleAction is the outcome of a command rule
+type RuleAction string
+
+const (
+	RuleAllow RuleAction = "allow"
+	RuleAsk   RuleAction = "ask"
+	RuleDeny  RuleAction = "deny"
+)
+
+// CommandRule matches simple commands found in a shell command line.
+//
+// Program is a glob on the program name with its directory stripped and
+// wrappers such as sudo, env or xargs removed. Each Flags entry lists
+// alternatives separated by "|" ("r|R|recursive") and all entries must be
+// present. Each Args regexp must match some positional argument; relative
+// arguments are also tried against the directory an earlier cd in the same
+// line moved to. Redirect matches the target of an output redirection. Empty
+// fields match anything, but a rule needs Program or Redirect.
+type CommandRule struct {
+	Name     string
+	Action   RuleAction
+	Program  string
+	Flags    []string
+	Args     []string
+	Redirect string
+}
+
+// CommandVerdict explains how a command line was classified
+type CommandVerdict struct {
+	Action  RuleAction
+	Rule    string
+	Command string
+}
+
+type compiledCommandRule struct {
+	CommandRule
+	flags    [][]string
+	args     []*regexp.Regexp
+	redirect *regexp.Regexp
+}
+
+// CommandAnalyzer parses shell command lines and evaluates rules over the
+// programs and arguments they actually run
+type CommandAnalyzer struct {
+	rules []compiledCommandRule
+}
+
+const (
+	ruleForkBomb       = "fork-bomb"
+	rulePipeToShell    = "pipe-to-shell"
+	ruleDynamicProgram = "dynamic-program"
+	maxNesting         = 4
+)
+
+// DefaultCommandRules are the built-in rules applied after configured ones
+func DefaultCommandRules() []CommandRule {
+	systemPath := `^(/|/\*|(~|\$HOME|\$\{HOME\})(/\*)?|/(bin|boot|dev|etc|home|lib|lib64|opt|proc|root|sbin|srv|sys|usr|var)(/\*)?)$`
+	return []CommandRule{
+		{Name: "rm-system-path", Action: RuleDeny, Program: "rm", Flags: []string{"r|R|recursive"}, Args: []string{systemPath}},
+		{Name: "chmod-system-path", Action: RuleDeny, Program: "chmod", Flags: []string{"R|recursive"}, Args: []string{systemPath}},
+		{Name: "chown-system-path", Action: RuleDeny, Program: "chown", Flags: []string{"R|recursive"}, Args: []string{systemPath}},
+		{Name: "mkfs", Action: RuleDeny, Program: "mkfs*"},
+		{Name: "mkfs", Action: RuleDeny, Program: "mke2fs"},
+		{Name: "mkswap", Action: RuleDeny, Program: "mkswap"},
+		{Name: "wipefs", Action: RuleDeny, Program: "wipefs"},
+		{Name: "dd-to-device", Action: RuleDeny, Program: "dd", Args: []string{`^of=/dev/`}},
+		{Name: "write-to-device", Action: RuleDeny, Redirect: `^/dev/(sd|hd|vd|xvd|nvme|mmcblk|disk|mapper/)`},
+		{Name: "windows-format", Action: RuleDeny, Program: "format", Args: []string{`(?i)^[a-z]:\\?$`}},
+		{Name: "windows-recursive-delete", Action: RuleDeny, Program: "del", Args: []string{`(?i)^/s$`, `(?i)^/q$`}},
+		{Name: "power", Action: RuleAsk, Program: "shutdown"},
+		{Name: "power", Action: RuleAsk, Program: "reboot"},
+		{Name: "power", Action: RuleAsk, Program: "poweroff"},
+		{Name: "power", Action: RuleAsk, Program: "halt"},
+	}
+}
+
+// NewCommandAnalyzer compiles rules; configured rules take precedence over defaults
+func NewCommandAnalyzer(rules []CommandRule, includeDefaults bool) (*CommandAnalyzer, error) {
+	if includeDefaults {
+		rules = append(append([]CommandRule{}, rules...), DefaultCommandRules()...)
+	}
+
+	a := &CommandAnalyzer{}
+	for i, r := range rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		switch r.Action {
+		case RuleAllow, RuleAsk, RuleDeny:
+		default:
+			return nil, fmt.Errorf("command rule %s: unknown action %q", r.Name, r.Action)
+		}
+		if r.Program == "" && r.Redirect == "" {
+			return nil, fmt.Errorf("command rule %s: program or redirect is required", r.Name)
+		}
+		if _, err := path.Match(r.Program, ""); err != nil {
+			return nil, fmt.Errorf("command rule %s: invalid program %q: %w", r.Name, r.Program, err)
+		}
+
+		c := compiledCommandRule{CommandRule: r}
+		for _, f := range r.Flags {
+			c.flags = append(c.flags, strings.Split(f, "|"))
+		}
+		for _, expr := range r.Args {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("command rule %s: invalid args %q: %w", r.Name, expr, err)
+			}
+			c.args = append(c.args, re)
+		}
+		if r.Redirect != "" {
+			re, err := regexp.Compile(r.Redirect)
+			if err != nil {
+				return nil, fmt.Errorf("command rule %s: invalid redirect %q: %w", r.Name, r.Redirect, err)
+			}
+			c.redirect = re
+		}
+		a.rules = append(a.rules, c)
+	}
+	return a, nil
+}
+
+// simpleCommand is a normalized program invocation. unknownArgs is set when
+// some arguments cannot be known from the command line, because xargs reads
+// them from stdin or a variable is followed by "..". dir is the directory a
+// preceding cd moved to, if known.
+type simpleCommand struct {
+	program     string
+	positional  []string
+	flags       map[string]bool
+	redirects   []string
+	unknownArgs bool
+	dir         string
+	text        string
+}
+
+// Evaluate classifies a command line. Deny outranks ask, which outranks allow.
+func (a *CommandAnalyzer) Evaluate(command string) CommandVerdict {
+	verdict := CommandVerdict{Action: RuleAllow}
+	for _, f := range analyzeCommand(command, "", 0) {
+		v := a.evaluateOne(f)
+		if severity(v.Action) > severity(verdict.Action) || (verdict.Rule == "" && v.Rule != "") {
+			verdict = v
+		}
+	}
+	return verdict
+}
+
+func severity(a RuleAction) int {
+	switch a {
+	case RuleDeny:
+		return 2
+	case RuleAsk:
+		return 1
+	}
+	return 0
+}
+
+// finding is either a simple command or a structural match
+type finding struct {
+	cmd  *simpleCommand
+	rule string
+	act  RuleAction
+	text string
+}
+
+func (a *CommandAnalyzer) evaluateOne(f finding) CommandVerdict {
+	if f.cmd == nil {
+		return CommandVerdict{Action: f.act, Rule: f.rule, Command: f.text}
+	}
+	for i := range a.rules {
+		r := &a.rules[i]
+		if ok, uncertain := r.matches(f.cmd); ok {
+			action := r.Action
+			if uncertain && action == RuleDeny {
+				action = RuleAsk
+			}
+			return CommandVerdict{Action: action, Rule: r.Name, Command: f.cmd.text}
+		}
+	}
+	return CommandVerdict{Action: RuleAllow, Command: f.cmd.text}
+}
+
+// matches reports whether the rule applies to c. Unknown arguments may be
+// anything, so they satisfy Args patterns but leave the match uncertain, and
+// a deny becomes an ask.
+func (r *compiledCommandRule) matches(c *simpleCommand) (ok, uncertain bool) {
+	if r.Program != "" {
+		if ok, _ := path.Match(r.Program, c.program); !ok {
+			return false, false
+		}
+	}
+	for _, alternatives := range r.flags {
+		found := false
+		for _, f := range alternatives {
+			if c.flags[f] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, false
+		}
+	}
+	for _, re := range r.args {
+		found := false
+		for _, arg := range c.positional {
+			if re.MatchString(arg) || (c.dir != "" && isRelative(arg) && re.MatchString(path.Join(c.dir, arg))) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			if !c.unknownArgs {
+				return false, false
+			}
+			uncertain = true
+		}
+	}
+	if r.redirect != nil {
+		found := false
+		for _, target := range c.redirects {
+			if r.redirect.MatchString(target) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, false
+		}
+	}
+	return true, uncertain
+}
+
+// analyzeCommand parses a command line into findings. Unparseable input is
+// treated as a single whitespace-separated command so rules still apply.
+// dir is the working directory the line starts in when a cd made it known.
+func analyzeCommand(command, dir string, depth int) []finding {
+	if depth > maxNesting {
+		return nil
+	}
+	file, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(command), "")
+	if err != nil {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return nil
+		}
+		return commandFindings(fields, nil, command, dir, depth)
+	}
+
+	var findings []finding
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch n := node.(type) {
+		case *syntax.Stmt:
+			call, ok := n.Cmd.(*syntax.CallExpr)
+			if ok && len(call.Args) > 0 {
+				words := make([]string, 0, len(call.Args))
+				for _, w := range call.Args {
+					words = append(words, wordValue(w))
+				}
+				findings = append(findings, commandFindings(words, outputRedirects(n.Redirs), printNode(n), dir, depth)...)
+				if target, ok := cdTarget(words, dir); ok {
+					dir = target
+				}
+			} else if targets := outputRedirects(n.Redirs); len(targets) > 0 {
+				findings = append(findings, finding{cmd: &simpleCommand{redirects: targets, text: printNode(n)}})
+			}
+		case *syntax.FuncDecl:
+			if selfCalls(n) >= 2 {
+				findings = append(findings, finding{rule: ruleForkBomb, act: RuleDeny, text: printNode(n)})
+			}
+		case *syntax.BinaryCmd:
+			if n.Op == syntax.Pipe || n.Op == syntax.PipeAll {
+				if pipesToShell(n) {
+					findings = append(findings, finding{rule: rulePipeToShell, act: RuleAsk, text: printNode(n)})
+				}
+			}
+		}
+		return true
+	})
+	return findings
+}
+
+// commandFindings normalizes one invocation, descending into wrappers and
+// nested shells. A program chosen by a variable or substitution cannot be
+// checked and needs approval.
+func commandFindings(words, redirects []string, text, dir string, depth int) []finding {
+	words, stdinArgs := unwrapCommand(words)
+	if len(words) == 0 {
+		return nil
+	}
+	if isDynamic(words[0]) {
+		return []finding{{rule: ruleDynamicProgram, act: RuleAsk, text: text}}
+	}
+	program := programName(words[0])
+	args := words[1:]
+
+	switch program {
+	case "sh", "bash", "dash", "zsh", "ksh":
+		if script, ok := shellScript(args); ok {
+			return analyzeCommand(script, dir, depth+1)
+		}
+	case "su", "runuser":
+		if script, ok := suScript(args); ok {
+			return analyzeCommand(script, dir, depth+1)
+		}
+	case "eval":
+		return analyzeCommand(strings.Join(args, " "), dir, depth+1)
+	case "watch":
+		// watch joins its operands and runs them with sh -c
+		return analyzeCommand(strings.Join(wrapperOperands(program, args), " "), dir, depth+1)
+	}
+
+	cmd := &simpleCommand{
+		program:     program,
+		flags:       make(map[string]bool),
+		redirects:   redirects,
+		unknownArgs: stdinArgs,
+		dir:         dir,
+		text:        text,
+	}
+	endOfFlags := false
+	for _, arg := range args {
+		switch {
+		case endOfFlags || !strings.HasPrefix(arg, "-") || arg == "-":
+			normalized, known := normalizeArg(arg)
+			cmd.positional = append(cmd.positional, normalized)
+			if !known {
+				cmd.unknownArgs = true
+			}
+		case arg == "--":
+			endOfFlags = true
+		case strings.HasPrefix(arg, "--"):
+			name, _, _ := strings.Cut(arg[2:], "=")
+			cmd.flags[name] = true
+		default:
+			for _, ch := range arg[1:] {
+				cmd.flags[string(ch)] = true
+			}
+		}
+	}
+	return []finding{{cmd: cmd}}
+}
+
+// programName returns the name a command word runs. Backslashes separate
+// directories in Windows paths and are otherwise shell escapes, so r\m is rm.
+func programName(word string) string {
+	if strings.ContainsAny(word, "/:") {
+		return path.Base(strings.ReplaceAll(word, `\`, "/"))
+	}
+	return strings.ReplaceAll(word, `\`, "")
+}
+
+// isDynamic reports whether a word is only known when the shell expands it
+func isDynamic(word string) bool {
+	return strings.ContainsAny(word, "$`")
+}
+
+// isRelative reports whether an argument names a path below the working directory
+func isRelative(arg string) bool {
+	return arg != "" && !strings.HasPrefix(arg, "/") && !strings.HasPrefix(arg, "~") && !isDynamic(arg)
+}
+
+// cdTarget returns the directory a cd or pushd moves to. The result is empty
+// when it depends on something the command line does not show, such as the
+// previous directory or a variable other than HOME.
+func cdTarget(words []string, dir string) (string, bool) {
+	if len(words) == 0 {
+		return "", false
+	}
+	if name := programName(words[0]); name != "cd" && name != "pushd" {
+		return "", false
+	}
+	target := "~"
+	for _, arg := range words[1:] {
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			target = arg
+			break
+		}
+	}
+	target, known := normalizeArg(target)
+	switch {
+	case !known || target == "-":
+		return "", true
+	case isRelative(target):
+		if dir == "" {
+			return "", true
+		}
+		return path.Join(dir, target), true
+	case isDynamic(target) && target != "$HOME" && target != "${HOME}":
+		return "", true
+	}
+	return target, true
+}
+
+// shellScript finds the script a shell runs with -c. The flag may be part of
+// a cluster such as -lc or -ec, and the script is the first operand after it.
+func shellScript(args []string) (string, bool) {
+	command := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-o" || arg == "+o" || arg == "-O" || arg == "+O":
+			i++
+		case strings.HasPrefix(arg, "--"):
+		case len(arg) > 1 && (arg[0] == '-' || arg[0] == '+'):
+			if arg[0] == '-' && strings.Contains(arg[1:], "c") {
+				command = true
+			}
+		default:
+			return arg, command
+		}
+	}
+	return "", false
+}
+
+// suScript finds the command su or runuser passes to the target user's
+// shell. -c may end a cluster such as -lc, and the user may come first.
+func suScript(args []string) (string, bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if script, ok := strings.CutPrefix(arg, "--command="); ok {
+			return script, true
+		}
+		if arg == "--command" && i+1 < len(args) {
+			return args[i+1], true
+		}
+		if len(arg) < 2 || arg[0] != '-' || arg[1] == '-' {
+			continue
+		}
+	cluster:
+		for j := 1; j < len(arg); j++ {
+			switch arg[j] {
+			case 'c':
+				if j+1 < len(arg) {
+					return arg[j+1:], true
+				}
+				if i+1 < len(args) {
+					return args[i+1], true
+				}
+				return "", false
+			case 's', 'g', 'G', 'w':
+				// the rest of the cluster or the next argument is the value
+				if j+1 == len(arg) {
+					i++
+				}
+				break cluster
+			}
+		}
+	}
+	return "", false
+}
+
+// wrapperValueFlags lists options of wrapper programs that consume a value
+var wrapperValueFlags = map[string]map[string]bool{
+	"sudo":    {"-u": true, "-g": true, "-C": true, "-D": true, "-h": true, "-p": true, "-r": true, "-t": true, "-U": true},
+	"doas":    {"-u": true, "-C": true},
+	"env":     {"-u": true, "-C": true, "-S": true},
+	"nice":    {"-n": true},
+	"ionice":  {"-c": true, "-n": true, "-p": true},
+	"timeout": {"-s": true, "-k": true},
+	"xargs":   {"-I": true, "-L": true, "-n": true, "-P": true, "-s": true, "-d": true, "-E": true, "-a": true},
+	"stdbuf":  {"-i": true, "-o": true, "-e": true},
+	"watch":   {"-n": true, "--interval": true, "-q": true, "--equexit": true},
+}
+
+// unwrapCommand strips wrappers that run another program and reports
+// whether xargs appends arguments from stdin
+func unwrapCommand(words []string) ([]string, bool) {
+	stdinArgs := false
+	for len(words) > 0 {
+		name := programName(words[0])
+		switch name {
+		case "sudo", "doas", "env", "nice", "ionice", "nohup", "time", "timeout", "xargs", "stdbuf",
+			"command", "builtin", "exec", "busybox", "chroot", "setsid":
+		default:
+			return words, stdinArgs
+		}
+		if name == "xargs" {
+			stdinArgs = true
+		}
+
+		words = wrapperOperands(name, words[1:])
+	}
+	return words, stdinArgs
+}
+
+// wrapperOperands skips the options of a wrapper and returns the command it runs
+func wrapperOperands(name string, rest []string) []string {
+	valueFlags := wrapperValueFlags[name]
+	for len(rest) > 0 {
+		arg := rest[0]
+		if arg == "--" {
+			rest = rest[1:]
+			break
+		}
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			rest = rest[1:]
+			if valueFlags[arg] && len(rest) > 0 {
+				rest = rest[1:]
+			}
+			continue
+		}
+		if name == "env" && strings.Contains(arg, "=") {
+			rest = rest[1:]
+			continue
+		}
+		break
+	}
+	switch name {
+	case "timeout", "chroot":
+		// first positional is the duration or new root
+		if len(rest) > 0 {
+			rest = rest[1:]
+		}
+	}
+	return rest
+}
+
+// normalizeArg cleans path-like arguments so "//", "/." and "/tmp/.." compare
+// equal, and "$HOME/" is the same as "$HOME". A ".." after a variable climbs
+// out of a directory the command line does not show, so such an argument is
+// reported as unknown.
+func normalizeArg(arg string) (string, bool) {
+	if isDynamic(arg) && slices.Contains(strings.Split(arg, "/"), "..") {
+		return arg, false
+	}
+	glob := strings.ContainsAny(arg, "*?[")
+	if strings.HasPrefix(arg, "/") && !glob {
+		return path.Clean(arg), true
+	}
+	if strings.HasPrefix(arg, "$") && !glob {
+		return path.Clean(arg), true
+	}
+	if strings.HasPrefix(arg, "~") && strings.Trim(arg, "/~.") == "" {
+		return "~", true
+	}
+	return arg, true
+}
+
+// wordValue returns the unquoted value of a word; dynamic parts keep their source text
+func wordValue(w *syntax.Word) string {
+	var b strings.Builder
+	for _, part := range w.Parts {
+		writeWordPart(&b, part)
+	}
+	return b.String()
+}
+
+func writeWordPart(b *strings.Builder, part syntax.WordPart) {
+	switch p := part.(type) {
+	case *syntax.Lit:
+		b.WriteString(p.Value)
+	case *syntax.SglQuoted:
+		b.WriteString(p.Value)
+	case *syntax.DblQuoted:
+		for _, inner := range p.Parts {
+			writeWordPart(b, inner)
+		}
+	default:
+		b.WriteString(printNode(part))
+	}
+}
+
+func outputRedirects(redirs []*syntax.Redirect) []string {
+	var targets []string
+	for _, r := range redirs {
+		switch r.Op {
+		case syntax.RdrOut, syntax.AppOut, syntax.RdrAll, syntax.AppAll, syntax.ClbOut, syntax.RdrInOut:
+			if r.Word != nil {
+				target, _ := normalizeArg(wordValue(r.Word))
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+func printNode(node syntax.Node) string {
+	var b strings.Builder
+	if err := syntax.NewPrinter(syntax.SingleLine(true)).Print(&b, node); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// selfCalls counts how often a function invokes itself
+func selfCalls(fn *syntax.FuncDecl) int {
+	count := 0
+	syntax.Walk(fn.Body, func(node syntax.Node) bool {
+		if call, ok := node.(*syntax.CallExpr); ok && len(call.Args) > 0 {
+			if wordValue(call.Args[0]) == fn.Name.Value {
+				count++
+			}
+		}
+		return true
+	})
+	return count
+}
+
+var downloaders = map[string]bool{"curl": true, "wget": true, "fetch": true}
+var interpreters = map[string]bool{"sh": true, "bash": true, "dash": true, "zsh": true, "ksh": true, "python": true, "python3": true, "perl": true, "ruby": true, "node": true}
+
+// pipesToShell detects downloads piped straight into an interpreter
+func pipesToShell(pipe *syntax.BinaryCmd) bool {
+	var stages []string
+	var collect func(s *syntax.Stmt)
+	collect = func(s *syntax.Stmt) {
+		if b, ok := s.Cmd.(*syntax.BinaryCmd); ok && (b.Op == syntax.Pipe || b.Op == syntax.PipeAll) {
+			collect(b.X)
+			collect(b.Y)
+			return
+		}
+		if call, ok := s.Cmd.(*syntax.CallExpr); ok && len(call.Args) > 0 {
+			words := make([]string, 0, len(call.Args))
+			for _, w := range call.Args {
+				words = append(words, wordValue(w))
+			}
+			if words, _ = unwrapCommand(words); len(words) > 0 {
+				stages = append(stages, programName(words[0]))
+				return
+			}
+		}
+		stages = append(stages, "")
+	}
+	collect(pipe.X)
+	collect(pipe.Y)
+
+	downloaded := false
+	for _, s := range stages {
+		if downloaders[s] {
+			downloaded = true
+		} else if downloaded && interpreters[s] {
+			return true
+		}
+	}
+	return false
+}
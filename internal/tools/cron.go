@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/cron"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// ScheduleTaskInput parameters for schedule_task tool
+type ScheduleTaskInput struct {
+	Message  string `json:"message" jsonschema:"required,description=What to do or say when the task fires, written as an instruction to yourself"`
+	Name     string `json:"name" jsonschema:"description=Short label for the task"`
+	Cron     string `json:"cron" jsonschema:"description=Repeating schedule as a 5-field cron expression, e.g. '0 9 * * 1-5' for weekdays at 9:00"`
+	At       string `json:"at" jsonschema:"description=One-shot time, RFC 3339 or 'YYYY-MM-DD HH:MM'"`
+	In       string `json:"in" jsonschema:"description=One-shot delay from now as a Go duration, e.g. '20m' or '2h'"`
+	Every    string `json:"every" jsonschema:"description=Fixed repeat interval as a Go duration, e.g. '30m'"`
+	Timezone string `json:"timezone" jsonschema:"description=IANA timezone for cron and at, e.g. Europe/Lisbon"`
+}
+
+// ScheduledTask describes a task in tool output
+type ScheduledTask struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Message  string `json:"message"`
+	Schedule string `json:"schedule"`
+	NextRun  string `json:"next_run"`
+	LastRun  string `json:"last_run,omitempty"`
+}
+
+// ScheduleTaskOutput result of schedule_task tool
+type ScheduleTaskOutput struct {
+	Task ScheduledTask `json:"task"`
+	Now  string        `json:"now"`
+}
+
+// ListTasksInput parameters for list_tasks tool
+type ListTasksInput struct{}
+
+// ListTasksOutput result of list_tasks tool
+type ListTasksOutput struct {
+	Tasks []ScheduledTask `json:"tasks"`
+}
+
+// CancelTaskInput parameters for cancel_task tool
+type CancelTaskInput struct {
+	ID string `json:"id" jsonschema:"required,description=Task id from schedule_task or list_tasks"`
+}
+
+// CancelTaskOutput result of cancel_task tool
+type CancelTaskOutput struct {
+	Cancelled ScheduledTask `json:"cancelled"`
+}
+
+func scheduledTask(j cron.Job) ScheduledTask {
+	t := ScheduledTask{
+		ID:       j.ID,
+		Name:     j.Name,
+		Message:  j.Message,
+		Schedule: j.Describe(),
+		NextRun:  j.NextRun.Format(time.RFC3339),
+	}
+	if !j.LastRun.IsZero() {
+		t.LastRun = j.LastRun.Format(time.RFC3339)
+	}
+	return t
+}
+
+// NewCronTools creates schedule_task, list_tasks and cancel_task. Tasks are
+// delivered to the conversation that scheduled them and can only be listed
+// and cancelled from there. timezone applies when a task names none.
+// channels lists the channels the scheduler can deliver to; conversations on
+// any other channel, such as golem chat, cannot schedule tasks.
+func NewCronTools(store *cron.Store, timezone string, channels []string) ([]tool.InvokableTool, error) {
+	schedule := func(ctx context.Context, input *ScheduleTaskInput) (*ScheduleTaskOutput, error) {
+		inv, ok := InvocationFromContext(ctx)
+		if !ok {
+			return nil, fmt.Errorf("scheduling needs a conversation to deliver to")
+		}
+		if !slices.Contains(channels, inv.Channel) {
+			if len(channels) == 0 {
+				return nil, fmt.Errorf("scheduled tasks are delivered through a chat channel and none is enabled")
+			}
+			return nil, fmt.Errorf("tasks scheduled from %q could not be delivered; schedule them from %s", inv.Channel, strings.Join(channels, ", "))
+		}
+		job := cron.Job{
+			Name:     strings.TrimSpace(input.Name),
+			Message:  input.Message,
+			Cron:     input.Cron,
+			At:       input.At,
+			Every:    input.Every,
+			Timezone: input.Timezone,
+			Channel:  inv.Channel,
+			ChatID:   inv.ChatID,
+			SenderID: inv.SenderID,
+			Agent:    inv.Agent,
+		}
+		if job.Timezone == "" {
+			job.Timezone = timezone
+		}
+		if in := strings.TrimSpace(input.In); in != "" {
+			if job.At != "" {
+				return nil, fmt.Errorf("set either at or in, not both")
+			}
+			at, err := cron.AtDelay(in, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			job.At = at
+		}
+		added, err := store.Add(job)
+		if err != nil {
+			return nil, err
+		}
+		return &ScheduleTaskOutput{Task: scheduledTask(added), Now: time.Now().Format(time.RFC3339)}, nil
+	}
+
+	list := func(ctx context.Context, input *ListTasksInput) (*ListTasksOutput, error) {
+		inv, _ := InvocationFromContext(ctx)
+		jobs, err := store.List()
+		if err != nil {
+			return nil, err
+		}
+		out := &ListTasksOutput{Tasks: []ScheduledTask{}}
+		for _, j := range jobs {
+			if j.SessionKey() == inv.SessionKey() {
+				out.Tasks = append(out.Tasks, scheduledTask(j))
+			}
+		}
+		return out, nil
+	}
+
+	cancel := func(ctx context.Context, input *CancelTaskInput) (*CancelTaskOutput, error) {
+		inv, _ := InvocationFromContext(ctx)
+		job, err := store.Remove(input.ID, inv.SessionKey())
+		if err != nil {
+			return nil, err
+		}
+		return &CancelTaskOutput{Cancelled: scheduledTask(job)}, nil
+	}
+
+	scheduleTool, err := utils.InferTool("schedule_task",
+		"Schedule a reminder or task that runs later in this conversation: repeating with cron or every, or once with at or in. Set exactly one.", schedule)
+	if err != nil {
+		return nil, err
+	}
+	listTool, err := utils.InferTool("list_tasks", "List the scheduled tasks of this conversation", list)
+	if err != nil {
+		return nil, err
+	}
+	cancelTool, err := utils.InferTool("cancel_task", "Cancel a scheduled task of this conversation", cancel)
+	if err != nil {
+		return nil, err
+	}
+	return []tool.InvokableTool{scheduleTool, listTool, cancelTool}, nil
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/cron"
+	"github.com/cloudwego/eino/components/tool"
+)
+
+func TestCronTools_ScopedToConversation(t *testing.T) {
+	store := cron.NewStore(t.TempDir())
+	cronTools, err := NewCronTools(store, "UTC", []string{"telegram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduleTool, listTool, cancelTool := cronTools[0], cronTools[1], cronTools[2]
+	alice := WithInvocation(context.Background(), Invocation{Channel: "telegram", ChatID: "1", SenderID: "a", Agent: "home"})
+	bob := WithInvocation(context.Background(), Invocation{Channel: "telegram", ChatID: "2", SenderID: "b"})
+	run := func(ctx context.Context, tl tool.InvokableTool, input, out any) error {
+		t.Helper()
+		args, _ := json.Marshal(input)
+		result, err := tl.InvokableRun(ctx, string(args))
+		if err != nil || out == nil {
+			return err
+		}
+		return json.Unmarshal([]byte(result), out)
+	}
+
+	var weekday ScheduleTaskOutput
+	if err := run(alice, scheduleTool, ScheduleTaskInput{Message: "Send the stand-up agenda", Cron: "0 9 * * 1-5"}, &weekday); err != nil {
+		t.Fatal(err)
+	}
+	if weekday.Task.Schedule != "cron 0 9 * * 1-5 (UTC)" {
+		t.Fatalf("expected default timezone, got %+v", weekday.Task)
+	}
+	var soon ScheduleTaskOutput
+	if err := run(alice, scheduleTool, ScheduleTaskInput{Message: "Check the oven", In: "20m"}, &soon); err != nil {
+		t.Fatal(err)
+	}
+	next, _ := time.Parse(time.RFC3339, soon.Task.NextRun)
+	if d := time.Until(next); d < 19*time.Minute || d > 21*time.Minute {
+		t.Fatalf("expected a run in about 20m, got %s", soon.Task.NextRun)
+	}
+	if err := run(alice, scheduleTool, ScheduleTaskInput{Message: "x", In: "5m", At: "2030-01-01 10:00"}, nil); err == nil {
+		t.Fatal("expected at and in together to be rejected")
+	}
+	if err := run(context.Background(), scheduleTool, ScheduleTaskInput{Message: "x", Every: "1h"}, nil); err == nil {
+		t.Fatal("expected scheduling without a conversation to fail")
+	}
+	cli := WithInvocation(context.Background(), Invocation{Channel: "cli", ChatID: "direct"})
+	if err := run(cli, scheduleTool, ScheduleTaskInput{Message: "x", In: "5m"}, nil); err == nil || !strings.Contains(err.Error(), "telegram") {
+		t.Fatalf("expected scheduling from a channel without delivery to fail, got %v", err)
+	}
+
+	jobs, _ := store.List()
+	if len(jobs) != 2 || jobs[0].SessionKey() != "telegram:1" || jobs[0].SenderID != "a" || jobs[0].Agent != "home" {
+		t.Fatalf("unexpected stored jobs %+v", jobs)
+	}
+
+	var listed ListTasksOutput
+	if err := run(bob, listTool, ListTasksInput{}, &listed); err != nil || len(listed.Tasks) != 0 {
+		t.Fatalf("expected another chat to see no tasks, got %+v, %v", listed, err)
+	}
+	if err := run(bob, cancelTool, CancelTaskInput{ID: weekday.Task.ID}, nil); err == nil {
+		t.Fatal("expected another chat to be unable to cancel")
+	}
+	var cancelled CancelTaskOutput
+	if err := run(alice, cancelTool, CancelTaskInput{ID: weekday.Task.ID}, &cancelled); err != nil || cancelled.Cancelled.ID != weekday.Task.ID {
+		t.Fatalf("cancel failed: %+v, %v", cancelled, err)
+	}
+	if err := run(alice, listTool, ListTasksInput{}, &listed); err != nil || len(listed.Tasks) != 1 || listed.Tasks[0].ID != soon.Task.ID {
+		t.Fatalf("expected only the oven reminder left, got %+v, %v", listed, err)
+	}
+}
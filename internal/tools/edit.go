@@ -0,0 +1,306 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// TextEdit replaces OldText with NewText
+type TextEdit struct {
+	OldText    string `json:"old_text" jsonschema:"required,description=Exact text to replace; include enough context to be unique"`
+	NewText    string `json:"new_text" jsonschema:"description=Replacement text"`
+	ReplaceAll bool   `json:"replace_all" jsonschema:"description=Replace every occurrence instead of requiring a unique match"`
+}
+
+// EditFileInput parameters for edit_file tool
+type EditFileInput struct {
+	Path  string     `json:"path" jsonschema:"required,description=Path to the file"`
+	Edits []TextEdit `json:"edits" jsonschema:"description=Search/replace edits applied in order"`
+	Patch string     `json:"patch" jsonschema:"description=Unified diff to apply instead of edits"`
+}
+
+// EditFileOutput result of edit_file tool
+type EditFileOutput struct {
+	Path string `json:"path"`
+	Diff string `json:"diff"`
+}
+
+// NewEditFileTool creates the edit_file tool. All edits or hunks must apply
+// or the file is left untouched.
+func NewEditFileTool(workspacePath string) (tool.InvokableTool, error) {
+	run := func(ctx context.Context, input *EditFileInput) (*EditFileOutput, error) {
+		path, err := validatePath(workspacePath, input.Path)
+		if err != nil {
+			return nil, err
+		}
+		if (len(input.Edits) == 0) == (input.Patch == "") {
+			return nil, fmt.Errorf("provide either edits or patch")
+		}
+
+		data, err := readFileNoFollow(path)
+		if err != nil {
+			return nil, err
+		}
+		original := string(data)
+
+		var updated string
+		if input.Patch != "" {
+			updated, err = applyUnifiedPatch(original, input.Patch)
+		} else {
+			updated, err = applyTextEdits(original, input.Edits)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := input.Path
+		if workspacePath != "" {
+			name = displayPath(workspacePath, path)
+		}
+		if updated == original {
+			return &EditFileOutput{Path: name, Diff: ""}, nil
+		}
+		if err := writeFileAtomic(path, []byte(updated)); err != nil {
+			return nil, err
+		}
+		return &EditFileOutput{Path: name, Diff: unifiedDiff(name, original, updated)}, nil
+	}
+	return utils.InferTool("edit_file",
+		"Edit a file with exact search/replace edits or a unified diff; returns the resulting diff", run)
+}
+
+// applyTextEdits applies edits in order; each must match exactly once unless ReplaceAll is set
+func applyTextEdits(content string, edits []TextEdit) (string, error) {
+	crlf := strings.Contains(content, "\r\n")
+	for i, e := range edits {
+		oldText, newText := e.OldText, e.NewText
+		if oldText == "" {
+			return "", fmt.Errorf("edit %d: old_text is empty", i+1)
+		}
+		if crlf && !strings.Contains(oldText, "\r\n") {
+			oldText = strings.ReplaceAll(oldText, "\n", "\r\n")
+			newText = strings.ReplaceAll(newText, "\n", "\r\n")
+		}
+
+		switch n := strings.Count(content, oldText); {
+		case n == 0:
+			return "", fmt.Errorf("edit %d: old_text not found", i+1)
+		case n > 1 && !e.ReplaceAll:
+			return "", fmt.Errorf("edit %d: old_text matches %d times; add surrounding context or set replace_all", i+1, n)
+		case e.ReplaceAll:
+			content = strings.ReplaceAll(content, oldText, newText)
+		default:
+			content = strings.Replace(content, oldText, newText, 1)
+		}
+	}
+	return content, nil
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+type patchHunk struct {
+	oldStart int
+	lines    []diffOp
+}
+
+// parseUnifiedPatch reads the hunks of a single-file diff. Header counts
+// decide where hunk bodies end, but lines past a miscounted hunk are still
+// accepted since hand-written patches often get the counts wrong.
+func parseUnifiedPatch(patch string) ([]patchHunk, error) {
+	var hunks []patchHunk
+	files, remaining := 0, 0
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		inBody := remaining > 0
+		switch {
+		case !inBody && strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			files++
+			if files > 1 {
+				return nil, fmt.Errorf("patch modifies more than one file")
+			}
+			i++
+		case !inBody && strings.HasPrefix(line, "@@"):
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("invalid hunk header %q", line)
+			}
+			oldStart, _ := strconv.Atoi(m[1])
+			remaining = hunkCount(m[2]) + hunkCount(m[4])
+			hunks = append(hunks, patchHunk{oldStart: oldStart})
+		case len(hunks) == 0:
+			// preamble such as "diff --git" lines
+		case line == `\ No newline at end of file`:
+			h := &hunks[len(hunks)-1]
+			if len(h.lines) > 0 {
+				last := &h.lines[len(h.lines)-1]
+				last.line = strings.TrimSuffix(last.line, "\n")
+			}
+		case line == "" && i == len(lines)-1:
+		case line == "":
+			// editors often strip the space of empty context lines
+			h := &hunks[len(hunks)-1]
+			h.lines = append(h.lines, diffOp{' ', "\n"})
+			remaining -= 2
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			h := &hunks[len(hunks)-1]
+			h.lines = append(h.lines, diffOp{line[0], line[1:] + "\n"})
+			if line[0] == ' ' {
+				remaining -= 2
+			} else {
+				remaining--
+			}
+		default:
+			return nil, fmt.Errorf("unexpected patch line %q", line)
+		}
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("patch contains no hunks")
+	}
+	return hunks, nil
+}
+
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// applyUnifiedPatch applies every hunk or none. Hunks are located by exact
+// content, preferring the position closest to the header line number.
+func applyUnifiedPatch(content, patch string) (string, error) {
+	hunks, err := parseUnifiedPatch(patch)
+	if err != nil {
+		return "", err
+	}
+
+	crlf := strings.Contains(content, "\r\n")
+	missingNewline := content != "" && !strings.HasSuffix(content, "\n")
+	lines := splitLines(strings.ReplaceAll(content, "\r\n", "\n"))
+	if missingNewline {
+		lines[len(lines)-1] += "\n"
+	}
+
+	var out []string
+	pos, offset := 0, 0
+	for i, h := range hunks {
+		var oldLines, newLines []string
+		for _, op := range h.lines {
+			line := op.line
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n"
+			}
+			if op.kind != '+' {
+				oldLines = append(oldLines, line)
+			}
+			if op.kind != '-' {
+				newLines = append(newLines, op.line)
+			}
+		}
+
+		at := findLines(lines, oldLines, pos, h.oldStart-1+offset)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (@@ -%d) does not apply; file left unchanged", i+1, h.oldStart)
+		}
+		out = append(out, lines[pos:at]...)
+		out = append(out, newLines...)
+		pos = at + len(oldLines)
+		offset += len(newLines) - len(oldLines)
+	}
+	out = append(out, lines[pos:]...)
+
+	result := strings.Join(out, "")
+	// keep a missing final newline unless the patch spelled one out
+	if missingNewline && lastLineUntouched(hunks) {
+		result = strings.TrimSuffix(result, "\n")
+	}
+	if crlf {
+		result = strings.ReplaceAll(result, "\n", "\r\n")
+	}
+	return result, nil
+}
+
+// lastLineUntouched reports whether no hunk mentions the end-of-file newline
+func lastLineUntouched(hunks []patchHunk) bool {
+	for _, h := range hunks {
+		for _, op := range h.lines {
+			if !strings.HasSuffix(op.line, "\n") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// findLines returns the index of want in lines at or after from, closest to hint
+func findLines(lines, want []string, from, hint int) int {
+	matches := func(at int) bool {
+		if at < from || at+len(want) > len(lines) {
+			return false
+		}
+		for i, l := range want {
+			if lines[at+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+	hint = max(hint, from)
+	for d := 0; hint-d >= from || hint+d <= len(lines); d++ {
+		if matches(hint + d) {
+			return hint + d
+		}
+		if d > 0 && matches(hint-d) {
+			return hint - d
+		}
+	}
+	return -1
+}
+
+// readFileNoFollow reads a resolved path with openNoFollow
+func readFileNoFollow(path string) ([]byte, error) {
+	f, err := openNoFollow(path, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// writeFileAtomic replaces path via a temporary file, keeping its mode
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
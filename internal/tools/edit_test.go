@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runEditFile(t *testing.T, ws string, input EditFileInput) (*EditFileOutput, error) {
+	t.Helper()
+	editTool, err := NewEditFileTool(ws)
+	if err != nil {
+		t.Fatalf("NewEditFileTool error: %v", err)
+	}
+	args, _ := json.Marshal(input)
+	out, err := editTool.InvokableRun(context.Background(), string(args))
+	if err != nil {
+		return nil, err
+	}
+	var result EditFileOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid output %q: %v", out, err)
+	}
+	return &result, nil
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+const editSample = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("hello")
+	fmt.Println("hello")
+}
+`
+
+func TestEditFile_SearchReplace(t *testing.T) {
+	ws := t.TempDir()
+	path := filepath.Join(ws, "main.go")
+	writeTestFile(t, path, editSample)
+
+	out, err := runEditFile(t, ws, EditFileInput{
+		Path:  "main.go",
+		Edits: []TextEdit{{OldText: `import "fmt"`, NewText: "import (\n\t\"fmt\"\n\t\"os\"\n)"}},
+	})
+	if err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if !strings.Contains(readTestFile(t, path), "\t\"os\"\n") {
+		t.Fatalf("edit not applied:\n%s", readTestFile(t, path))
+	}
+	wantDiff := "--- a/main.go\n+++ b/main.go\n@@ -1,6 +1,9 @@\n package main\n \n-import \"fmt\"\n+import (\n+\t\"fmt\"\n+\t\"os\"\n+)\n \n func main() {\n \tfmt.Println(\"hello\")\n"
+	if out.Diff != wantDiff {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", out.Diff, wantDiff)
+	}
+}
+
+func TestEditFile_UniquenessAndAtomicity(t *testing.T) {
+	ws := t.TempDir()
+	path := filepath.Join(ws, "main.go")
+	writeTestFile(t, path, editSample)
+
+	_, err := runEditFile(t, ws, EditFileInput{
+		Path:  "main.go",
+		Edits: []TextEdit{{OldText: `fmt.Println("hello")`, NewText: `fmt.Println("bye")`}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "matches 2 times") {
+		t.Fatalf("expected ambiguity error, got %v", err)
+	}
+
+	_, err = runEditFile(t, ws, EditFileInput{
+		Path: "main.go",
+		Edits: []TextEdit{
+			{OldText: "package main", NewText: "package app"},
+			{OldText: "missing", NewText: "x"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "edit 2: old_text not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if readTestFile(t, path) != editSample {
+		t.Fatal("failed edits must leave the file untouched")
+	}
+
+	if _, err := runEditFile(t, ws, EditFileInput{
+		Path:  "main.go",
+		Edits: []TextEdit{{OldText: `"hello"`, NewText: `"bye"`, ReplaceAll: true}},
+	}); err != nil {
+		t.Fatalf("replace_all failed: %v", err)
+	}
+	if strings.Count(readTestFile(t, path), `"bye"`) != 2 {
+		t.Fatalf("expected both occurrences replaced:\n%s", readTestFile(t, path))
+	}
+}
+
+func TestEditFile_PreservesCRLF(t *testing.T) {
+	ws := t.TempDir()
+	path := filepath.Join(ws, "win.txt")
+	writeTestFile(t, path, "one\r\ntwo\r\nthree\r\n")
+
+	if _, err := runEditFile(t, ws, EditFileInput{
+		Path:  "win.txt",
+		Edits: []TextEdit{{OldText: "one\ntwo", NewText: "one\n2"}},
+	}); err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if got := readTestFile(t, path); got != "one\r\n2\r\nthree\r\n" {
+		t.Fatalf("unexpected content %q", got)
+	}
+}
+
+func TestEditFile_UnifiedPatch(t *testing.T) {
+	ws := t.TempDir()
+	path := filepath.Join(ws, "main.go")
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, "line "+string(rune('a'+i%26)))
+	}
+	original := strings.Join(lines, "\n") + "\n"
+	writeTestFile(t, path, "// header added later\n"+original)
+
+	// Line numbers are off by one; hunks are located by content
+	patch := `--- a/main.go
++++ b/main.go
+@@ -2,3 +2,3 @@
+ line c
+-line d
++line D
+ line e
+@@ -27,4 +27,3 @@
+ line b
+ line c
+-line d
+ line e
+`
+	out, err := runEditFile(t, ws, EditFileInput{Path: "main.go", Patch: patch})
+	if err != nil {
+		t.Fatalf("patch failed: %v", err)
+	}
+	got := readTestFile(t, path)
+	if strings.Count(got, "line D\n") != 1 || strings.Count(got, "line d\n") != 0 || strings.Count(got, "\n") != 30 {
+		t.Fatalf("unexpected content:\n%s", got)
+	}
+	if !strings.Contains(out.Diff, "-line d\n+line D\n") {
+		t.Fatalf("expected diff of change, got:\n%s", out.Diff)
+	}
+}
+
+func TestEditFile_PatchFailsAtomically(t *testing.T) {
+	ws := t.TempDir()
+	path := filepath.Join(ws, "a.txt")
+	writeTestFile(t, path, "alpha\nbeta\ngamma\n")
+
+	patch := "@@ -1,2 +1,2 @@\n-alpha\n+ALPHA\n beta\n@@ -3 +3 @@\n-delta\n+DELTA\n"
+	_, err := runEditFile(t, ws, EditFileInput{Path: "a.txt", Patch: patch})
+	if err == nil || !strings.Contains(err.Error(), "hunk 2") {
+		t.Fatalf("expected hunk 2 to fail, got %v", err)
+	}
+	if readTestFile(t, path) != "alpha\nbeta\ngamma\n" {
+		t.Fatal("failed patch must leave the file untouched")
+	}
+}
+
+func TestEditFile_PatchNoNewlineAtEnd(t *testing.T) {
+	ws := t.TempDir()
+	path := filepath.Join(ws, "a.txt")
+	writeTestFile(t, path, "one\ntwo")
+
+	if _, err := runEditFile(t, ws, EditFileInput{Path: "a.txt", Patch: "@@ -1 +1 @@\n-one\n+ONE\n"}); err != nil {
+		t.Fatalf("patch failed: %v", err)
+	}
+	if got := readTestFile(t, path); got != "ONE\ntwo" {
+		t.Fatalf("expected missing final newline preserved, got %q", got)
+	}
+
+	patch := "@@ -1,2 +1,2 @@\n ONE\n-two\n\\ No newline at end of file\n+two\n"
+	out, err := runEditFile(t, ws, EditFileInput{Path: "a.txt", Patch: patch})
+	if err != nil {
+		t.Fatalf("patch failed: %v", err)
+	}
+	if got := readTestFile(t, path); got != "ONE\ntwo\n" {
+		t.Fatalf("expected final newline added, got %q", got)
+	}
+	if !strings.Contains(out.Diff, "\\ No newline at end of file") {
+		t.Fatalf("expected diff to mention the newline, got:\n%s", out.Diff)
+	}
+}
+
+func TestEditFile_ValidatesPathAndInput(t *testing.T) {
+	ws := t.TempDir()
+	writeTestFile(t, filepath.Join(ws, "a.txt"), "x\n")
+
+	if _, err := runEditFile(t, ws, EditFileInput{
+		Path:  "../outside.txt",
+		Edits: []TextEdit{{OldText: "x", NewText: "y"}},
+	}); err == nil || !strings.Contains(err.Error(), "outside workspace") {
+		t.Fatalf("expected path to be rejected, got %v", err)
+	}
+	if _, err := runEditFile(t, ws, EditFileInput{Path: "a.txt"}); err == nil {
+		t.Fatal("expected error without edits or patch")
+	}
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 40; i++ {
+		line := strings.Repeat("x", i%5) + "\n"
+		a = append(a, line)
+		if i == 5 || i == 30 {
+			line = "changed\n"
+		}
+		b = append(b, line)
+	}
+	diff := unifiedDiff("f", strings.Join(a, ""), strings.Join(b, ""))
+	if strings.Count(diff, "@@ ") != 2 || !strings.Contains(diff, "@@ -3,7 +3,7 @@") || !strings.Contains(diff, "@@ -28,7 +28,7 @@") {
+		t.Fatalf("unexpected hunks:\n%s", diff)
+	}
+}
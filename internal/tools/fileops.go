@@ -0,0 +1,321 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// trashDirName is the workspace directory that receives deleted files
+const trashDirName = ".trash"
+
+// TransferInput parameters for move_file and copy_file tools
+type TransferInput struct {
+	Source      string `json:"source" jsonschema:"required,description=File or directory to move or copy"`
+	Destination string `json:"destination" jsonschema:"required,description=Target path including the new name"`
+	Overwrite   bool   `json:"overwrite" jsonschema:"description=Replace an existing destination file"`
+}
+
+// PathInput identifies a file or directory
+type PathInput struct {
+	Path string `json:"path" jsonschema:"required,description=File or directory path"`
+}
+
+// FileOpOutput result of file management tools
+type FileOpOutput struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// DeleteFileOutput result of delete_file tool
+type DeleteFileOutput struct {
+	Path    string `json:"path"`
+	Trashed string `json:"trashed"`
+}
+
+// StatOutput result of stat tool
+type StatOutput struct {
+	Path       string    `json:"path"`
+	Type       string    `json:"type"`
+	Size       int64     `json:"size"`
+	Mode       string    `json:"mode"`
+	ModTime    time.Time `json:"mod_time"`
+	LinkTarget string    `json:"link_target,omitempty"`
+	Entries    int       `json:"entries,omitempty"`
+}
+
+// displayPath shows paths relative to the workspace when possible
+func displayPath(workspacePath, path string) string {
+	if workspacePath == "" {
+		return path
+	}
+	root, err := resolveWorkspace(workspacePath)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// resolveTransfer validates both ends of a move or copy. The source is
+// checked with validateSource, since a move acts on a symlink itself while a
+// copy reads what it points to; the destination link is always replaced.
+func resolveTransfer(workspacePath string, input *TransferInput, validateSource func(string, string) (string, error)) (string, string, error) {
+	src, err := validateSource(workspacePath, input.Source)
+	if err != nil {
+		return "", "", err
+	}
+	dst, err := validateLinkPath(workspacePath, input.Destination)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := os.Lstat(src); err != nil {
+		return "", "", err
+	}
+	if src == dst {
+		return "", "", fmt.Errorf("source and destination are the same")
+	}
+	if rel, err := filepath.Rel(src, dst); err == nil && !strings.HasPrefix(rel, "..") {
+		return "", "", fmt.Errorf("destination %q is inside source %q", input.Destination, input.Source)
+	}
+	if info, err := os.Lstat(dst); err == nil {
+		if !input.Overwrite {
+			return "", "", fmt.Errorf("destination %q already exists; set overwrite to replace it", input.Destination)
+		}
+		if info.IsDir() {
+			return "", "", fmt.Errorf("destination %q is a directory and cannot be overwritten", input.Destination)
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if err := os.Remove(dst); err != nil {
+				return "", "", err
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", "", err
+	}
+	return src, dst, nil
+}
+
+// NewMoveFileTool creates the move_file tool
+func NewMoveFileTool(workspacePath string) (tool.InvokableTool, error) {
+	run := func(ctx context.Context, input *TransferInput) (*FileOpOutput, error) {
+		src, dst, err := resolveTransfer(workspacePath, input, validateLinkPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := movePath(src, dst); err != nil {
+			return nil, err
+		}
+		return &FileOpOutput{
+			Path:    displayPath(workspacePath, dst),
+			Message: fmt.Sprintf("Moved %s to %s", displayPath(workspacePath, src), displayPath(workspacePath, dst)),
+		}, nil
+	}
+	return utils.InferTool("move_file", "Move or rename a file or directory", run)
+}
+
+// NewCopyFileTool creates the copy_file tool
+func NewCopyFileTool(workspacePath string) (tool.InvokableTool, error) {
+	run := func(ctx context.Context, input *TransferInput) (*FileOpOutput, error) {
+		src, dst, err := resolveTransfer(workspacePath, input, validatePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := copyPath(src, dst); err != nil {
+			return nil, err
+		}
+		return &FileOpOutput{
+			Path:    displayPath(workspacePath, dst),
+			Message: fmt.Sprintf("Copied %s to %s", displayPath(workspacePath, src), displayPath(workspacePath, dst)),
+		}, nil
+	}
+	return utils.InferTool("copy_file", "Copy a file or directory recursively", run)
+}
+
+// NewDeleteFileTool creates the delete_file tool. Deleted paths are moved
+// to the workspace trash so they can be restored with move_file.
+func NewDeleteFileTool(workspacePath string) (tool.InvokableTool, error) {
+	run := func(ctx context.Context, input *PathInput) (*DeleteFileOutput, error) {
+		path, err := validateLinkPath(workspacePath, input.Path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Lstat(path); err != nil {
+			return nil, err
+		}
+
+		trashRoot := trashDir(workspacePath)
+		if root, _ := resolveWorkspace(workspacePath); path == root || path == trashRoot {
+			return nil, fmt.Errorf("refusing to delete %q", input.Path)
+		}
+		if rel, err := filepath.Rel(trashRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("%q is already in the trash", input.Path)
+		}
+
+		name := displayPath(workspacePath, path)
+		if filepath.IsAbs(name) {
+			name = filepath.Base(path)
+		}
+		suffix := make([]byte, 3)
+		_, _ = rand.Read(suffix)
+		batch := time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+		target := filepath.Join(trashRoot, batch, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		if err := movePath(path, target); err != nil {
+			return nil, err
+		}
+		return &DeleteFileOutput{Path: name, Trashed: displayPath(workspacePath, target)}, nil
+	}
+	return utils.InferTool("delete_file",
+		"Delete a file or directory by moving it to the workspace trash; restore it with move_file", run)
+}
+
+func trashDir(workspacePath string) string {
+	if workspacePath == "" {
+		return filepath.Join(os.TempDir(), "golem-trash")
+	}
+	root, err := resolveWorkspace(workspacePath)
+	if err != nil {
+		root = filepath.Clean(workspacePath)
+	}
+	return filepath.Join(root, trashDirName)
+}
+
+// NewMakeDirTool creates the make_dir tool
+func NewMakeDirTool(workspacePath string) (tool.InvokableTool, error) {
+	run := func(ctx context.Context, input *PathInput) (*FileOpOutput, error) {
+		path, err := validatePath(workspacePath, input.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, err
+		}
+		return &FileOpOutput{Path: displayPath(workspacePath, path), Message: "Directory created"}, nil
+	}
+	return utils.InferTool("make_dir", "Create a directory and any missing parents", run)
+}
+
+// NewStatTool creates the stat tool
+func NewStatTool(workspacePath string) (tool.InvokableTool, error) {
+	run := func(ctx context.Context, input *PathInput) (*StatOutput, error) {
+		path, err := validateLinkPath(workspacePath, input.Path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		out := &StatOutput{
+			Path:    displayPath(workspacePath, path),
+			Size:    info.Size(),
+			Mode:    info.Mode().String(),
+			ModTime: info.ModTime(),
+		}
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			out.Type = "symlink"
+			out.LinkTarget, _ = os.Readlink(path)
+		case info.IsDir():
+			out.Type = "dir"
+			if entries, err := os.ReadDir(path); err == nil {
+				out.Entries = len(entries)
+			}
+		case info.Mode().IsRegular():
+			out.Type = "file"
+		default:
+			out.Type = "other"
+		}
+		return out, nil
+	}
+	return utils.InferTool("stat", "Show type, size, permissions and modification time of a path", run)
+}
+
+// movePath renames src to dst, copying when they are on different devices
+func movePath(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if copyErr := copyPath(src, dst); copyErr != nil {
+		_ = os.RemoveAll(dst)
+		return fmt.Errorf("move failed: %v; copy fallback failed: %w", err, copyErr)
+	}
+	return os.RemoveAll(src)
+}
+
+// copyPath copies files and directories, preserving permissions and
+// recreating symlinks rather than following them
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			_ = os.Remove(target)
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(p, target, info.Mode().Perm())
+		default:
+			return fmt.Errorf("cannot copy special file %s", p)
+		}
+	})
+}
+
+func copyFile(src, dst string, mode fs.FileMode) error {
+	in, err := openNoFollow(src, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := openNoFollow(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+func invokeJSON(t *testing.T, tl tool.InvokableTool, input any, out any) error {
+	t.Helper()
+	args, _ := json.Marshal(input)
+	result, err := tl.InvokableRun(context.Background(), string(args))
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		if err := json.Unmarshal([]byte(result), out); err != nil {
+			t.Fatalf("invalid output %q: %v", result, err)
+		}
+	}
+	return nil
+}
+
+func TestMoveFile(t *testing.T) {
+	ws := t.TempDir()
+	writeTestFile(t, filepath.Join(ws, "a.txt"), "A")
+	writeTestFile(t, filepath.Join(ws, "b.txt"), "B")
+	moveTool, _ := NewMoveFileTool(ws)
+
+	var out FileOpOutput
+	if err := invokeJSON(t, moveTool, TransferInput{Source: "a.txt", Destination: "nested/dir/a.txt"}, &out); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	if out.Path != "nested/dir/a.txt" || readTestFile(t, filepath.Join(ws, "nested/dir/a.txt")) != "A" {
+		t.Fatalf("unexpected move result %+v", out)
+	}
+	if _, err := os.Stat(filepath.Join(ws, "a.txt")); !os.IsNotExist(err) {
+		t.Fatal("source must be gone after move")
+	}
+
+	err := invokeJSON(t, moveTool, TransferInput{Source: "b.txt", Destination: "nested/dir/a.txt"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected existing destination to be protected, got %v", err)
+	}
+	if err := invokeJSON(t, moveTool, TransferInput{Source: "b.txt", Destination: "nested/dir/a.txt", Overwrite: true}, nil); err != nil {
+		t.Fatalf("overwrite failed: %v", err)
+	}
+	if readTestFile(t, filepath.Join(ws, "nested/dir/a.txt")) != "B" {
+		t.Fatal("expected destination to be replaced")
+	}
+
+	if err := invokeJSON(t, moveTool, TransferInput{Source: "nested", Destination: "nested/inner"}, nil); err == nil {
+		t.Fatal("expected moving a directory into itself to fail")
+	}
+	if err := invokeJSON(t, moveTool, TransferInput{Source: "nested", Destination: "../escaped"}, nil); err == nil {
+		t.Fatal("expected destination outside workspace to be rejected")
+	}
+}
+
+func TestCopyFile_Recursive(t *testing.T) {
+	ws := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(ws, "src/sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(ws, "src/a.txt"), "A")
+	if err := os.WriteFile(filepath.Join(ws, "src/sub/run.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	copyTool, _ := NewCopyFileTool(ws)
+
+	if err := invokeJSON(t, copyTool, TransferInput{Source: "src", Destination: "dst"}, nil); err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+	if readTestFile(t, filepath.Join(ws, "dst/a.txt")) != "A" || readTestFile(t, filepath.Join(ws, "src/a.txt")) != "A" {
+		t.Fatal("expected file copied and source kept")
+	}
+	info, err := os.Stat(filepath.Join(ws, "dst/sub/run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0755 {
+		t.Fatalf("expected mode to be preserved, got %v", info.Mode())
+	}
+}
+
+func TestDeleteFile_MovesToTrash(t *testing.T) {
+	ws := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(ws, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(ws, "docs/note.md"), "keep me")
+	deleteTool, _ := NewDeleteFileTool(ws)
+	moveTool, _ := NewMoveFileTool(ws)
+
+	var out DeleteFileOutput
+	if err := invokeJSON(t, deleteTool, PathInput{Path: "docs/note.md"}, &out); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(ws, "docs/note.md")); !os.IsNotExist(err) {
+		t.Fatal("expected file to be removed")
+	}
+	if !strings.HasPrefix(out.Trashed, ".trash/") || !strings.HasSuffix(out.Trashed, "/docs/note.md") {
+		t.Fatalf("unexpected trash location %q", out.Trashed)
+	}
+
+	if err := invokeJSON(t, moveTool, TransferInput{Source: out.Trashed, Destination: "docs/note.md"}, nil); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if readTestFile(t, filepath.Join(ws, "docs/note.md")) != "keep me" {
+		t.Fatal("expected restored content")
+	}
+
+	for _, p := range []string{".", ".trash", out.Trashed, "../x"} {
+		if err := invokeJSON(t, deleteTool, PathInput{Path: p}, nil); err == nil {
+			t.Fatalf("expected delete of %q to be refused", p)
+		}
+	}
+}
+
+func TestMakeDirAndStat(t *testing.T) {
+	ws := t.TempDir()
+	mkdirTool, _ := NewMakeDirTool(ws)
+	statTool, _ := NewStatTool(ws)
+
+	if err := invokeJSON(t, mkdirTool, PathInput{Path: "a/b/c"}, nil); err != nil {
+		t.Fatalf("make_dir failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(ws, "a/b/c/file.txt"), "12345")
+
+	var dir StatOutput
+	if err := invokeJSON(t, statTool, PathInput{Path: "a/b/c"}, &dir); err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if dir.Type != "dir" || dir.Entries != 1 || dir.Path != "a/b/c" {
+		t.Fatalf("unexpected dir stat %+v", dir)
+	}
+
+	var file StatOutput
+	if err := invokeJSON(t, statTool, PathInput{Path: "a/b/c/file.txt"}, &file); err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if file.Type != "file" || file.Size != 5 || file.ModTime.IsZero() {
+		t.Fatalf("unexpected file stat %+v", file)
+	}
+
+	if err := invokeJSON(t, statTool, PathInput{Path: "missing"}, nil); err == nil {
+		t.Fatal("expected stat of missing path to fail")
+	}
+}
+
+func TestWriteFile_CreatesParentDirs(t *testing.T) {
+	ws := t.TempDir()
+	writeTool, _ := NewWriteFileTool(ws)
+	if err := invokeJSON(t, writeTool, WriteFileInput{Path: "new/dir/file.txt", Content: "hi"}, nil); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if readTestFile(t, filepath.Join(ws, "new/dir/file.txt")) != "hi" {
+		t.Fatal("expected file content")
+	}
+}
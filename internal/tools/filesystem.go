@@ -25,34 +25,123 @@ type ReadFileOutput struct {
 	TotalLines int    `json:"total_lines"`
 }
 
-// validatePath ensures the target path is within the workspace
+// validatePath ensures the target path is within the workspace. Symlinks in
+// the existing part of the path are resolved before the check, so a link
+// pointing outside the workspace is rejected; the returned path is the
+// resolved one and contains no symlinks.
 func validatePath(workspace, target string) (string, error) {
 	if workspace == "" {
 		return target, nil
 	}
 
-	absWorkspace, err := filepath.Abs(workspace)
+	absWorkspace, absTarget, err := absolutePaths(workspace, target)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve workspace path: %w", err)
+		return "", err
 	}
 
-	var absTarget string
-	if filepath.IsAbs(target) {
-		absTarget = filepath.Clean(target)
-	} else {
-		absTarget = filepath.Join(absWorkspace, target)
+	resolved, err := resolveExisting(absTarget)
+	if err != nil {
+		return "", fmt.Errorf("access denied: cannot resolve path %q: %w", target, err)
+	}
+	if !withinDir(absWorkspace, resolved) {
+		return "", fmt.Errorf("access denied: path %q is outside workspace", target)
 	}
 
-	rel, err := filepath.Rel(absWorkspace, absTarget)
+	return resolved, nil
+}
+
+// validateLinkPath is validatePath for operations on a path itself rather
+// than what it points to, such as delete, move and stat. Only the parent is
+// resolved, so a symlink as the final component is returned unfollowed.
+func validateLinkPath(workspace, target string) (string, error) {
+	if workspace == "" {
+		return target, nil
+	}
+
+	absWorkspace, absTarget, err := absolutePaths(workspace, target)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve path relative to workspace: %w", err)
+		return "", err
 	}
 
-	if strings.HasPrefix(rel, "..") || rel == ".." {
+	parent, err := resolveExisting(filepath.Dir(absTarget))
+	if err != nil {
+		return "", fmt.Errorf("access denied: cannot resolve path %q: %w", target, err)
+	}
+	path := filepath.Join(parent, filepath.Base(absTarget))
+	if !withinDir(absWorkspace, path) {
 		return "", fmt.Errorf("access denied: path %q is outside workspace", target)
 	}
 
-	return absTarget, nil
+	return path, nil
+}
+
+// absolutePaths returns the resolved workspace and the target made absolute
+// against it
+func absolutePaths(workspace, target string) (string, string, error) {
+	absWorkspace, err := resolveWorkspace(workspace)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve workspace path: %w", err)
+	}
+	if filepath.IsAbs(target) {
+		return absWorkspace, filepath.Clean(target), nil
+	}
+	return absWorkspace, filepath.Join(absWorkspace, target), nil
+}
+
+// resolveWorkspace returns the absolute workspace path with symlinks resolved
+func resolveWorkspace(workspace string) (string, error) {
+	abs, err := filepath.Abs(workspace)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
+}
+
+// resolveExisting resolves symlinks in the longest existing prefix of an
+// absolute path and appends the components that do not exist yet. A dangling
+// symlink is an error, since writing through it would create its target.
+func resolveExisting(path string) (string, error) {
+	existing := path
+	var rest []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append(rest, filepath.Base(existing))
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	for i := len(rest) - 1; i >= 0; i-- {
+		resolved = filepath.Join(resolved, rest[i])
+	}
+	return resolved, nil
+}
+
+// withinDir reports whether path is dir or below it. Both must be clean
+// absolute paths; a sibling sharing a name prefix such as dir-other is outside.
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// openNoFollow opens a resolved path without following a symlink that may
+// have replaced its final component since validation
+func openNoFollow(path string, flag int, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(path, flag|noFollowFlag, perm)
 }
 
 // NewReadFileTool creates the read_file tool
@@ -63,7 +152,7 @@ func NewReadFileTool(workspacePath string) (tool.InvokableTool, error) {
 			return nil, err
 		}
 
-		file, err := os.Open(path)
+		file, err := openNoFollow(path, os.O_RDONLY, 0)
 		if err != nil {
 			return nil, err
 		}
@@ -176,13 +265,23 @@ func NewWriteFileTool(workspacePath string) (tool.InvokableTool, error) {
 			return "", err
 		}
 
-		err = os.WriteFile(path, []byte(input.Content), 0644)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		file, err := openNoFollow(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 		if err != nil {
 			return "", err
 		}
+		if _, err := file.WriteString(input.Content); err != nil {
+			file.Close()
+			return "", err
+		}
+		if err := file.Close(); err != nil {
+			return "", err
+		}
 		return "File written successfully", nil
 	}
-	return utils.InferTool("write_file", "Write content to a file", run)
+	return utils.InferTool("write_file", "Write content to a file, creating parent directories as needed", run)
 }
 
 // ListDirInput parameters for list_dir tool
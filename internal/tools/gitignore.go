@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is one .gitignore pattern; base is the slash-separated
+// directory of the .gitignore relative to the matcher root
+type ignoreRule struct {
+	base     string
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ignoreMatcher applies .gitignore files found while walking a tree.
+// Later rules win, so deeper files override their parents.
+type ignoreMatcher struct {
+	root   string
+	rules  []ignoreRule
+	loaded map[string]bool
+}
+
+func newIgnoreMatcher(root string) *ignoreMatcher {
+	m := &ignoreMatcher{root: root, loaded: make(map[string]bool)}
+	m.loadFile("", filepath.Join(root, ".git", "info", "exclude"))
+	return m
+}
+
+// loadDir reads the .gitignore of dir, given relative to the root
+func (m *ignoreMatcher) loadDir(rel string) {
+	if m.loaded[rel] {
+		return
+	}
+	m.loaded[rel] = true
+	m.loadFile(rel, filepath.Join(m.root, filepath.FromSlash(rel), ".gitignore"))
+}
+
+// loadParents reads the .gitignore files from the root down to dir
+func (m *ignoreMatcher) loadParents(rel string) {
+	m.loadDir("")
+	if rel == "" {
+		return
+	}
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		m.loadDir(strings.Join(parts[:i+1], "/"))
+	}
+}
+
+func (m *ignoreMatcher) loadFile(base, file string) {
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if r, ok := parseIgnoreLine(base, scanner.Text()); ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+}
+
+func parseIgnoreLine(base, line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasSuffix(line, `\ `) {
+		line = strings.TrimRight(line, " ")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	r := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	r.pattern = line
+	return r, true
+}
+
+// ignored reports whether a slash-separated path relative to the root is excluded
+func (m *ignoreMatcher) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			sub = rel[len(r.base)+1:]
+		}
+		var match bool
+		if r.anchored {
+			match = matchGlob(r.pattern, sub)
+		} else {
+			match = matchGlob(r.pattern, path.Base(sub))
+		}
+		if match {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
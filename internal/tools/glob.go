@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlob reports whether a slash-separated name matches pattern. Segments
+// follow path.Match syntax and "**" matches any number of segments.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(splitGlob(pattern), splitGlob(name))
+}
+
+func splitGlob(s string) []string {
+	s = strings.Trim(s, "/")
+	if s == "" || s == "." {
+		return nil
+	}
+	return strings.Split(s, "/")
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
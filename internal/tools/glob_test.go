@@ -0,0 +1,27 @@
+package tools
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "cmd/main.go", true},
+		{"**/*.go", "main.go", true},
+		{"cmd/**", "cmd/golem/main.go", true},
+		{"cmd/**", "internal/x.go", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/?.txt", "a/1.txt", true},
+		{"**", ".", true},
+	}
+	for _, tc := range tests {
+		if got := matchGlob(tc.pattern, tc.name); got != tc.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package tools
+
+import "context"
+
+// Invocation identifies the conversation a tool call originates from and,
+// when several agents run, the agent answering it
+type Invocation struct {
+	Channel  string
+	ChatID   string
+	SenderID string
+	Agent    string
+}
+
+// SessionKey returns the session identifier, matching bus.InboundMessage.SessionKey
+func (i Invocation) SessionKey() string {
+	return i.Channel + ":" + i.ChatID
+}
+
+type invocationKey struct{}
+
+// WithInvocation attaches the calling conversation to ctx
+func WithInvocation(ctx context.Context, inv Invocation) context.Context {
+	return context.WithValue(ctx, invocationKey{}, inv)
+}
+
+// InvocationFromContext returns the calling conversation, if any
+func InvocationFromContext(ctx context.Context) (Invocation, bool) {
+	inv, ok := ctx.Value(invocationKey{}).(Invocation)
+	return inv, ok
+}
@@ -0,0 +1,13 @@
+package tools
+
+import (
+	"os"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/sandbox"
+)
+
+func TestMain(m *testing.M) {
+	sandbox.Init()
+	os.Exit(m.Run())
+}
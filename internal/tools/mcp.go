@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MEKXH/golem/internal/mcp"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/eino-contrib/jsonschema"
+)
+
+// maxToolNameLen is the longest tool name model APIs accept
+const maxToolNameLen = 64
+
+// MCPToolName returns the registry name of a server's tool, server__tool,
+// with characters model APIs reject replaced by underscores
+func MCPToolName(server, name string) string {
+	full := []rune(server + "__" + name)
+	for i, r := range full {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			full[i] = '_'
+		}
+	}
+	if len(full) > maxToolNameLen {
+		full = full[:maxToolNameLen]
+	}
+	return string(full)
+}
+
+// mcpTool forwards calls to a tool of an MCP server
+type mcpTool struct {
+	client *mcp.Client
+	name   string
+	info   *schema.ToolInfo
+}
+
+func (t *mcpTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return t.info, nil
+}
+
+func (t *mcpTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	result, err := t.client.CallTool(ctx, t.name, json.RawMessage(argumentsInJSON))
+	if err != nil {
+		return "", err
+	}
+	if result.IsError {
+		return "", errors.New(result.Text())
+	}
+	return result.Text(), nil
+}
+
+// MCPServer returns the MCP server a registered tool comes from
+func MCPServer(t tool.InvokableTool) (string, bool) {
+	if m, ok := t.(*mcpTool); ok {
+		return m.client.Name(), true
+	}
+	return "", false
+}
+
+// NewMCPTools lists the tools of an MCP server and wraps them for the
+// registry, named with MCPToolName
+func NewMCPTools(ctx context.Context, client *mcp.Client) ([]tool.InvokableTool, error) {
+	list, err := client.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]tool.InvokableTool, 0, len(list))
+	for _, t := range list {
+		params := &jsonschema.Schema{Type: "object"}
+		if len(t.InputSchema) > 0 {
+			if err := json.Unmarshal(t.InputSchema, params); err != nil {
+				return nil, fmt.Errorf("tool %s: invalid input schema: %w", t.Name, err)
+			}
+		}
+		desc := strings.TrimSpace(t.Description)
+		if desc == "" {
+			desc = t.Title
+		}
+		out = append(out, &mcpTool{
+			client: client,
+			name:   t.Name,
+			info: &schema.ToolInfo{
+				Name:        MCPToolName(client.Name(), t.Name),
+				Desc:        desc,
+				ParamsOneOf: schema.NewParamsOneOfByJSONSchema(params),
+			},
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/mcp"
+	"github.com/MEKXH/golem/internal/mcp/mcptest"
+)
+
+func TestMCPToolName(t *testing.T) {
+	if got := MCPToolName("jira", "search_issues"); got != "jira__search_issues" {
+		t.Errorf("got %s", got)
+	}
+	if got := MCPToolName("my server", "files/read.v2"); got != "my_server__files_read_v2" {
+		t.Errorf("expected unsupported characters replaced, got %s", got)
+	}
+	if got := MCPToolName("s", strings.Repeat("x", 100)); len(got) != 64 {
+		t.Errorf("expected the name cut to 64 characters, got %d", len(got))
+	}
+}
+
+func TestMCPTools(t *testing.T) {
+	fake := mcptest.NewServer()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	client, err := mcp.Connect(context.Background(), mcp.Options{Name: "fake", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	mcpTools, err := NewMCPTools(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRegistry()
+	for _, tl := range mcpTools {
+		if err := r.Register(tl); err != nil {
+			t.Fatal(err)
+		}
+		if server, ok := MCPServer(tl); !ok || server != "fake" {
+			t.Errorf("expected the tool to report its server, got %q", server)
+		}
+	}
+	if len(r.Names()) != 4 {
+		t.Fatalf("expected four tools, got %v", r.Names())
+	}
+
+	echo, _ := r.Get("fake__echo")
+	info, _ := echo.Info(context.Background())
+	params, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil || params.Properties == nil || len(params.Required) != 1 || params.Required[0] != "text" {
+		t.Fatalf("expected the server's input schema, got %+v %v", params, err)
+	}
+
+	out, err := r.Execute(context.Background(), "fake__echo", `{"text":"hello"}`)
+	if err != nil || out != "hello" {
+		t.Fatalf("echo: %q %v", out, err)
+	}
+	if _, err := r.Execute(context.Background(), "fake__fail", `{}`); err == nil || !strings.Contains(err.Error(), "something broke") {
+		t.Fatalf("expected the tool error, got %v", err)
+	}
+	if calls := fake.Calls(); len(calls) != 2 || calls[0] != "echo" {
+		t.Fatalf("expected calls forwarded by their server names, got %v", calls)
+	}
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MEKXH/golem/internal/memory"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+const defaultMemoryResults = 10
+
+// MemorySaveInput parameters for memory_save tool
+type MemorySaveInput struct {
+	Content string   `json:"content" jsonschema:"required,description=Fact to remember, written so it makes sense on its own later"`
+	Tags    []string `json:"tags" jsonschema:"description=Short topic tags such as preferences or work"`
+	Daily   bool     `json:"daily" jsonschema:"description=Add to today's notes instead of long-term memory; use for events and progress rather than lasting facts"`
+}
+
+// MemorySaveOutput result of memory_save tool
+type MemorySaveOutput struct {
+	ID   string `json:"id,omitempty"`
+	File string `json:"file"`
+}
+
+// MemorySearchInput parameters for memory_search tool
+type MemorySearchInput struct {
+	Query string   `json:"query" jsonschema:"description=Words to look for; any match counts and more matches rank higher"`
+	Tags  []string `json:"tags" jsonschema:"description=Only return memories with all of these tags"`
+	Limit int      `json:"limit" jsonschema:"description=Maximum number of results (default 10)"`
+}
+
+// MemorySearchOutput result of memory_search tool
+type MemorySearchOutput struct {
+	Results []memory.Hit `json:"results"`
+}
+
+// MemoryForgetInput parameters for memory_forget tool
+type MemoryForgetInput struct {
+	ID string `json:"id" jsonschema:"required,description=Memory id as returned by memory_save or memory_search"`
+}
+
+// NewMemoryTools creates memory_save, memory_search and memory_forget
+func NewMemoryTools(store *memory.Store) ([]tool.InvokableTool, error) {
+	save := func(ctx context.Context, input *MemorySaveInput) (*MemorySaveOutput, error) {
+		if input.Daily {
+			name, err := store.AddNote(input.Content, input.Tags)
+			if err != nil {
+				return nil, err
+			}
+			return &MemorySaveOutput{File: "memory/" + name}, nil
+		}
+		entry, err := store.Save(input.Content, input.Tags)
+		if err != nil {
+			return nil, err
+		}
+		return &MemorySaveOutput{ID: entry.ID, File: "memory/" + memory.FileName}, nil
+	}
+
+	search := func(ctx context.Context, input *MemorySearchInput) (*MemorySearchOutput, error) {
+		limit := input.Limit
+		if limit <= 0 {
+			limit = defaultMemoryResults
+		}
+		hits, err := store.Search(input.Query, input.Tags, limit)
+		if err != nil {
+			return nil, err
+		}
+		if hits == nil {
+			hits = []memory.Hit{}
+		}
+		return &MemorySearchOutput{Results: hits}, nil
+	}
+
+	forget := func(ctx context.Context, input *MemoryForgetInput) (*FileOpOutput, error) {
+		entry, err := store.Forget(input.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &FileOpOutput{
+			Path:    "memory/" + memory.FileName,
+			Message: fmt.Sprintf("Forgot %s: %s", entry.ID, entry.Content),
+		}, nil
+	}
+
+	saveTool, err := utils.InferTool("memory_save",
+		"Remember a fact about the user or their work across sessions, or add a line to today's notes", save)
+	if err != nil {
+		return nil, err
+	}
+	searchTool, err := utils.InferTool("memory_search",
+		"Search long-term memory and daily notes by words and tags", search)
+	if err != nil {
+		return nil, err
+	}
+	forgetTool, err := utils.InferTool("memory_forget",
+		"Delete a long-term memory that is wrong or no longer wanted", forget)
+	if err != nil {
+		return nil, err
+	}
+	return []tool.InvokableTool{saveTool, searchTool, forgetTool}, nil
+}
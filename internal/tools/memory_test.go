@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/memory"
+)
+
+func TestMemoryTools(t *testing.T) {
+	ws := t.TempDir()
+	list, err := NewMemoryTools(memory.NewStore(filepath.Join(ws, "memory")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveTool, searchTool, forgetTool := list[0], list[1], list[2]
+
+	var saved MemorySaveOutput
+	if err := invokeJSON(t, saveTool, MemorySaveInput{Content: "Prefers dark mode", Tags: []string{"preferences"}}, &saved); err != nil {
+		t.Fatalf("memory_save failed: %v", err)
+	}
+	if saved.ID == "" || saved.File != "memory/MEMORY.md" {
+		t.Fatalf("unexpected save output %+v", saved)
+	}
+	var note MemorySaveOutput
+	if err := invokeJSON(t, saveTool, MemorySaveInput{Content: "Set up dark mode in the editor", Daily: true}, &note); err != nil {
+		t.Fatalf("daily note failed: %v", err)
+	}
+	if note.ID != "" || !strings.HasPrefix(note.File, "memory/") || !strings.HasSuffix(note.File, ".md") {
+		t.Fatalf("unexpected note output %+v", note)
+	}
+	if !strings.Contains(readTestFile(t, filepath.Join(ws, "memory", "MEMORY.md")), "Prefers dark mode") {
+		t.Fatal("expected entry in MEMORY.md")
+	}
+
+	var found MemorySearchOutput
+	if err := invokeJSON(t, searchTool, MemorySearchInput{Query: "dark mode"}, &found); err != nil {
+		t.Fatalf("memory_search failed: %v", err)
+	}
+	if len(found.Results) != 2 {
+		t.Fatalf("expected entry and note, got %+v", found.Results)
+	}
+
+	if err := invokeJSON(t, forgetTool, MemoryForgetInput{ID: saved.ID}, nil); err != nil {
+		t.Fatalf("memory_forget failed: %v", err)
+	}
+	found = MemorySearchOutput{}
+	_ = invokeJSON(t, searchTool, MemorySearchInput{Tags: []string{"preferences"}}, &found)
+	if len(found.Results) != 0 {
+		t.Fatalf("expected forgotten memory to be gone, got %+v", found.Results)
+	}
+	if err := invokeJSON(t, saveTool, MemorySaveInput{Content: "  "}, nil); err == nil {
+		t.Fatal("expected empty memory to be rejected")
+	}
+}
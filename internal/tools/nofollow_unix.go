@@ -0,0 +1,8 @@
+//go:build !windows
+
+package tools
+
+import "syscall"
+
+// noFollowFlag makes open fail when the final path component is a symlink
+const noFollowFlag = syscall.O_NOFOLLOW
@@ -0,0 +1,6 @@
+//go:build windows
+
+package tools
+
+// noFollowFlag is unsupported on Windows; validatePath resolves links instead
+const noFollowFlag = 0
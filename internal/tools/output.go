@@ -0,0 +1,266 @@
+package tools
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultOutputBudget is the default maximum size of a tool result in bytes
+	DefaultOutputBudget = 16 * 1024
+	// minFieldKeep is the smallest share of a string field kept when truncating
+	minFieldKeep = 256
+	// maxArtifacts is how many spilled outputs are kept; older ones are removed
+	maxArtifacts = 100
+)
+
+// OutputLimits caps tool results before they are sent to the model.
+// Budgets are in bytes; a negative per-tool budget disables the cap.
+type OutputLimits struct {
+	Default     int
+	PerTool     map[string]int
+	Workspace   string
+	ArtifactDir string
+}
+
+// budget returns the cap for a tool, or 0 when uncapped
+func (l OutputLimits) budget(name string) int {
+	if b, ok := l.PerTool[name]; ok && b != 0 {
+		return max(b, 0)
+	}
+	return max(l.Default, 0)
+}
+
+// limitOutput truncates result to the tool's budget, spilling the full
+// output to an artifact file the agent can page through with read_file.
+// Reading an artifact back is not spilled again.
+func (l OutputLimits) limitOutput(name, argsJSON, result string) string {
+	budget := l.budget(name)
+	if budget == 0 || len(result) <= budget {
+		return result
+	}
+
+	note := "full output discarded"
+	if name == "read_file" && l.readsArtifact(argsJSON) {
+		note = "read fewer lines at a time with a smaller limit"
+	} else if artifact, text, err := l.spill(name, result); err == nil {
+		lines := strings.Count(text, "\n") + 1
+		note = fmt.Sprintf(`full output (%d lines) saved to %s; page through it with read_file {"path":%q,"offset":0,"limit":%d}`,
+			lines, artifact, artifact, l.pageLines(text, lines))
+	}
+
+	if limited, ok := truncateJSONFields(result, budget, note); ok {
+		return limited
+	}
+	return truncateMiddle(result, budget, note)
+}
+
+// pageLines suggests a read_file limit whose pages fit the read_file budget
+func (l OutputLimits) pageLines(text string, lines int) int {
+	budget := l.budget("read_file")
+	if budget == 0 {
+		budget = max(l.Default, DefaultOutputBudget)
+	}
+	avg := max(len(text)/lines, 1)
+	// Leave room for JSON escaping and the other fields of the result
+	return max(budget/2/avg, 1)
+}
+
+func (l OutputLimits) artifactDir() string {
+	if l.Workspace == "" || l.ArtifactDir == "" {
+		return ""
+	}
+	if filepath.IsAbs(l.ArtifactDir) {
+		return filepath.Clean(l.ArtifactDir)
+	}
+	return filepath.Join(l.Workspace, l.ArtifactDir)
+}
+
+// readsArtifact reports whether read_file arguments name a spilled output
+func (l OutputLimits) readsArtifact(argsJSON string) bool {
+	dir := l.artifactDir()
+	var args ReadFileInput
+	if dir == "" || json.Unmarshal([]byte(argsJSON), &args) != nil || args.Path == "" {
+		return false
+	}
+	path := args.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.Workspace, path)
+	}
+	return filepath.Dir(filepath.Clean(path)) == dir
+}
+
+// spill writes the full result to a new artifact and returns its path and text
+func (l OutputLimits) spill(name, result string) (string, string, error) {
+	dir := l.artifactDir()
+	if dir == "" {
+		return "", "", fmt.Errorf("artifacts disabled")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	file := fmt.Sprintf("%s-%s-%s.txt", name, time.Now().Format("20060102-150405"), hex.EncodeToString(suffix))
+	path := filepath.Join(dir, file)
+	text := artifactText(result)
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return "", "", err
+	}
+	pruneArtifacts(dir, maxArtifacts)
+	if rel, err := filepath.Rel(l.Workspace, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel), text, nil
+	}
+	return path, text, nil
+}
+
+// pruneArtifacts removes the oldest artifacts beyond keep
+func pruneArtifacts(dir string, keep int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type artifact struct {
+		path    string
+		modTime time.Time
+	}
+	var artifacts []artifact
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".txt") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			artifacts = append(artifacts, artifact{filepath.Join(dir, e.Name()), info.ModTime()})
+		}
+	}
+	if len(artifacts) <= keep {
+		return
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].modTime.After(artifacts[j].modTime) })
+	for _, a := range artifacts[keep:] {
+		os.Remove(a.path)
+	}
+}
+
+// artifactText unpacks JSON objects so long string fields stay line-pageable
+func artifactText(result string) string {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(result), &fields); err != nil || fields == nil {
+		return result
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		if s, ok := fields[k].(string); ok && strings.Contains(s, "\n") {
+			fmt.Fprintf(&b, "=== %s ===\n%s", k, s)
+			if !strings.HasSuffix(s, "\n") {
+				b.WriteByte('\n')
+			}
+			continue
+		}
+		raw, _ := json.Marshal(fields[k])
+		fmt.Fprintf(&b, "%s: %s\n", k, raw)
+	}
+	return b.String()
+}
+
+// truncateJSONFields shrinks the longest string fields of a JSON object so
+// the result stays valid JSON within budget
+func truncateJSONFields(result string, budget int, note string) (string, bool) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(result), &fields); err != nil || fields == nil {
+		return "", false
+	}
+	fields["truncated"] = note
+
+	for {
+		encoded, err := json.Marshal(fields)
+		if err != nil {
+			return "", false
+		}
+		over := len(encoded) - budget
+		if over <= 0 {
+			return string(encoded), true
+		}
+
+		longest, size := "", 0
+		for k, v := range fields {
+			if s, ok := v.(string); ok && k != "truncated" && len(s) > size && len(s) > minFieldKeep*2 {
+				longest, size = k, len(s)
+			}
+		}
+		if longest == "" {
+			return "", false
+		}
+		// JSON escaping can make the encoded field larger than the raw string
+		keep := max(size-over-len(note)-64, minFieldKeep)
+		fields[longest] = truncateMiddle(fields[longest].(string), keep, "")
+	}
+}
+
+// truncateMiddle keeps the head and tail of s within budget bytes, joined by a marker
+func truncateMiddle(s string, budget int, note string) string {
+	if len(s) <= budget {
+		return s
+	}
+	marker := func(omitted int) string {
+		if note == "" {
+			return fmt.Sprintf("\n... [%d bytes truncated] ...\n", omitted)
+		}
+		return fmt.Sprintf("\n... [%d bytes truncated; %s] ...\n", omitted, note)
+	}
+
+	keep := max(budget-len(marker(len(s))), 0)
+	headLen := keep * 2 / 3
+	head := cutAtLine(s[:validPrefix(s, headLen)], true)
+	tailStart := len(s) - (keep - len(head))
+	tail := cutAtLine(s[validSuffix(s, tailStart):], false)
+	return head + marker(len(s)-len(head)-len(tail)) + tail
+}
+
+// cutAtLine trims a partial line at the cut edge when one is close by
+func cutAtLine(s string, head bool) string {
+	const window = 200
+	if head {
+		if i := strings.LastIndexByte(s, '\n'); i >= 0 && len(s)-i <= window {
+			return s[:i+1]
+		}
+		return s
+	}
+	if i := strings.IndexByte(s, '\n'); i >= 0 && i < window {
+		return s[i+1:]
+	}
+	return s
+}
+
+// validPrefix returns n adjusted down to a rune boundary
+func validPrefix(s string, n int) int {
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return n
+}
+
+// validSuffix returns start adjusted up to a rune boundary
+func validSuffix(s string, start int) int {
+	start = max(start, 0)
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return start
+}
@@ -0,0 +1,205 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+type staticTool struct {
+	name   string
+	output string
+}
+
+func (s *staticTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: s.name, Desc: "returns fixed output"}, nil
+}
+
+func (s *staticTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	return s.output, nil
+}
+
+func numberedLines(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		b.WriteString("line ")
+		b.WriteString(strings.Repeat("x", i%7))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestTruncateMiddle_KeepsHeadAndTail(t *testing.T) {
+	s := "HEAD\n" + strings.Repeat("middle\n", 2000) + "TAIL\n"
+	out := truncateMiddle(s, 500, "see artifact")
+
+	if len(out) > 500 {
+		t.Fatalf("expected at most 500 bytes, got %d", len(out))
+	}
+	if !strings.HasPrefix(out, "HEAD\n") || !strings.HasSuffix(out, "TAIL\n") {
+		t.Fatalf("expected head and tail to be kept, got %q", out)
+	}
+	if !regexp.MustCompile(`\[\d+ bytes truncated; see artifact\]`).MatchString(out) {
+		t.Fatalf("expected truncation marker, got %q", out)
+	}
+}
+
+func TestTruncateMiddle_RespectsRuneBoundaries(t *testing.T) {
+	s := strings.Repeat("界", 1000)
+	out := truncateMiddle(s, 301, "")
+	if !strings.Contains(out, "bytes truncated") {
+		t.Fatalf("expected marker, got %q", out)
+	}
+	for _, part := range strings.Split(out, "\n") {
+		if strings.ContainsRune(part, '�') {
+			t.Fatalf("split inside a rune: %q", out)
+		}
+	}
+}
+
+func TestRegistry_ExecuteSpillsLargeOutput(t *testing.T) {
+	ws := t.TempDir()
+	stdout := numberedLines(5000)
+	result, _ := json.Marshal(ExecOutput{Stdout: stdout, Stderr: "warning\n", ExitCode: 0})
+
+	reg := NewRegistry()
+	if err := reg.Register(&staticTool{name: "exec", output: string(result)}); err != nil {
+		t.Fatal(err)
+	}
+	reg.SetOutputLimits(OutputLimits{Default: 2048, Workspace: ws, ArtifactDir: "artifacts"})
+
+	out, err := reg.Execute(context.Background(), "exec", `{}`)
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if len(out) > 2048 {
+		t.Fatalf("expected result within budget, got %d bytes", len(out))
+	}
+
+	var limited struct {
+		ExecOutput
+		Truncated string `json:"truncated"`
+	}
+	if err := json.Unmarshal([]byte(out), &limited); err != nil {
+		t.Fatalf("truncated result must stay valid JSON: %v\n%s", err, out)
+	}
+	if limited.Stderr != "warning\n" || !strings.HasPrefix(limited.Stdout, "line x\nline xx\n") {
+		t.Fatalf("expected short fields intact and stdout head kept, got %+v", limited.ExecOutput)
+	}
+
+	artifact := regexp.MustCompile(`artifacts/exec-[^ ,]+\.txt`).FindString(limited.Truncated)
+	if artifact == "" {
+		t.Fatalf("expected artifact path in %q", limited.Truncated)
+	}
+
+	readTool, _ := NewReadFileTool(ws)
+	args, _ := json.Marshal(ReadFileInput{Path: artifact, Offset: 10, Limit: 2})
+	page, err := readTool.InvokableRun(context.Background(), string(args))
+	if err != nil {
+		t.Fatalf("read_file on artifact failed: %v", err)
+	}
+	var read ReadFileOutput
+	_ = json.Unmarshal([]byte(page), &read)
+	if read.Content == "" || !strings.Contains(read.Content, "line") {
+		t.Fatalf("expected artifact to be pageable, got %+v", read)
+	}
+
+	data, err := os.ReadFile(filepath.Join(ws, artifact))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), stdout) {
+		t.Fatal("artifact must contain the full stdout")
+	}
+
+	// The note names the exact call, and its pages fit the budget
+	call := regexp.MustCompile(`read_file (\{.*\})`).FindStringSubmatch(limited.Truncated)
+	if call == nil {
+		t.Fatalf("expected a read_file call in %q", limited.Truncated)
+	}
+	var suggested ReadFileInput
+	if err := json.Unmarshal([]byte(call[1]), &suggested); err != nil || suggested.Path != artifact || suggested.Limit <= 0 {
+		t.Fatalf("unexpected suggested call %q (%v)", call[1], err)
+	}
+	if err := reg.Register(readTool); err != nil {
+		t.Fatal(err)
+	}
+	page, err = reg.Execute(context.Background(), "read_file", call[1])
+	if err != nil || len(page) > 2048 || strings.Contains(page, "truncated") {
+		t.Fatalf("expected the suggested page to fit, got %d bytes (%v)", len(page), err)
+	}
+
+	// Reading the whole artifact back truncates without spilling it again
+	args, _ = json.Marshal(ReadFileInput{Path: artifact})
+	page, err = reg.Execute(context.Background(), "read_file", string(args))
+	if err != nil || len(page) > 2048 || !strings.Contains(page, "smaller limit") {
+		t.Fatalf("expected a truncated page asking for a smaller limit, got %d bytes (%v)", len(page), err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(ws, "artifacts")); len(entries) != 1 {
+		t.Fatalf("expected no new artifact, got %d", len(entries))
+	}
+}
+
+func TestPruneArtifacts_KeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("exec-%d.txt", i))
+		writeTestFile(t, path, "x")
+		if err := os.Chtimes(path, now, now.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pruneArtifacts(dir, 2)
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if strings.Join(names, ",") != "exec-3.txt,exec-4.txt" {
+		t.Fatalf("expected the two newest artifacts, got %v", names)
+	}
+}
+
+func TestRegistry_ExecuteOutputBudgets(t *testing.T) {
+	big := strings.Repeat("a", 5000)
+
+	reg := NewRegistry()
+	for _, name := range []string{"small", "large", "uncapped"} {
+		if err := reg.Register(&staticTool{name: name, output: big}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reg.SetOutputLimits(OutputLimits{
+		Default: 1000,
+		PerTool: map[string]int{"large": 4000, "uncapped": -1},
+	})
+
+	for name, want := range map[string]int{"small": 1000, "large": 4000, "uncapped": 5000} {
+		out, err := reg.Execute(context.Background(), name, `{}`)
+		if err != nil {
+			t.Fatalf("%s: Execute error: %v", name, err)
+		}
+		if name == "uncapped" {
+			if out != big {
+				t.Fatalf("uncapped output must be returned unchanged")
+			}
+			continue
+		}
+		if len(out) > want || len(out) < want-100 {
+			t.Fatalf("%s: expected about %d bytes, got %d", name, want, len(out))
+		}
+		if !strings.Contains(out, "full output discarded") {
+			t.Fatalf("%s: expected discard note without artifact dir, got marker in %q", name, out[len(out)/2-100:len(out)/2+100])
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/plugins"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/eino-contrib/jsonschema"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+const (
+	// maxPluginStderr is how much of a failing plugin's stderr is reported
+	maxPluginStderr = 2048
+	// pluginWaitDelay bounds the wait for output pipes held open by children
+	// of a plugin that was killed
+	pluginWaitDelay = 2 * time.Second
+)
+
+// pluginTool runs a plugin executable with the exec tool's timeout and sandbox
+type pluginTool struct {
+	plugin plugins.Plugin
+	info   *schema.ToolInfo
+	exec   *execToolImpl
+}
+
+func (t *pluginTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return t.info, nil
+}
+
+func (t *pluginTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	args := strings.TrimSpace(argumentsInJSON)
+	if args == "" {
+		args = "{}"
+	}
+	runCtx, cancel := context.WithTimeout(ctx, t.exec.timeout)
+	defer cancel()
+
+	cmd, err := t.command(runCtx)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s: %w", t.plugin.Name, err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(args)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("plugin %s timed out after %s", t.plugin.Name, t.exec.timeout)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxPluginStderr {
+			msg = msg[:maxPluginStderr] + "..."
+		}
+		return "", fmt.Errorf("plugin %s failed: %v: %s", t.plugin.Name, err, msg)
+	}
+	out := bytes.TrimSpace(stdout.Bytes())
+	if !json.Valid(out) {
+		return "", fmt.Errorf("plugin %s did not write JSON to stdout", t.plugin.Name)
+	}
+	return string(out), nil
+}
+
+// command builds the plugin process bound to ctx. It runs in the workspace
+// and is told where its own folder is through GOLEM_PLUGIN_DIR.
+func (t *pluginTool) command(ctx context.Context) (*exec.Cmd, error) {
+	dir := t.exec.workspaceDir
+	if dir == "" {
+		dir = t.plugin.Dir
+	}
+	var cmd *exec.Cmd
+	if t.exec.sandbox != nil {
+		words := append([]string{t.plugin.Executable()}, t.plugin.Args...)
+		quoted := make([]string, 0, len(words))
+		for _, w := range words {
+			q, err := syntax.Quote(w, syntax.LangPOSIX)
+			if err != nil {
+				return nil, err
+			}
+			quoted = append(quoted, q)
+		}
+		sandboxed, err := t.exec.sandbox.Command(ctx, "exec "+strings.Join(quoted, " "), dir)
+		if err != nil {
+			return nil, fmt.Errorf("sandbox error: %w", err)
+		}
+		cmd = sandboxed
+	} else {
+		cmd = exec.CommandContext(ctx, t.plugin.Executable(), t.plugin.Args...)
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, "GOLEM_PLUGIN_DIR="+t.plugin.Dir, "GOLEM_WORKSPACE="+t.exec.workspaceDir)
+	cmd.Dir = dir
+	cmd.WaitDelay = pluginWaitDelay
+	return cmd, nil
+}
+
+// PluginDir returns the folder of a registered plugin tool
+func PluginDir(t tool.InvokableTool) (string, bool) {
+	if p, ok := t.(*pluginTool); ok {
+		return p.plugin.Dir, true
+	}
+	return "", false
+}
+
+// NewPluginTool wraps a plugin for the registry. Only the sandbox of the
+// exec options applies: the command is fixed by the manifest, so the
+// command rules have nothing to judge.
+func NewPluginTool(p plugins.Plugin, timeoutSec int, workspaceDir string, opts ...ExecOption) (tool.InvokableTool, error) {
+	impl, err := newExecToolImpl(timeoutSec, true, workspaceDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	params := &jsonschema.Schema{Type: "object"}
+	if len(p.Parameters) > 0 {
+		if err := json.Unmarshal(p.Parameters, params); err != nil {
+			return nil, fmt.Errorf("plugin %s: invalid parameters schema: %w", p.Name, err)
+		}
+	}
+	return &pluginTool{
+		plugin: p,
+		exec:   impl,
+		info: &schema.ToolInfo{
+			Name:        p.Name,
+			Desc:        p.Description,
+			ParamsOneOf: schema.NewParamsOneOfByJSONSchema(params),
+		},
+	}, nil
+}
@@ -0,0 +1,92 @@
+//go:build !windows
+
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/plugins"
+)
+
+func writePlugin(t *testing.T, dir, script string) plugins.Plugin {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"name": "greet", "description": "Greet someone",
+		"parameters": {"type": "object", "properties": {"name": {"type": "string"}}},
+		"command": "./run.sh", "args": ["hello"]}`
+	if err := os.WriteFile(filepath.Join(dir, plugins.ManifestName), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := plugins.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPluginTool_PassesJSONThroughStdio(t *testing.T) {
+	workspace := t.TempDir()
+	p := writePlugin(t, filepath.Join(t.TempDir(), "greet"),
+		`read input
+printf '{"greeting":"%s","input":%s,"cwd":"%s","dir_set":%s}\n' "$1" "$input" "$(pwd)" "$([ -n "$GOLEM_PLUGIN_DIR" ] && echo true || echo false)"
+`)
+	tool, err := NewPluginTool(p, 10, workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := tool.Info(context.Background())
+	if info.Name != "greet" || info.Desc != "Greet someone" {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if dir, ok := PluginDir(tool); !ok || dir != p.Dir {
+		t.Fatalf("expected plugin dir %s, got %s", p.Dir, dir)
+	}
+
+	out, err := tool.InvokableRun(context.Background(), `{"name":"Ada"}`)
+	if err != nil {
+		t.Fatalf("InvokableRun error: %v", err)
+	}
+	resolved, _ := filepath.EvalSymlinks(workspace)
+	for _, want := range []string{`"greeting":"hello"`, `"input":{"name":"Ada"}`, `"dir_set":true`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in %s", want, out)
+		}
+	}
+	if !strings.Contains(out, `"cwd":"`+workspace+`"`) && !strings.Contains(out, `"cwd":"`+resolved+`"`) {
+		t.Errorf("expected the plugin to run in the workspace, got %s", out)
+	}
+}
+
+func TestPluginTool_Failures(t *testing.T) {
+	cases := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"exit code", "echo 'no API key' >&2\nexit 2\n", "no API key"},
+		{"not json", "echo hello\n", "did not write JSON"},
+		{"timeout", "sleep 5\n", "timed out"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := writePlugin(t, filepath.Join(t.TempDir(), "greet"), tc.script)
+			tool, err := NewPluginTool(p, 1, t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = tool.InvokableRun(context.Background(), `{}`)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
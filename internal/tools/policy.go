@@ -0,0 +1,231 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// pathArgKeys are the argument names that carry filesystem paths
+var pathArgKeys = []string{"path", "working_dir", "source", "destination"}
+
+// commandTools are the tools whose "command" argument is checked against exec_allow
+var commandTools = map[string]bool{"exec": true, "exec_background": true}
+
+// ToolPolicy restricts the tools available to matching conversations.
+// Empty match fields (or "*") match anything; empty Allow permits all tools.
+//
+// ExecAllow patterns are checked against each program invocation in a
+// command line (its words, unquoted and joined by spaces), and all of them
+// must match. Lists, pipes, substitutions, redirects and other shell syntax
+// are only permitted when a pattern matches the entire command line.
+type ToolPolicy struct {
+	Channel   string
+	SenderID  string
+	Session   string
+	Allow     []string
+	Deny      []string
+	ExecAllow []string
+	Paths     []string
+}
+
+type compiledPolicy struct {
+	ToolPolicy
+	execAllow     []*regexp.Regexp
+	execAllowLine []*regexp.Regexp
+}
+
+// PolicySet evaluates tool policies. Every policy matching a conversation
+// applies, so the effective permissions are their intersection.
+type PolicySet struct {
+	policies  []compiledPolicy
+	workspace string
+}
+
+// NewPolicySet compiles policies; path globs are relative to workspace
+func NewPolicySet(policies []ToolPolicy, workspace string) (*PolicySet, error) {
+	s := &PolicySet{workspace: workspace}
+	for i, p := range policies {
+		c := compiledPolicy{ToolPolicy: p}
+		for _, expr := range p.ExecAllow {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("policy %d: invalid exec_allow %q: %w", i, expr, err)
+			}
+			c.execAllow = append(c.execAllow, re)
+			c.execAllowLine = append(c.execAllowLine, regexp.MustCompile(`^(?:`+expr+`)$`))
+		}
+		for _, pattern := range append(append([]string{}, p.Allow...), p.Deny...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("policy %d: invalid tool pattern %q: %w", i, pattern, err)
+			}
+		}
+		s.policies = append(s.policies, c)
+	}
+	return s, nil
+}
+
+func (p *compiledPolicy) matches(inv Invocation) bool {
+	return matchField(p.Channel, inv.Channel) &&
+		matchField(p.SenderID, inv.SenderID) &&
+		matchField(p.Session, inv.SessionKey())
+}
+
+func matchField(want, got string) bool {
+	return want == "" || want == "*" || want == got
+}
+
+func matchAnyName(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed reports whether a tool is available to the conversation
+func (s *PolicySet) Allowed(inv Invocation, name string) bool {
+	if s == nil {
+		return true
+	}
+	for i := range s.policies {
+		p := &s.policies[i]
+		if !p.matches(inv) {
+			continue
+		}
+		if len(p.Allow) > 0 && !matchAnyName(p.Allow, name) {
+			return false
+		}
+		if matchAnyName(p.Deny, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check validates a call, including argument constraints
+func (s *PolicySet) Check(inv Invocation, name, argsJSON string) error {
+	if s == nil {
+		return nil
+	}
+	if !s.Allowed(inv, name) {
+		return fmt.Errorf("tool %s is not permitted for %s", name, inv.SessionKey())
+	}
+
+	var args map[string]any
+	_ = json.Unmarshal([]byte(argsJSON), &args)
+
+	for i := range s.policies {
+		p := &s.policies[i]
+		if !p.matches(inv) {
+			continue
+		}
+		if commandTools[name] && len(p.execAllow) > 0 {
+			command, _ := args["command"].(string)
+			if !p.commandAllowed(command) {
+				return fmt.Errorf("command %q is not permitted for %s", command, inv.SessionKey())
+			}
+		}
+		if len(p.Paths) > 0 {
+			for _, key := range pathArgKeys {
+				value, ok := args[key].(string)
+				if !ok || value == "" {
+					continue
+				}
+				if !s.pathAllowed(p.Paths, value) {
+					return fmt.Errorf("path %q is not permitted for %s", value, inv.SessionKey())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// commandAllowed parses a command line and checks every invocation in it
+// against exec_allow. Anything beyond one plain invocation also needs a
+// pattern covering the whole line.
+func (p *compiledPolicy) commandAllowed(command string) bool {
+	command = strings.TrimSpace(command)
+	file, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(command), "")
+	if err != nil || len(file.Stmts) == 0 {
+		return false
+	}
+	plain := len(file.Stmts) == 1
+	calls, allowed := 0, true
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch n := node.(type) {
+		case *syntax.Stmt:
+			if _, ok := n.Cmd.(*syntax.CallExpr); !ok || len(n.Redirs) > 0 || n.Background || n.Coprocess || n.Negated {
+				plain = false
+			}
+		case *syntax.CallExpr:
+			if len(n.Assigns) > 0 || len(n.Args) == 0 {
+				plain = false
+			}
+			if len(n.Args) == 0 {
+				break
+			}
+			calls++
+			words := make([]string, 0, len(n.Args))
+			for _, w := range n.Args {
+				words = append(words, wordValue(w))
+			}
+			if !matchAnyRegexp(p.execAllow, strings.Join(words, " ")) {
+				allowed = false
+			}
+		case *syntax.CmdSubst, *syntax.ProcSubst:
+			plain = false
+		}
+		return allowed
+	})
+	if !allowed || calls == 0 {
+		return false
+	}
+	return plain || matchAnyRegexp(p.execAllowLine, command)
+}
+
+func matchAnyRegexp(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *PolicySet) pathAllowed(globs []string, target string) bool {
+	abs := target
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(s.workspace, abs)
+	}
+	abs = filepath.Clean(abs)
+
+	rel := filepath.ToSlash(abs)
+	if s.workspace != "" {
+		// Match where the path really points so a symlink cannot borrow
+		// another directory's rules
+		root, err := resolveWorkspace(s.workspace)
+		if err != nil {
+			root = s.workspace
+		}
+		if resolved, err := resolveExisting(abs); err == nil {
+			abs = resolved
+			rel = filepath.ToSlash(abs)
+		}
+		if r, err := filepath.Rel(root, abs); err == nil && withinDir(root, abs) {
+			rel = filepath.ToSlash(r)
+		}
+	}
+	for _, g := range globs {
+		if matchGlob(filepath.ToSlash(g), rel) {
+			return true
+		}
+	}
+	return false
+}
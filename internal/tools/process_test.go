@@ -0,0 +1,242 @@
+//go:build !windows
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+func newProcessTools(t *testing.T, ws string) (*ProcessManager, map[string]tool.InvokableTool) {
+	t.Helper()
+	manager := NewProcessManager(ws, 2)
+	t.Cleanup(manager.Close)
+
+	list, err := NewProcessTools(manager, true, ws)
+	if err != nil {
+		t.Fatalf("NewProcessTools error: %v", err)
+	}
+	byName := make(map[string]tool.InvokableTool)
+	for _, tl := range list {
+		info, _ := tl.Info(context.Background())
+		byName[info.Name] = tl
+	}
+	return manager, byName
+}
+
+func sessionContext(chatID string) context.Context {
+	return WithInvocation(context.Background(), Invocation{Channel: "cli", ChatID: chatID, SenderID: "user"})
+}
+
+func runTool[T any](t *testing.T, ctx context.Context, tl tool.InvokableTool, args any) T {
+	t.Helper()
+	raw, _ := json.Marshal(args)
+	out, err := tl.InvokableRun(ctx, string(raw))
+	if err != nil {
+		t.Fatalf("tool error: %v", err)
+	}
+	var result T
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid tool output %q: %v", out, err)
+	}
+	return result
+}
+
+func TestProcessTools_StdinAndIncrementalOutput(t *testing.T) {
+	ws := t.TempDir()
+	_, tools := newProcessTools(t, ws)
+	ctx := sessionContext("a")
+
+	started := runTool[ExecBackgroundOutput](t, ctx, tools["exec_background"], ExecInput{
+		Command: `while read line; do echo "got $line"; done; echo bye >&2`,
+	})
+	if started.ID == "" || started.PID == 0 {
+		t.Fatalf("expected process to start, got %+v", started)
+	}
+
+	written := runTool[ProcessInputOutput](t, ctx, tools["process_input"], ProcessInputInput{ID: started.ID, Input: "one\n"})
+	if written.Written != 4 {
+		t.Fatalf("expected 4 bytes written, got %d", written.Written)
+	}
+	out := runTool[ProcessOutput](t, ctx, tools["process_output"], ProcessOutputInput{ID: started.ID, WaitSeconds: 5})
+	if !out.Running || out.Stdout != "got one\n" {
+		t.Fatalf("unexpected first output: %+v", out)
+	}
+
+	runTool[ProcessInputOutput](t, ctx, tools["process_input"], ProcessInputInput{ID: started.ID, Input: "two\n", CloseStdin: true})
+	deadline := time.Now().Add(5 * time.Second)
+	var stdout, stderr string
+	for time.Now().Before(deadline) {
+		out = runTool[ProcessOutput](t, ctx, tools["process_output"], ProcessOutputInput{ID: started.ID, WaitSeconds: 1})
+		stdout += out.Stdout
+		stderr += out.Stderr
+		if !out.Running {
+			break
+		}
+	}
+	if out.Running || out.ExitCode != 0 || stdout != "got two\n" || stderr != "bye\n" {
+		t.Fatalf("unexpected final output: %+v (stdout %q, stderr %q)", out, stdout, stderr)
+	}
+
+	// Finished processes are forgotten once fully read
+	raw, _ := json.Marshal(ProcessOutputInput{ID: started.ID})
+	if _, err := tools["process_output"].InvokableRun(ctx, string(raw)); err == nil {
+		t.Fatal("expected unknown process after exit was reported")
+	}
+}
+
+func TestProcessTools_SessionIsolationAndKill(t *testing.T) {
+	ws := t.TempDir()
+	manager, tools := newProcessTools(t, ws)
+	ctx := sessionContext("a")
+
+	started := runTool[ExecBackgroundOutput](t, ctx, tools["exec_background"], ExecInput{Command: "sleep 30 & sleep 30"})
+	if started.ID == "" {
+		t.Fatalf("expected process to start, got %+v", started)
+	}
+
+	other := sessionContext("b")
+	if list := runTool[ProcessListOutput](t, other, tools["process_list"], struct{}{}); len(list.Processes) != 0 {
+		t.Fatalf("other session must not see processes, got %+v", list.Processes)
+	}
+	raw, _ := json.Marshal(ProcessIDInput{ID: started.ID})
+	if _, err := tools["process_kill"].InvokableRun(other, string(raw)); err == nil {
+		t.Fatal("other session must not kill the process")
+	}
+
+	states, err := ReadProcessStates(ws)
+	if err != nil || len(states) != 1 || states[0].Session != "cli:a" || !states[0].Running {
+		t.Fatalf("expected persisted running process, got %+v (%v)", states, err)
+	}
+
+	killed := runTool[ProcessOutput](t, ctx, tools["process_kill"], ProcessIDInput{ID: started.ID})
+	if killed.Running {
+		t.Fatalf("expected process to be stopped, got %+v", killed)
+	}
+	if processAlive(started.PID) {
+		t.Fatalf("process %d still alive after kill", started.PID)
+	}
+	if list := manager.List("cli:a"); len(list) != 0 {
+		t.Fatalf("expected killed process to be removed, got %+v", list)
+	}
+	if states, _ := ReadProcessStates(ws); len(states) != 0 {
+		t.Fatalf("expected empty state after kill, got %+v", states)
+	}
+}
+
+func TestProcessManager_LimitAndEndSession(t *testing.T) {
+	ws := t.TempDir()
+	manager, tools := newProcessTools(t, ws)
+	ctx := sessionContext("a")
+
+	var pids []int
+	for i := 0; i < 2; i++ {
+		started := runTool[ExecBackgroundOutput](t, ctx, tools["exec_background"], ExecInput{Command: "sleep 30"})
+		if started.ID == "" {
+			t.Fatalf("expected process %d to start, got %+v", i, started)
+		}
+		pids = append(pids, started.PID)
+	}
+	over := runTool[ExecBackgroundOutput](t, ctx, tools["exec_background"], ExecInput{Command: "sleep 30"})
+	if over.ID != "" || !strings.Contains(over.Error, "running background processes") {
+		t.Fatalf("expected per-session limit, got %+v", over)
+	}
+	if started := runTool[ExecBackgroundOutput](t, sessionContext("b"), tools["exec_background"], ExecInput{Command: "true"}); started.ID == "" {
+		t.Fatalf("limit must be per session, got %+v", started)
+	}
+
+	manager.EndSession("cli:a")
+	for _, pid := range pids {
+		if processAlive(pid) {
+			t.Fatalf("process %d survived session end", pid)
+		}
+	}
+	if list := manager.List("cli:a"); len(list) != 0 {
+		t.Fatalf("expected no processes after session end, got %+v", list)
+	}
+}
+
+func TestProcessManager_ConcurrentStartsRespectLimit(t *testing.T) {
+	manager := NewProcessManager("", 2)
+	t.Cleanup(manager.Close)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	started := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := manager.Start("cli:a", "sleep 30", exec.Command("sleep", "30")); err == nil {
+				mu.Lock()
+				started++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if started != 2 || len(manager.List("cli:a")) != 2 {
+		t.Fatalf("expected exactly 2 processes to start, got %d", started)
+	}
+}
+
+func TestProcessManager_WriteDoesNotBlockOnFullPipe(t *testing.T) {
+	manager := NewProcessManager("", 2)
+	t.Cleanup(manager.Close)
+	info, err := manager.Start("cli:a", "sleep 30", exec.Command("sleep", "30"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	if _, err := manager.Write(ctx, "cli:a", info.ID, strings.Repeat("x", 1<<20), false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the write to give up, got %v", err)
+	}
+	if time.Since(begin) > 5*time.Second {
+		t.Fatal("write blocked past its deadline")
+	}
+	if _, err := manager.Write(context.Background(), "cli:a", info.ID, "y", false); err == nil || !strings.Contains(err.Error(), "not read") {
+		t.Fatalf("expected further input to be refused while a write is pending, got %v", err)
+	}
+}
+
+func TestProcessTools_AppliesCommandRules(t *testing.T) {
+	_, tools := newProcessTools(t, t.TempDir())
+	out := runTool[ExecBackgroundOutput](t, sessionContext("a"), tools["exec_background"], ExecInput{Command: "rm -rf /"})
+	if out.ID != "" || out.Rule != "rm-system-path" {
+		t.Fatalf("expected command to be blocked, got %+v", out)
+	}
+}
+
+func TestReadProcessStates_RemovesDeadOwners(t *testing.T) {
+	ws := t.TempDir()
+	dir := processStateDir(ws)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// pid 0x7ffffffe is never a live process
+	stale := filepath.Join(dir, "2147483646.json")
+	if err := os.WriteFile(stale, []byte(`[{"id":"p1","session":"cli:x","running":true}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := ReadProcessStates(ws)
+	if err != nil || len(states) != 0 {
+		t.Fatalf("expected stale state to be ignored, got %+v (%v)", states, err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatal("expected stale state file to be removed")
+	}
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// maxProcessWait bounds how long process_output blocks waiting for output
+const maxProcessWait = 30 * time.Second
+
+// ExecBackgroundOutput result of exec_background tool
+type ExecBackgroundOutput struct {
+	ID    string `json:"id,omitempty"`
+	PID   int    `json:"pid,omitempty"`
+	Error string `json:"error,omitempty"`
+	Rule  string `json:"rule,omitempty"`
+}
+
+// ProcessOutputInput parameters for process_output tool
+type ProcessOutputInput struct {
+	ID          string `json:"id" jsonschema:"required,description=Background process id"`
+	WaitSeconds int    `json:"wait_seconds" jsonschema:"description=Seconds to wait for new output (max 30)"`
+}
+
+// ProcessInputInput parameters for process_input tool
+type ProcessInputInput struct {
+	ID         string `json:"id" jsonschema:"required,description=Background process id"`
+	Input      string `json:"input" jsonschema:"description=Text written to stdin; include a trailing newline for line-based programs"`
+	CloseStdin bool   `json:"close_stdin" jsonschema:"description=Close stdin after writing"`
+}
+
+// ProcessInputOutput result of process_input tool
+type ProcessInputOutput struct {
+	Written int `json:"written"`
+}
+
+// ProcessIDInput identifies a background process
+type ProcessIDInput struct {
+	ID string `json:"id" jsonschema:"required,description=Background process id"`
+}
+
+// ProcessListOutput result of process_list tool
+type ProcessListOutput struct {
+	Processes []ProcessInfo `json:"processes"`
+}
+
+// NewProcessTools creates exec_background and the tools that manage the
+// processes it starts. Commands go through the same rules, workspace
+// checks and sandbox as exec.
+func NewProcessTools(manager *ProcessManager, restrictToWorkspace bool, workspaceDir string, opts ...ExecOption) ([]tool.InvokableTool, error) {
+	impl, err := newExecToolImpl(0, restrictToWorkspace, workspaceDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	start := func(ctx context.Context, input *ExecInput) (*ExecBackgroundOutput, error) {
+		cmd, verdict, blocked, err := impl.prepare(ctx, context.Background(), "exec_background", input)
+		if err != nil {
+			return nil, err
+		}
+		if blocked != nil {
+			return &ExecBackgroundOutput{Error: blocked.Stderr, Rule: blocked.Rule}, nil
+		}
+		inv, _ := InvocationFromContext(ctx)
+		info, err := manager.Start(inv.SessionKey(), input.Command, cmd)
+		if err != nil {
+			return &ExecBackgroundOutput{Error: err.Error(), Rule: verdict.Rule}, nil
+		}
+		return &ExecBackgroundOutput{ID: info.ID, PID: info.PID, Rule: verdict.Rule}, nil
+	}
+
+	output := func(ctx context.Context, input *ProcessOutputInput) (*ProcessOutput, error) {
+		inv, _ := InvocationFromContext(ctx)
+		wait := min(time.Duration(max(input.WaitSeconds, 0))*time.Second, maxProcessWait)
+		out, err := manager.Output(inv.SessionKey(), input.ID, wait)
+		if err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+
+	write := func(ctx context.Context, input *ProcessInputInput) (*ProcessInputOutput, error) {
+		inv, _ := InvocationFromContext(ctx)
+		n, err := manager.Write(ctx, inv.SessionKey(), input.ID, input.Input, input.CloseStdin)
+		if err != nil {
+			return nil, err
+		}
+		return &ProcessInputOutput{Written: n}, nil
+	}
+
+	kill := func(ctx context.Context, input *ProcessIDInput) (*ProcessOutput, error) {
+		inv, _ := InvocationFromContext(ctx)
+		out, err := manager.Kill(inv.SessionKey(), input.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+
+	list := func(ctx context.Context, input *struct{}) (*ProcessListOutput, error) {
+		inv, _ := InvocationFromContext(ctx)
+		return &ProcessListOutput{Processes: manager.List(inv.SessionKey())}, nil
+	}
+
+	var tools []tool.InvokableTool
+	for _, build := range []func() (tool.InvokableTool, error){
+		func() (tool.InvokableTool, error) {
+			return utils.InferTool("exec_background",
+				"Start a long-running shell command (dev server, build, log tail) without a timeout; returns a process id", start)
+		},
+		func() (tool.InvokableTool, error) {
+			return utils.InferTool("process_output",
+				"Read new stdout/stderr of a background process since the last read, and whether it is still running", output)
+		},
+		func() (tool.InvokableTool, error) {
+			return utils.InferTool("process_input", "Write to the stdin of a background process", write)
+		},
+		func() (tool.InvokableTool, error) {
+			return utils.InferTool("process_kill", "Kill a background process and return its remaining output", kill)
+		},
+		func() (tool.InvokableTool, error) {
+			return utils.InferTool("process_list", "List background processes of this conversation", list)
+		},
+	} {
+		t, err := build()
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, t)
+	}
+	return tools, nil
+}
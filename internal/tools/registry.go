@@ -11,13 +11,39 @@ import (
 
 // Registry manages tools by name
 type Registry struct {
-	mu    sync.RWMutex
-	tools map[string]tool.InvokableTool
+	mu        sync.RWMutex
+	tools     map[string]tool.InvokableTool
+	approvals *ApprovalPolicy
+	policies  *PolicySet
+	output    OutputLimits
 }
 
 // NewRegistry creates a new registry
 func NewRegistry() *Registry {
-	return &Registry{tools: make(map[string]tool.InvokableTool)}
+	approvals, _ := NewApprovalPolicy(nil, DefaultApprovalTimeout)
+	return &Registry{
+		tools:     make(map[string]tool.InvokableTool),
+		approvals: approvals,
+	}
+}
+
+// Approvals returns the approval policy applied by Execute
+func (r *Registry) Approvals() *ApprovalPolicy {
+	return r.approvals
+}
+
+// SetPolicies restricts tools per conversation; nil removes all restrictions
+func (r *Registry) SetPolicies(policies *PolicySet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policies = policies
+}
+
+// SetOutputLimits caps tool results returned by Execute
+func (r *Registry) SetOutputLimits(limits OutputLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.output = limits
 }
 
 // Register adds a tool to registry
@@ -49,13 +75,18 @@ func (r *Registry) Get(name string) (tool.InvokableTool, bool) {
 	return tool, ok
 }
 
-// GetToolInfos returns all tool schemas for ChatModel binding
+// GetToolInfos returns the tool schemas for ChatModel binding. When ctx
+// carries an Invocation, only the tools its policies allow are returned.
 func (r *Registry) GetToolInfos(ctx context.Context) ([]*schema.ToolInfo, error) {
     r.mu.RLock()
     defer r.mu.RUnlock()
 
+    inv, scoped := InvocationFromContext(ctx)
     infos := make([]*schema.ToolInfo, 0, len(r.tools))
-    for _, t := range r.tools {
+    for name, t := range r.tools {
+        if scoped && !r.policies.Allowed(inv, name) {
+            continue
+        }
         info, err := t.Info(ctx)
         if err != nil {
             return nil, err
@@ -65,13 +96,31 @@ func (r *Registry) GetToolInfos(ctx context.Context) ([]*schema.ToolInfo, error)
     return infos, nil
 }
 
-// Execute runs a tool by name
+// Execute runs a tool by name after checking policies, waiting for approval
+// when required. Results over the tool's output budget are truncated.
 func (r *Registry) Execute(ctx context.Context, name string, argsJSON string) (string, error) {
     t, ok := r.Get(name)
     if !ok {
         return "", fmt.Errorf("tool not found: %s", name)
     }
-    return t.InvokableRun(ctx, argsJSON)
+    r.mu.RLock()
+    policies := r.policies
+    output := r.output
+    r.mu.RUnlock()
+    inv, _ := InvocationFromContext(ctx)
+    if err := policies.Check(inv, name, argsJSON); err != nil {
+        return "", err
+    }
+
+    ctx = context.WithValue(ctx, approvalPolicyKey{}, r.approvals)
+    if err := r.approvals.Authorize(ctx, name, argsJSON); err != nil {
+        return "", err
+    }
+    result, err := t.InvokableRun(ctx, argsJSON)
+    if err != nil {
+        return result, err
+    }
+    return output.limitOutput(name, argsJSON, result), nil
 }
 
 // Names returns all registered tool names
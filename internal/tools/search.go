@@ -0,0 +1,329 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+const (
+	defaultGlobLimit = 200
+	defaultGrepLimit = 100
+	maxGrepContext   = 10
+	maxGrepFileSize  = 10 << 20
+	maxGrepLineLen   = 500
+	binarySniffLen   = 8000
+)
+
+var errSearchLimit = errors.New("search limit reached")
+
+// GlobInput parameters for glob tool
+type GlobInput struct {
+	Pattern string `json:"pattern" jsonschema:"required,description=Glob relative to path such as **/*.go; ** matches any number of directories"`
+	Path    string `json:"path" jsonschema:"description=Directory to search (default workspace root)"`
+	Limit   int    `json:"limit" jsonschema:"description=Maximum number of results (default 200)"`
+}
+
+// GlobOutput result of glob tool
+type GlobOutput struct {
+	Files     []string `json:"files"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// GrepInput parameters for grep tool
+type GrepInput struct {
+	Pattern    string `json:"pattern" jsonschema:"required,description=Regular expression (RE2 syntax)"`
+	Path       string `json:"path" jsonschema:"description=File or directory to search (default workspace root)"`
+	Include    string `json:"include" jsonschema:"description=Only search files matching this glob such as *.go or src/**/*.ts"`
+	IgnoreCase bool   `json:"ignore_case" jsonschema:"description=Case-insensitive match"`
+	Context    int    `json:"context" jsonschema:"description=Lines of context before and after each match (max 10)"`
+	Limit      int    `json:"limit" jsonschema:"description=Maximum number of matches (default 100)"`
+	FilesOnly  bool   `json:"files_only" jsonschema:"description=Only list files containing a match"`
+}
+
+// GrepMatch is one matching line
+type GrepMatch struct {
+	Path   string   `json:"path"`
+	Line   int      `json:"line"`
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// GrepOutput result of grep tool
+type GrepOutput struct {
+	Matches   []GrepMatch `json:"matches,omitempty"`
+	Files     []string    `json:"files,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// searchTree walks the files under start, skipping .git, the trash and anything
+// excluded by .gitignore files between the workspace root and start.
+// fn receives the absolute path and the path reported to the model.
+func searchTree(workspacePath, start string, fn func(path, display string) error) error {
+	root := workspacePath
+	if root == "" {
+		root = start
+		if info, err := os.Stat(start); err == nil && !info.IsDir() {
+			root = filepath.Dir(start)
+		}
+	}
+	root, err := resolveWorkspace(root)
+	if err != nil {
+		return err
+	}
+	relToRoot := func(p string) string {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return filepath.ToSlash(p)
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	matcher := newIgnoreMatcher(root)
+	startRel := relToRoot(start)
+	if startRel == "." {
+		startRel = ""
+	}
+	matcher.loadParents(parentDir(startRel))
+
+	return filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == start {
+				return err
+			}
+			return nil
+		}
+		rel := relToRoot(p)
+		if rel == "." {
+			rel = ""
+		}
+		if d.IsDir() {
+			if rel != "" && (d.Name() == ".git" || rel == trashDirName || matcher.ignored(rel, true)) {
+				return filepath.SkipDir
+			}
+			matcher.loadDir(rel)
+			return nil
+		}
+		if !d.Type().IsRegular() || (p != start && matcher.ignored(rel, false)) {
+			return nil
+		}
+		return fn(p, rel)
+	})
+}
+
+func parentDir(rel string) string {
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		return rel[:i]
+	}
+	return ""
+}
+
+// NewGlobTool creates the glob tool
+func NewGlobTool(workspacePath string) (tool.InvokableTool, error) {
+	run := func(ctx context.Context, input *GlobInput) (*GlobOutput, error) {
+		if input.Pattern == "" {
+			return nil, fmt.Errorf("pattern is required")
+		}
+		start, err := searchStart(workspacePath, input.Path)
+		if err != nil {
+			return nil, err
+		}
+		limit := input.Limit
+		if limit <= 0 {
+			limit = defaultGlobLimit
+		}
+
+		out := &GlobOutput{Files: []string{}}
+		err = searchTree(workspacePath, start, func(p, display string) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(start, p)
+			if err != nil || !matchGlob(input.Pattern, filepath.ToSlash(rel)) {
+				return nil
+			}
+			if len(out.Files) == limit {
+				out.Truncated = true
+				return errSearchLimit
+			}
+			out.Files = append(out.Files, display)
+			return nil
+		})
+		if err != nil && !errors.Is(err, errSearchLimit) {
+			return nil, err
+		}
+		sort.Strings(out.Files)
+		return out, nil
+	}
+	return utils.InferTool("glob",
+		"Find files by glob pattern, recursively and honoring .gitignore", run)
+}
+
+// NewGrepTool creates the grep tool
+func NewGrepTool(workspacePath string) (tool.InvokableTool, error) {
+	run := func(ctx context.Context, input *GrepInput) (*GrepOutput, error) {
+		expr := input.Pattern
+		if expr == "" {
+			return nil, fmt.Errorf("pattern is required")
+		}
+		if input.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		start, err := searchStart(workspacePath, input.Path)
+		if err != nil {
+			return nil, err
+		}
+		limit := input.Limit
+		if limit <= 0 {
+			limit = defaultGrepLimit
+		}
+		contextLines := min(max(input.Context, 0), maxGrepContext)
+
+		out := &GrepOutput{}
+		found := 0
+		err = searchTree(workspacePath, start, func(p, display string) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if input.Include != "" && !matchInclude(input.Include, display) {
+				return nil
+			}
+			matches, err := grepFile(p, re, contextLines, limit-found, input.FilesOnly)
+			if err != nil || len(matches) == 0 {
+				return nil
+			}
+			if input.FilesOnly {
+				out.Files = append(out.Files, display)
+				found++
+			} else {
+				for i := range matches {
+					matches[i].Path = display
+				}
+				out.Matches = append(out.Matches, matches...)
+				found += len(matches)
+			}
+			if found >= limit {
+				out.Truncated = true
+				return errSearchLimit
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errSearchLimit) {
+			return nil, err
+		}
+		return out, nil
+	}
+	return utils.InferTool("grep",
+		"Search file contents with a regular expression, recursively and honoring .gitignore; skips binary files", run)
+}
+
+func searchStart(workspacePath, target string) (string, error) {
+	if target == "" {
+		target = "."
+	}
+	p, err := validatePath(workspacePath, target)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(p); err != nil {
+		return "", err
+	}
+	return resolveWorkspace(p)
+}
+
+// matchInclude matches patterns without a slash against the file name only
+func matchInclude(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		return matchGlob(pattern, filepath.Base(filepath.FromSlash(rel)))
+	}
+	return matchGlob(pattern, rel)
+}
+
+// grepFile returns up to limit matching lines; binary and oversized files are skipped
+func grepFile(path string, re *regexp.Regexp, contextLines, limit int, firstOnly bool) ([]GrepMatch, error) {
+	f, err := openNoFollow(path, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err != nil || info.Size() > maxGrepFileSize {
+		return nil, err
+	}
+	reader := bufio.NewReader(f)
+	if head, _ := reader.Peek(binarySniffLen); bytes.IndexByte(head, 0) >= 0 {
+		return nil, nil
+	}
+
+	var (
+		matches []GrepMatch
+		before  []string
+		pending []int // indexes of matches still collecting trailing context
+		lineNo  int
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
+			if err == io.EOF {
+				break
+			}
+			return matches, err
+		}
+		lineNo++
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		text := clipLine(line)
+
+		still := pending[:0]
+		for _, i := range pending {
+			matches[i].After = append(matches[i].After, text)
+			if len(matches[i].After) < contextLines {
+				still = append(still, i)
+			}
+		}
+		pending = still
+
+		if len(matches) < limit && re.MatchString(line) {
+			matches = append(matches, GrepMatch{Line: lineNo, Text: text, Before: append([]string(nil), before...)})
+			if firstOnly {
+				return matches, nil
+			}
+			if contextLines > 0 {
+				pending = append(pending, len(matches)-1)
+			}
+		} else if len(matches) >= limit && len(pending) == 0 {
+			break
+		}
+
+		if contextLines > 0 {
+			before = append(before, text)
+			if len(before) > contextLines {
+				before = before[1:]
+			}
+		}
+	}
+	return matches, nil
+}
+
+func clipLine(s string) string {
+	if len(s) <= maxGrepLineLen {
+		return s
+	}
+	return s[:validPrefix(s, maxGrepLineLen)] + "..."
+}
@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newSearchWorkspace lays out a small repository with ignored files
+func newSearchWorkspace(t *testing.T) string {
+	t.Helper()
+	ws := t.TempDir()
+	files := map[string]string{
+		".gitignore":            "*.log\nbuild/\n/secret.txt\n!keep.log\n",
+		"main.go":               "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n",
+		"README.md":             "# Demo\nHello world\n",
+		"secret.txt":            "hello secret\n",
+		"debug.log":             "hello log\n",
+		"keep.log":              "hello kept\n",
+		"build/out.go":          "package build // hello\n",
+		"pkg/util/util.go":      "package util\n\n// Hello returns a greeting\nfunc Hello() string { return \"hello\" }\n",
+		"pkg/util/secret.txt":   "not anchored, hello\n",
+		"pkg/.gitignore":        "generated.go\n",
+		"pkg/generated.go":      "package pkg // hello\n",
+		"pkg/util/generated.go": "package util // hello\n",
+		".git/config":           "hello\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(ws, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, path, content)
+	}
+	writeTestFile(t, filepath.Join(ws, "image.bin"), "hello\x00\x01\x02")
+	return ws
+}
+
+func runGlob(t *testing.T, ws string, input GlobInput) GlobOutput {
+	t.Helper()
+	globTool, err := NewGlobTool(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, _ := json.Marshal(input)
+	out, err := globTool.InvokableRun(context.Background(), string(args))
+	if err != nil {
+		t.Fatalf("glob error: %v", err)
+	}
+	var result GlobOutput
+	_ = json.Unmarshal([]byte(out), &result)
+	return result
+}
+
+func runGrep(t *testing.T, ws string, input GrepInput) GrepOutput {
+	t.Helper()
+	grepTool, err := NewGrepTool(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, _ := json.Marshal(input)
+	out, err := grepTool.InvokableRun(context.Background(), string(args))
+	if err != nil {
+		t.Fatalf("grep error: %v", err)
+	}
+	var result GrepOutput
+	_ = json.Unmarshal([]byte(out), &result)
+	return result
+}
+
+func TestGlob_HonorsGitignore(t *testing.T) {
+	ws := newSearchWorkspace(t)
+
+	got := runGlob(t, ws, GlobInput{Pattern: "**/*.go"})
+	want := []string{"main.go", "pkg/util/util.go"}
+	if !reflect.DeepEqual(got.Files, want) {
+		t.Fatalf("expected %v, got %v", want, got.Files)
+	}
+
+	got = runGlob(t, ws, GlobInput{Pattern: "*"})
+	want = []string{".gitignore", "README.md", "image.bin", "keep.log", "main.go"}
+	if !reflect.DeepEqual(got.Files, want) {
+		t.Fatalf("expected %v, got %v", want, got.Files)
+	}
+
+	got = runGlob(t, ws, GlobInput{Pattern: "*.txt", Path: "pkg/util"})
+	if !reflect.DeepEqual(got.Files, []string{"pkg/util/secret.txt"}) {
+		t.Fatalf("anchored pattern must only apply at its own level, got %v", got.Files)
+	}
+
+	got = runGlob(t, ws, GlobInput{Pattern: "**", Limit: 2})
+	if len(got.Files) != 2 || !got.Truncated {
+		t.Fatalf("expected limit to truncate, got %+v", got)
+	}
+}
+
+func TestGrep_RegexContextAndFiltering(t *testing.T) {
+	ws := newSearchWorkspace(t)
+
+	got := runGrep(t, ws, GrepInput{Pattern: "hello", IgnoreCase: true, FilesOnly: true})
+	want := []string{"README.md", "keep.log", "main.go", "pkg/util/secret.txt", "pkg/util/util.go"}
+	if !reflect.DeepEqual(got.Files, want) {
+		t.Fatalf("expected %v, got %v", want, got.Files)
+	}
+
+	got = runGrep(t, ws, GrepInput{Pattern: `func \w+\(`, Include: "*.go", Context: 1})
+	if len(got.Matches) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", got.Matches)
+	}
+	first := got.Matches[0]
+	if first.Path != "main.go" || first.Line != 3 || first.Text != "func main() {" ||
+		!reflect.DeepEqual(first.Before, []string{""}) || !reflect.DeepEqual(first.After, []string{"\tprintln(\"hello\")"}) {
+		t.Fatalf("unexpected first match: %+v", first)
+	}
+	if got.Matches[1].Path != "pkg/util/util.go" || got.Matches[1].Line != 4 {
+		t.Fatalf("unexpected second match: %+v", got.Matches[1])
+	}
+
+	got = runGrep(t, ws, GrepInput{Pattern: "hello", Limit: 1})
+	if len(got.Matches) != 1 || !got.Truncated {
+		t.Fatalf("expected limit to truncate, got %+v", got)
+	}
+
+	// An explicitly named file is searched even when ignored
+	got = runGrep(t, ws, GrepInput{Pattern: "hello", Path: "debug.log"})
+	if len(got.Matches) != 1 || got.Matches[0].Path != "debug.log" {
+		t.Fatalf("expected explicit file to be searched, got %+v", got)
+	}
+}
+
+func TestGrep_AnchorsAtLineEnd(t *testing.T) {
+	ws := newSearchWorkspace(t)
+	if err := os.WriteFile(filepath.Join(ws, "windows.txt"), []byte("first {\r\nreturn\r\nlast {"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := runGrep(t, ws, GrepInput{Pattern: `^func.*\{$`, Include: "*.go"})
+	if len(got.Matches) != 1 || got.Matches[0].Text != "func main() {" {
+		t.Fatalf("expected $ to match before the newline, got %+v", got.Matches)
+	}
+	got = runGrep(t, ws, GrepInput{Pattern: `\{$|^return$`, Path: "windows.txt"})
+	if len(got.Matches) != 3 || got.Matches[1].Text != "return" {
+		t.Fatalf("expected $ to match before CRLF and at EOF, got %+v", got.Matches)
+	}
+}
+
+func TestGrep_RejectsOutsideWorkspace(t *testing.T) {
+	ws := newSearchWorkspace(t)
+	grepTool, _ := NewGrepTool(ws)
+	_, err := grepTool.InvokableRun(context.Background(), `{"pattern":"root","path":"../"}`)
+	if err == nil || !strings.Contains(err.Error(), "outside workspace") {
+		t.Fatalf("expected path outside workspace to be rejected, got %v", err)
+	}
+}
+
+func TestIgnoreMatcher_Rules(t *testing.T) {
+	m := &ignoreMatcher{loaded: map[string]bool{}}
+	for _, line := range []string{"# comment", "", "*.o", "!important.o", "/root-only", "docs/**/*.tmp", "cache/"} {
+		if r, ok := parseIgnoreLine("", line); ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+	if r, ok := parseIgnoreLine("sub", "local"); ok {
+		m.rules = append(m.rules, r)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"a.o", false, true},
+		{"x/y/b.o", false, true},
+		{"x/important.o", false, false},
+		{"root-only", false, true},
+		{"x/root-only", false, false},
+		{"docs/a/b/c.tmp", false, true},
+		{"docs/c.tmp", false, true},
+		{"other/c.tmp", false, false},
+		{"cache", true, true},
+		{"cache", false, false},
+		{"sub/local", false, true},
+		{"local", false, false},
+	}
+	for _, tc := range tests {
+		if got := m.ignored(tc.path, tc.isDir); got != tc.want {
+			t.Errorf("ignored(%q, %v) = %v, want %v", tc.path, tc.isDir, got, tc.want)
+		}
+	}
+}
@@ -2,12 +2,15 @@ package tools
 
 import (
     "context"
+    "encoding/json"
+    "errors"
     "fmt"
     "os/exec"
     "runtime"
     "strings"
     "time"
 
+    "github.com/MEKXH/golem/internal/sandbox"
     "github.com/cloudwego/eino/components/tool"
     "github.com/cloudwego/eino/components/tool/utils"
 )
@@ -23,32 +26,90 @@ type ExecOutput struct {
     Stdout   string `json:"stdout"`
     Stderr   string `json:"stderr"`
     ExitCode int    `json:"exit_code"`
-}
-
-// Dangerous commands to block
-var dangerousCommands = []string{
-    "rm -rf /",
-    "rm -rf ~",
-    "mkfs",
-    "dd if=",
-    ":(){:|:&};:",
-    "format c:",
-    "del /f /s /q",
+    Rule     string `json:"rule,omitempty"`
 }
 
 type execToolImpl struct {
     timeout             time.Duration
     restrictToWorkspace bool
     workspaceDir        string
+    sandbox             sandbox.Sandbox
+    rules               *CommandAnalyzer
+}
+
+// ExecOption customizes the exec tool
+type ExecOption func(*execToolImpl)
+
+// WithSandbox runs commands through an isolation backend
+func WithSandbox(sb sandbox.Sandbox) ExecOption {
+    return func(e *execToolImpl) {
+        e.sandbox = sb
+    }
+}
+
+// WithCommandRules replaces the default command rules
+func WithCommandRules(a *CommandAnalyzer) ExecOption {
+    return func(e *execToolImpl) {
+        e.rules = a
+    }
 }
 
 func (e *execToolImpl) execute(ctx context.Context, input *ExecInput) (*ExecOutput, error) {
-    cmdLower := strings.ToLower(input.Command)
-    for _, dangerous := range dangerousCommands {
-        if strings.Contains(cmdLower, dangerous) {
+    timeoutCtx, cancel := context.WithTimeout(ctx, e.timeout)
+    defer cancel()
+
+    cmd, verdict, blocked, err := e.prepare(ctx, timeoutCtx, "exec", input)
+    if err != nil || blocked != nil {
+        return blocked, err
+    }
+
+    var stdout, stderr strings.Builder
+    cmd.Stdout = &stdout
+    cmd.Stderr = &stderr
+
+    err = cmd.Run()
+    exitCode := 0
+    if err != nil {
+        if exitErr, ok := err.(*exec.ExitError); ok {
+            exitCode = exitErr.ExitCode()
+        } else {
             return &ExecOutput{
-                Stderr:   fmt.Sprintf("Blocked dangerous command: %s", dangerous),
+                Stderr:   err.Error(),
+                ExitCode: 1,
+            }, nil
+        }
+    }
+
+    return &ExecOutput{
+        Stdout:   stdout.String(),
+        Stderr:   stderr.String(),
+        ExitCode: exitCode,
+        Rule:     verdict.Rule,
+    }, nil
+}
+
+// prepare applies the command rules and builds the command bound to runCtx.
+// A non-nil ExecOutput explains why the command must not run.
+func (e *execToolImpl) prepare(ctx, runCtx context.Context, toolName string, input *ExecInput) (*exec.Cmd, CommandVerdict, *ExecOutput, error) {
+    verdict := e.rules.Evaluate(input.Command)
+    switch verdict.Action {
+    case RuleDeny:
+        return nil, verdict, &ExecOutput{
+            Stderr:   fmt.Sprintf("Blocked by rule %s: %s", verdict.Rule, verdict.Command),
+            ExitCode: 1,
+            Rule:     verdict.Rule,
+        }, nil
+    case RuleAsk:
+        args, _ := json.Marshal(input)
+        reason := fmt.Sprintf("command matches rule %s: %s", verdict.Rule, verdict.Command)
+        if err := RequireApproval(ctx, toolName, string(args), "command rule "+verdict.Rule, reason); err != nil {
+            if !errors.Is(err, ErrApprovalDenied) {
+                return nil, verdict, nil, err
+            }
+            return nil, verdict, &ExecOutput{
+                Stderr:   fmt.Sprintf("Not approved (rule %s): %v", verdict.Rule, err),
                 ExitCode: 1,
+                Rule:     verdict.Rule,
             }, nil
         }
     }
@@ -58,7 +119,7 @@ func (e *execToolImpl) execute(ctx context.Context, input *ExecInput) (*ExecOutp
         if input.WorkingDir != "" {
             safePath, err := validatePath(e.workspaceDir, input.WorkingDir)
             if err != nil {
-                return &ExecOutput{
+                return nil, verdict, &ExecOutput{
                     Stderr:   fmt.Sprintf("Access denied: %v", err),
                     ExitCode: 1,
                 }, nil
@@ -75,47 +136,52 @@ func (e *execToolImpl) execute(ctx context.Context, input *ExecInput) (*ExecOutp
         }
     }
 
-    timeoutCtx, cancel := context.WithTimeout(ctx, e.timeout)
-    defer cancel()
-
     var cmd *exec.Cmd
-    if runtime.GOOS == "windows" {
-        cmd = exec.CommandContext(timeoutCtx, "cmd", "/C", input.Command)
+    if e.sandbox != nil {
+        if workingDir == "" {
+            workingDir = e.workspaceDir
+        }
+        sandboxed, err := e.sandbox.Command(runCtx, input.Command, workingDir)
+        if err != nil {
+            return nil, verdict, &ExecOutput{
+                Stderr:   fmt.Sprintf("Sandbox error: %v", err),
+                ExitCode: 1,
+            }, nil
+        }
+        cmd = sandboxed
+    } else if runtime.GOOS == "windows" {
+        cmd = exec.CommandContext(runCtx, "cmd", "/C", input.Command)
     } else {
-        cmd = exec.CommandContext(timeoutCtx, "sh", "-c", input.Command)
+        cmd = exec.CommandContext(runCtx, "sh", "-c", input.Command)
     }
     cmd.Dir = workingDir
+    return cmd, verdict, nil, nil
+}
 
-    var stdout, stderr strings.Builder
-    cmd.Stdout = &stdout
-    cmd.Stderr = &stderr
-
-    err := cmd.Run()
-    exitCode := 0
+// NewExecTool creates the exec tool
+func NewExecTool(timeoutSec int, restrictToWorkspace bool, workspaceDir string, opts ...ExecOption) (tool.InvokableTool, error) {
+    impl, err := newExecToolImpl(timeoutSec, restrictToWorkspace, workspaceDir, opts)
     if err != nil {
-        if exitErr, ok := err.(*exec.ExitError); ok {
-            exitCode = exitErr.ExitCode()
-        } else {
-            return &ExecOutput{
-                Stderr:   err.Error(),
-                ExitCode: 1,
-            }, nil
-        }
+        return nil, err
     }
-
-    return &ExecOutput{
-        Stdout:   stdout.String(),
-        Stderr:   stderr.String(),
-        ExitCode: exitCode,
-    }, nil
+    return utils.InferTool("exec", "Execute a shell command", impl.execute)
 }
 
-// NewExecTool creates the exec tool
-func NewExecTool(timeoutSec int, restrictToWorkspace bool, workspaceDir string) (tool.InvokableTool, error) {
+func newExecToolImpl(timeoutSec int, restrictToWorkspace bool, workspaceDir string, opts []ExecOption) (*execToolImpl, error) {
     impl := &execToolImpl{
         timeout:             time.Duration(timeoutSec) * time.Second,
         restrictToWorkspace: restrictToWorkspace,
         workspaceDir:        workspaceDir,
     }
-    return utils.InferTool("exec", "Execute a shell command", impl.execute)
+    for _, opt := range opts {
+        opt(impl)
+    }
+    if impl.rules == nil {
+        rules, err := NewCommandAnalyzer(nil, true)
+        if err != nil {
+            return nil, err
+        }
+        impl.rules = rules
+    }
+    return impl, nil
 }
@@ -0,0 +1,95 @@
+//go:build linux
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/sandbox"
+)
+
+func TestExecTool_SandboxConfinesWrites(t *testing.T) {
+	if err := exec.Command("unshare", "-Ur", "true").Run(); err != nil {
+		t.Skipf("user namespaces unavailable: %v", err)
+	}
+	workspace := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "escape.txt")
+
+	sb, err := sandbox.New(sandbox.Options{Backend: sandbox.BackendNamespace, Workspace: workspace})
+	if err != nil {
+		t.Fatalf("sandbox.New error: %v", err)
+	}
+	tool, err := NewExecTool(10, true, workspace, WithSandbox(sb))
+	if err != nil {
+		t.Fatalf("NewExecTool error: %v", err)
+	}
+
+	run := func(command string) ExecOutput {
+		t.Helper()
+		result, err := tool.InvokableRun(context.Background(), fmt.Sprintf(`{"command": %q}`, command))
+		if err != nil {
+			t.Fatalf("InvokableRun error: %v", err)
+		}
+		var out ExecOutput
+		if err := json.Unmarshal([]byte(result), &out); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		return out
+	}
+
+	if out := run("echo ok > result.txt"); out.ExitCode != 0 {
+		t.Fatalf("expected workspace write to succeed: %+v", out)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "result.txt")); err != nil {
+		t.Fatalf("expected result.txt in workspace: %v", err)
+	}
+
+	if out := run("touch " + outside); out.ExitCode == 0 {
+		t.Fatalf("expected write outside workspace to fail: %+v", out)
+	}
+	if _, err := os.Stat(outside); err == nil {
+		t.Fatal("SECURITY FAILURE: sandboxed command wrote outside workspace")
+	}
+}
+
+func TestPluginTool_SandboxConfinesWrites(t *testing.T) {
+	if err := exec.Command("unshare", "-Ur", "true").Run(); err != nil {
+		t.Skipf("user namespaces unavailable: %v", err)
+	}
+	workspace := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "escape.txt")
+
+	sb, err := sandbox.New(sandbox.Options{Backend: sandbox.BackendNamespace, Workspace: workspace})
+	if err != nil {
+		t.Fatalf("sandbox.New error: %v", err)
+	}
+	// The sandbox has a private /tmp, so the plugin lives in the (temporary) workspace
+	p := writePlugin(t, filepath.Join(workspace, "plugins", "greet"), fmt.Sprintf(`read input
+echo "$input" > result.json
+touch %s 2>/dev/null && echo '{"escaped":true}' || echo '{"escaped":false}'
+`, outside))
+	tool, err := NewPluginTool(p, 10, workspace, WithSandbox(sb))
+	if err != nil {
+		t.Fatalf("NewPluginTool error: %v", err)
+	}
+
+	out, err := tool.InvokableRun(context.Background(), `{"name":"Ada"}`)
+	if err != nil {
+		t.Fatalf("InvokableRun error: %v", err)
+	}
+	if out != `{"escaped":false}` {
+		t.Fatalf("unexpected output %s", out)
+	}
+	if data, err := os.ReadFile(filepath.Join(workspace, "result.json")); err != nil || string(data) != "{\"name\":\"Ada\"}\n" {
+		t.Fatalf("expected the arguments written in the workspace, got %q %v", data, err)
+	}
+	if _, err := os.Stat(outside); err == nil {
+		t.Fatal("SECURITY FAILURE: sandboxed plugin wrote outside workspace")
+	}
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/MEKXH/golem/internal/skills"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// LoadSkillInput parameters for load_skill tool
+type LoadSkillInput struct {
+	Name string `json:"name" jsonschema:"required,description=Skill name as listed in the system prompt"`
+}
+
+// LoadSkillOutput result of load_skill tool
+type LoadSkillOutput struct {
+	Name         string   `json:"name"`
+	Dir          string   `json:"dir"`
+	Instructions string   `json:"instructions"`
+	Files        []string `json:"files,omitempty"`
+}
+
+// NewLoadSkillTool creates the load_skill tool. Dir is relative to the
+// workspace so the instructions' scripts can be run with exec.
+func NewLoadSkillTool(workspacePath string, loader *skills.Loader) (tool.InvokableTool, error) {
+	return utils.InferTool("load_skill",
+		"Load the full instructions of an installed skill before doing a task it covers",
+		func(ctx context.Context, input *LoadSkillInput) (*LoadSkillOutput, error) {
+			skill, instructions, files, err := loader.Load(input.Name)
+			if err != nil {
+				return nil, err
+			}
+			dir := skill.Dir
+			if rel, err := filepath.Rel(workspacePath, skill.Dir); err == nil {
+				dir = filepath.ToSlash(rel)
+			}
+			return &LoadSkillOutput{
+				Name:         skill.Name,
+				Dir:          dir,
+				Instructions: instructions,
+				Files:        files,
+			}, nil
+		})
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/skills"
+)
+
+func TestLoadSkillTool(t *testing.T) {
+	ws := t.TempDir()
+	dir := filepath.Join(ws, "skills", "deploy")
+	if err := os.MkdirAll(filepath.Join(dir, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, skills.FileName), "---\nname: deploy\ndescription: Ship the site\n---\nRun scripts/ship.sh.\n")
+	writeTestFile(t, filepath.Join(dir, "scripts", "ship.sh"), "echo ship\n")
+
+	loadTool, err := NewLoadSkillTool(ws, skills.NewLoader(filepath.Join(ws, "skills")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out LoadSkillOutput
+	if err := invokeJSON(t, loadTool, LoadSkillInput{Name: "deploy"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Dir != "skills/deploy" || out.Instructions != "Run scripts/ship.sh." || len(out.Files) != 1 {
+		t.Fatalf("unexpected output %+v", out)
+	}
+	if err := invokeJSON(t, loadTool, LoadSkillInput{Name: "nope"}, nil); err == nil {
+		t.Fatal("expected unknown skill to fail")
+	}
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// SpawnAgentName is the tool name; sub-agents never get it themselves
+const SpawnAgentName = "spawn_agent"
+
+// SpawnAgentInput parameters for spawn_agent tool
+type SpawnAgentInput struct {
+	Task          string   `json:"task" jsonschema:"required,description=Complete description of the delegated task, including any context the sub-agent needs; it cannot see this conversation"`
+	Tools         []string `json:"tools" jsonschema:"description=Tool names the sub-agent may use (default: all of yours except spawn_agent)"`
+	MaxIterations int      `json:"max_iterations" jsonschema:"description=Model calls the sub-agent may make (default and maximum set by configuration)"`
+	Background    bool     `json:"background" jsonschema:"description=Return immediately and post the result to this chat when done"`
+}
+
+// SpawnAgentOutput result of spawn_agent tool
+type SpawnAgentOutput struct {
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	Result     string `json:"result,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+}
+
+// Sub-agent statuses
+const (
+	SubAgentCompleted = "completed"
+	SubAgentRunning   = "running"
+	// SubAgentExhausted means the iteration budget ran out before a final answer
+	SubAgentExhausted = "exhausted"
+)
+
+// SubAgentRunner runs delegated tasks in a child agent
+type SubAgentRunner interface {
+	RunSubAgent(ctx context.Context, input SpawnAgentInput) (*SpawnAgentOutput, error)
+}
+
+// NewSpawnAgentTool creates the spawn_agent tool
+func NewSpawnAgentTool(runner SubAgentRunner) (tool.InvokableTool, error) {
+	return utils.InferTool(SpawnAgentName,
+		"Delegate a self-contained task to a sub-agent with its own fresh history, optionally limited to some tools. "+
+			"Returns the sub-agent's summary, or runs it in the background and reports to this chat when done.",
+		func(ctx context.Context, input *SpawnAgentInput) (*SpawnAgentOutput, error) {
+			input.Task = strings.TrimSpace(input.Task)
+			if input.Task == "" {
+				return nil, fmt.Errorf("task is empty")
+			}
+			for _, name := range input.Tools {
+				if name == SpawnAgentName {
+					return nil, fmt.Errorf("sub-agents cannot spawn further agents")
+				}
+			}
+			return runner.RunSubAgent(ctx, *input)
+		})
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeSubAgentRunner struct {
+	got   *SpawnAgentInput
+	calls int
+}
+
+func (r *fakeSubAgentRunner) RunSubAgent(ctx context.Context, input SpawnAgentInput) (*SpawnAgentOutput, error) {
+	r.calls++
+	r.got = &input
+	return &SpawnAgentOutput{ID: "sub-1", Status: SubAgentCompleted, Result: "done", Iterations: 1}, nil
+}
+
+func TestSpawnAgentTool(t *testing.T) {
+	runner := &fakeSubAgentRunner{}
+	tl, err := NewSpawnAgentTool(runner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out SpawnAgentOutput
+	input := SpawnAgentInput{Task: "  check the logs  ", Tools: []string{"read_file"}, MaxIterations: 3}
+	if err := invokeJSON(t, tl, input, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Status != SubAgentCompleted || out.Result != "done" {
+		t.Fatalf("unexpected output %+v", out)
+	}
+	if runner.got.Task != "check the logs" || runner.got.MaxIterations != 3 || len(runner.got.Tools) != 1 {
+		t.Fatalf("unexpected input passed to the runner %+v", runner.got)
+	}
+}
+
+func TestSpawnAgentTool_RejectsInvalidInput(t *testing.T) {
+	runner := &fakeSubAgentRunner{}
+	tl, err := NewSpawnAgentTool(runner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := invokeJSON(t, tl, SpawnAgentInput{Task: " "}, nil); err == nil {
+		t.Error("expected an empty task to be rejected")
+	}
+	if err := invokeJSON(t, tl, SpawnAgentInput{Task: "x", Tools: []string{SpawnAgentName}}, nil); err == nil {
+		t.Error("expected nested spawn_agent to be rejected")
+	}
+	if runner.calls != 0 {
+		t.Errorf("expected no sub-agent to run, got %d", runner.calls)
+	}
+}
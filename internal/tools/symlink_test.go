@@ -0,0 +1,218 @@
+//go:build !windows
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newEscapeWorkspace creates a workspace next to a sibling directory whose
+// name shares its prefix, plus an outside directory holding a secret
+func newEscapeWorkspace(t *testing.T) (ws, outside string) {
+	t.Helper()
+	root := t.TempDir()
+	ws = filepath.Join(root, "workspace")
+	outside = filepath.Join(root, "outside")
+	for _, dir := range []string{ws, outside, filepath.Join(root, "workspace-other"), filepath.Join(ws, "docs")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(outside, "secret.txt"), "secret")
+	writeTestFile(t, filepath.Join(root, "workspace-other", "secret.txt"), "sibling secret")
+	writeTestFile(t, filepath.Join(ws, "docs", "note.md"), "note")
+
+	links := map[string]string{
+		"etc-link":    outside,
+		"secret-link": filepath.Join(outside, "secret.txt"),
+		"dangling":    filepath.Join(outside, "created-by-write.txt"),
+		"docs-link":   filepath.Join(ws, "docs"),
+		"rel-escape":  "../outside",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(ws, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ws, outside
+}
+
+func TestValidatePath_EscapeAttempts(t *testing.T) {
+	ws, outside := newEscapeWorkspace(t)
+
+	denied := []string{
+		"../outside/secret.txt",
+		"docs/../../outside/secret.txt",
+		"../workspace-other/secret.txt",
+		filepath.Join(filepath.Dir(ws), "workspace-other", "secret.txt"),
+		filepath.Join(outside, "secret.txt"),
+		"/etc/passwd",
+		"etc-link/secret.txt",
+		"etc-link/new-file.txt",
+		"secret-link",
+		"rel-escape/secret.txt",
+		"dangling",
+		filepath.Join(ws, "etc-link", "secret.txt"),
+	}
+	for _, p := range denied {
+		if got, err := validatePath(ws, p); err == nil {
+			t.Errorf("validatePath(%q) = %q, expected access denied", p, got)
+		} else if !strings.Contains(err.Error(), "access denied") {
+			t.Errorf("validatePath(%q) error %v does not mention access denied", p, err)
+		}
+	}
+
+	realWS, _ := filepath.EvalSymlinks(ws)
+	allowed := map[string]string{
+		"docs/note.md":         filepath.Join(realWS, "docs", "note.md"),
+		"docs-link/note.md":    filepath.Join(realWS, "docs", "note.md"),
+		"docs-link/new/file":   filepath.Join(realWS, "docs", "new", "file"),
+		"docs/../new.txt":      filepath.Join(realWS, "new.txt"),
+		".":                    realWS,
+		"..workspace-dotfile":  filepath.Join(realWS, "..workspace-dotfile"),
+		filepath.Join(ws, "x"): filepath.Join(realWS, "x"),
+	}
+	for p, want := range allowed {
+		got, err := validatePath(ws, p)
+		if err != nil {
+			t.Errorf("validatePath(%q) unexpected error: %v", p, err)
+		} else if got != want {
+			t.Errorf("validatePath(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
+
+func TestValidatePath_SymlinkedWorkspace(t *testing.T) {
+	ws, _ := newEscapeWorkspace(t)
+	alias := filepath.Join(t.TempDir(), "alias")
+	if err := os.Symlink(ws, alias); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := validatePath(alias, "docs/note.md"); err != nil {
+		t.Fatalf("expected path inside symlinked workspace to be allowed: %v", err)
+	}
+	if _, err := validatePath(alias, filepath.Join(alias, "docs", "note.md")); err != nil {
+		t.Fatalf("expected absolute path through workspace alias to be allowed: %v", err)
+	}
+	if _, err := validatePath(alias, "etc-link/secret.txt"); err == nil {
+		t.Fatal("expected escape through symlinked workspace to be denied")
+	}
+}
+
+func TestFileTools_DoNotFollowEscapingSymlinks(t *testing.T) {
+	ws, outside := newEscapeWorkspace(t)
+	readTool, _ := NewReadFileTool(ws)
+	writeTool, _ := NewWriteFileTool(ws)
+	editTool, _ := NewEditFileTool(ws)
+	copyTool, _ := NewCopyFileTool(ws)
+	listTool, _ := NewListDirTool(ws)
+
+	if err := invokeJSON(t, readTool, ReadFileInput{Path: "secret-link"}, nil); err == nil {
+		t.Error("read_file followed a symlink out of the workspace")
+	}
+	if err := invokeJSON(t, listTool, ListDirInput{Path: "etc-link"}, nil); err == nil {
+		t.Error("list_dir followed a symlinked directory out of the workspace")
+	}
+	if err := invokeJSON(t, writeTool, WriteFileInput{Path: "dangling", Content: "pwned"}, nil); err == nil {
+		t.Error("write_file wrote through a dangling symlink")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "created-by-write.txt")); !os.IsNotExist(err) {
+		t.Error("dangling symlink target was created outside the workspace")
+	}
+	if err := invokeJSON(t, writeTool, WriteFileInput{Path: "etc-link/secret.txt", Content: "pwned"}, nil); err == nil {
+		t.Error("write_file wrote through a symlinked directory")
+	}
+	edit := EditFileInput{Path: "secret-link", Edits: []TextEdit{{OldText: "secret", NewText: "pwned"}}}
+	if err := invokeJSON(t, editTool, edit, nil); err == nil {
+		t.Error("edit_file followed a symlink out of the workspace")
+	}
+	if err := invokeJSON(t, copyTool, TransferInput{Source: "etc-link/secret.txt", Destination: "copy.txt"}, nil); err == nil {
+		t.Error("copy_file read through a symlinked directory")
+	}
+	if readTestFile(t, filepath.Join(outside, "secret.txt")) != "secret" {
+		t.Fatal("outside file was modified")
+	}
+
+	// Links that stay inside the workspace keep working
+	var out ReadFileOutput
+	if err := invokeJSON(t, readTool, ReadFileInput{Path: "docs-link/note.md"}, &out); err != nil || out.Content != "note" {
+		t.Fatalf("expected read through internal symlink, got %+v, %v", out, err)
+	}
+}
+
+func TestOpenNoFollow_RejectsSwappedSymlink(t *testing.T) {
+	ws, outside := newEscapeWorkspace(t)
+	path, err := validatePath(ws, "docs/target.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate the final component being replaced after validation
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), path); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := openNoFollow(path, os.O_RDONLY, 0); err == nil {
+		f.Close()
+		t.Fatal("expected open of swapped symlink to fail")
+	}
+}
+
+func TestGrep_SkipsEscapingSymlinks(t *testing.T) {
+	ws, _ := newEscapeWorkspace(t)
+	got := runGrep(t, ws, GrepInput{Pattern: "secret", FilesOnly: true})
+	if len(got.Files) != 0 {
+		t.Fatalf("expected no matches through symlinks, got %v", got.Files)
+	}
+}
+
+func TestFileTools_ActOnSymlinkItself(t *testing.T) {
+	ws, outside := newEscapeWorkspace(t)
+	deleteTool, _ := NewDeleteFileTool(ws)
+	moveTool, _ := NewMoveFileTool(ws)
+	statTool, _ := NewStatTool(ws)
+
+	var st StatOutput
+	if err := invokeJSON(t, statTool, PathInput{Path: "docs-link"}, &st); err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if st.Type != "symlink" || st.Path != "docs-link" || st.LinkTarget != filepath.Join(ws, "docs") {
+		t.Fatalf("expected the link itself, got %+v", st)
+	}
+	if err := invokeJSON(t, statTool, PathInput{Path: "etc-link"}, &st); err != nil || st.Type != "symlink" {
+		t.Fatalf("expected stat of an escaping link to describe the link, got %+v, %v", st, err)
+	}
+
+	if err := invokeJSON(t, moveTool, TransferInput{Source: "docs-link", Destination: "moved-link"}, nil); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	if info, err := os.Lstat(filepath.Join(ws, "moved-link")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected the link to be moved, got %v, %v", info, err)
+	}
+	if readTestFile(t, filepath.Join(ws, "docs", "note.md")) != "note" {
+		t.Fatal("link target must stay in place")
+	}
+
+	var out DeleteFileOutput
+	if err := invokeJSON(t, deleteTool, PathInput{Path: "moved-link"}, &out); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(ws, "moved-link")); !os.IsNotExist(err) {
+		t.Fatal("expected the link to be removed")
+	}
+	if readTestFile(t, filepath.Join(ws, "docs", "note.md")) != "note" {
+		t.Fatal("deleting a link must not trash its target")
+	}
+
+	if err := invokeJSON(t, deleteTool, PathInput{Path: "etc-link"}, nil); err != nil {
+		t.Fatalf("delete of escaping link failed: %v", err)
+	}
+	if readTestFile(t, filepath.Join(outside, "secret.txt")) != "secret" {
+		t.Fatal("deleting a link must not touch the outside directory")
+	}
+	if err := invokeJSON(t, deleteTool, PathInput{Path: "rel-escape/secret.txt"}, nil); err == nil {
+		t.Fatal("expected delete through an escaping link to be denied")
+	}
+}
@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/webfetch"
+	"github.com/MEKXH/golem/internal/websearch"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+const (
+	defaultSearchResults = 5
+	maxSearchResults     = 20
+	defaultFetchChars    = 10000
+	maxFetchCacheEntries = 32
+)
+
+// WebSearchInput parameters for web_search tool
+type WebSearchInput struct {
+	Query string `json:"query" jsonschema:"required,description=Search query"`
+	Count int    `json:"count" jsonschema:"description=Number of results (default from config, max 20)"`
+}
+
+// WebSearchOutput result of web_search tool
+type WebSearchOutput struct {
+	Query   string             `json:"query"`
+	Results []websearch.Result `json:"results"`
+}
+
+// NewWebSearchTool creates the web_search tool backed by searcher.
+// maxResults is the default result count when the model does not ask for one.
+func NewWebSearchTool(searcher websearch.Searcher, maxResults int) (tool.InvokableTool, error) {
+	if maxResults <= 0 {
+		maxResults = defaultSearchResults
+	}
+	run := func(ctx context.Context, input *WebSearchInput) (*WebSearchOutput, error) {
+		query := strings.TrimSpace(input.Query)
+		if query == "" {
+			return nil, fmt.Errorf("query is required")
+		}
+		count := input.Count
+		if count <= 0 {
+			count = maxResults
+		}
+		count = min(count, maxSearchResults)
+
+		results, err := searcher.Search(ctx, query, count)
+		if err != nil {
+			return nil, err
+		}
+		if results == nil {
+			results = []websearch.Result{}
+		}
+		return &WebSearchOutput{Query: query, Results: results}, nil
+	}
+	return utils.InferTool("web_search", "Search the web and return titles, URLs and snippets", run)
+}
+
+// WebFetchInput parameters for web_fetch tool
+type WebFetchInput struct {
+	URL      string `json:"url" jsonschema:"required,description=http or https URL to read"`
+	Offset   int    `json:"offset" jsonschema:"description=Character offset to continue reading a long page"`
+	MaxChars int    `json:"max_chars" jsonschema:"description=Maximum characters to return (default 10000)"`
+}
+
+// WebFetchOutput result of web_fetch tool
+type WebFetchOutput struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	ContentType string `json:"content_type"`
+	Content     string `json:"content"`
+	TotalChars  int    `json:"total_chars"`
+	NextOffset  int    `json:"next_offset,omitempty"`
+	Truncated   bool   `json:"truncated,omitempty"`
+	Cached      bool   `json:"cached,omitempty"`
+}
+
+// FetchCache keeps fetched pages per session so paging through a long
+// document or revisiting a page does not download it again
+type FetchCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]map[string]fetchEntry
+}
+
+type fetchEntry struct {
+	page    *webfetch.Page
+	fetched time.Time
+}
+
+// NewFetchCache creates a cache whose entries expire after ttl
+func NewFetchCache(ttl time.Duration) *FetchCache {
+	return &FetchCache{ttl: ttl, sessions: make(map[string]map[string]fetchEntry)}
+}
+
+func (c *FetchCache) get(session, url string) (*webfetch.Page, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.sessions[session][url]
+	if !ok || time.Since(entry.fetched) > c.ttl {
+		return nil, false
+	}
+	return entry.page, true
+}
+
+func (c *FetchCache) put(session, url string, page *webfetch.Page) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := c.sessions[session]
+	if entries == nil {
+		entries = make(map[string]fetchEntry)
+		c.sessions[session] = entries
+	}
+	if len(entries) >= maxFetchCacheEntries {
+		oldest := ""
+		for u, e := range entries {
+			if oldest == "" || e.fetched.Before(entries[oldest].fetched) {
+				oldest = u
+			}
+		}
+		delete(entries, oldest)
+	}
+	entries[url] = fetchEntry{page: page, fetched: time.Now()}
+}
+
+// EndSession drops the pages cached for a session
+func (c *FetchCache) EndSession(session string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sessions, session)
+}
+
+// NewWebFetchTool creates the web_fetch tool. cache may be nil to always
+// download.
+func NewWebFetchTool(fetcher *webfetch.Fetcher, cache *FetchCache) (tool.InvokableTool, error) {
+	run := func(ctx context.Context, input *WebFetchInput) (*WebFetchOutput, error) {
+		url := strings.TrimSpace(input.URL)
+		if url == "" {
+			return nil, fmt.Errorf("url is required")
+		}
+		session := ""
+		if inv, ok := InvocationFromContext(ctx); ok {
+			session = inv.SessionKey()
+		}
+
+		var page *webfetch.Page
+		cached := false
+		if cache != nil {
+			page, cached = cache.get(session, url)
+		}
+		if !cached {
+			var err error
+			if page, err = fetcher.Fetch(ctx, url); err != nil {
+				return nil, err
+			}
+			if cache != nil {
+				cache.put(session, url, page)
+			}
+		}
+
+		content := []rune(page.Content)
+		start := min(max(input.Offset, 0), len(content))
+		limit := input.MaxChars
+		if limit <= 0 {
+			limit = defaultFetchChars
+		}
+		end := min(start+limit, len(content))
+
+		out := &WebFetchOutput{
+			URL:         page.URL,
+			Title:       page.Title,
+			ContentType: page.ContentType,
+			Content:     string(content[start:end]),
+			TotalChars:  len(content),
+			Truncated:   page.Truncated,
+			Cached:      cached,
+		}
+		if end < len(content) {
+			out.NextOffset = end
+		}
+		return out, nil
+	}
+	return utils.InferTool("web_fetch",
+		"Read a web page, PDF or text file as Markdown; use next_offset to continue long documents", run)
+}
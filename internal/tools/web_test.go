@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/webfetch"
+	"github.com/MEKXH/golem/internal/websearch"
+)
+
+type fakeSearcher struct {
+	query string
+	count int
+	err   error
+}
+
+func (f *fakeSearcher) Name() string { return "fake" }
+
+func (f *fakeSearcher) Search(ctx context.Context, query string, count int) ([]websearch.Result, error) {
+	f.query, f.count = query, count
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []websearch.Result{{Title: "Go", URL: "https://go.dev/", Snippet: "Go language"}}, nil
+}
+
+func TestWebSearchTool(t *testing.T) {
+	searcher := &fakeSearcher{}
+	searchTool, err := NewWebSearchTool(searcher, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out WebSearchOutput
+	if err := invokeJSON(t, searchTool, WebSearchInput{Query: "  golang "}, &out); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if searcher.query != "golang" || searcher.count != 3 {
+		t.Fatalf("expected default count 3 for trimmed query, got %q %d", searcher.query, searcher.count)
+	}
+	if len(out.Results) != 1 || out.Results[0].URL != "https://go.dev/" {
+		t.Fatalf("unexpected output %+v", out)
+	}
+
+	if err := invokeJSON(t, searchTool, WebSearchInput{Query: "go", Count: 100}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if searcher.count != maxSearchResults {
+		t.Fatalf("expected count capped at %d, got %d", maxSearchResults, searcher.count)
+	}
+
+	if err := invokeJSON(t, searchTool, WebSearchInput{}, nil); err == nil {
+		t.Fatal("expected empty query to be rejected")
+	}
+	searcher.err = errors.New("rate limited")
+	if err := invokeJSON(t, searchTool, WebSearchInput{Query: "go"}, nil); err == nil {
+		t.Fatal("expected backend error to surface")
+	}
+}
+
+func TestWebFetchTool_PagesAndCachesPerSession(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("héllo world"))
+	}))
+	defer srv.Close()
+
+	cache := NewFetchCache(time.Minute)
+	fetchTool, err := NewWebFetchTool(webfetch.New(webfetch.Options{AllowPrivate: true}), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := func(ctx context.Context, input WebFetchInput) WebFetchOutput {
+		t.Helper()
+		args, _ := json.Marshal(input)
+		result, err := fetchTool.InvokableRun(ctx, string(args))
+		if err != nil {
+			t.Fatalf("fetch failed: %v", err)
+		}
+		var out WebFetchOutput
+		if err := json.Unmarshal([]byte(result), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	alice := WithInvocation(context.Background(), Invocation{Channel: "telegram", ChatID: "1"})
+	bob := WithInvocation(context.Background(), Invocation{Channel: "telegram", ChatID: "2"})
+
+	first := call(alice, WebFetchInput{URL: srv.URL, MaxChars: 5})
+	if first.Content != "héllo" || first.TotalChars != 11 || first.NextOffset != 5 || first.Cached {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+	rest := call(alice, WebFetchInput{URL: srv.URL, Offset: first.NextOffset})
+	if rest.Content != " world" || rest.NextOffset != 0 || !rest.Cached {
+		t.Fatalf("unexpected continuation %+v", rest)
+	}
+	if hits.Load() != 1 {
+		t.Fatalf("expected one download for the session, got %d", hits.Load())
+	}
+
+	if other := call(bob, WebFetchInput{URL: srv.URL}); other.Cached || hits.Load() != 2 {
+		t.Fatalf("expected other session to download again, got %+v after %d hits", other, hits.Load())
+	}
+	cache.EndSession("telegram:1")
+	if again := call(alice, WebFetchInput{URL: srv.URL}); again.Cached {
+		t.Fatal("expected ended session cache to be dropped")
+	}
+
+	if err := invokeJSON(t, fetchTool, WebFetchInput{URL: ""}, nil); err == nil {
+		t.Fatal("expected empty url to be rejected")
+	}
+}
+
+func TestWebFetchTool_BlocksPrivateByDefault(t *testing.T) {
+	fetchTool, _ := NewWebFetchTool(webfetch.New(webfetch.Options{}), nil)
+	err := invokeJSON(t, fetchTool, WebFetchInput{URL: "http://127.0.0.1:1/"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "private") {
+		t.Fatalf("expected loopback to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package webfetch downloads web pages and converts them to text the model
+// can read: HTML is reduced to its main content as Markdown, PDFs to their
+// text. Private network addresses are refused unless explicitly allowed.
+package webfetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	DefaultMaxBytes = 5 << 20
+	DefaultTimeout  = 30 * time.Second
+	maxRedirects    = 5
+	userAgent       = "Mozilla/5.0 (compatible; golem/1.0)"
+)
+
+// ErrBlockedAddress is returned when a URL resolves to a private, loopback
+// or otherwise internal address
+var ErrBlockedAddress = errors.New("address is private, loopback or link-local")
+
+// Options configure a fetcher
+type Options struct {
+	MaxBytes     int64
+	Timeout      time.Duration
+	AllowPrivate bool
+}
+
+// Page is a downloaded document converted to text
+type Page struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	ContentType string `json:"content_type"`
+	Content     string `json:"content"`
+	Truncated   bool   `json:"truncated,omitempty"`
+}
+
+// Fetcher downloads URLs with size and time limits
+type Fetcher struct {
+	opts   Options
+	client *http.Client
+}
+
+// New creates a fetcher. Addresses are checked when connecting, so
+// redirects and DNS answers cannot reach internal hosts either.
+func New(opts Options) *Fetcher {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !opts.AllowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || blockedIP(ip) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+			}
+			return nil
+		}
+		// A proxy would connect on our behalf and bypass the address check
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+	return &Fetcher{opts: opts, client: client}
+}
+
+var blockedNets = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"64:ff9b::/96",  // NAT64, which can embed private IPv4 addresses
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// blockedIP reports whether ip is internal to the host or its networks
+func blockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch downloads rawURL and converts the body by content type
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported url scheme %q; use http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("url %q has no host", rawURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/pdf,text/plain;q=0.9,*/*;q=0.5")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("fetch %s: %s", u.Redacted(), resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.opts.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{URL: resp.Request.URL.String()}
+	if int64(len(body)) > f.opts.MaxBytes {
+		body = body[:f.opts.MaxBytes]
+		page.Truncated = true
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		contentType = http.DetectContentType(body)
+		mediaType, _, _ = mime.ParseMediaType(contentType)
+	}
+	page.ContentType = mediaType
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		doc, err := html.Parse(decodeCharset(body, contentType))
+		if err != nil {
+			return nil, fmt.Errorf("parse html: %w", err)
+		}
+		page.Title, page.Content = Markdown(doc, resp.Request.URL)
+	case mediaType == "application/pdf":
+		if page.Truncated {
+			return nil, fmt.Errorf("pdf is larger than the %d byte limit", f.opts.MaxBytes)
+		}
+		if page.Content, err = PDFText(body); err != nil {
+			return nil, err
+		}
+	case strings.HasPrefix(mediaType, "text/") || isTextual(mediaType):
+		text, err := io.ReadAll(decodeCharset(body, contentType))
+		if err != nil {
+			return nil, err
+		}
+		page.Content = string(text)
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+	return page, nil
+}
+
+func isTextual(mediaType string) bool {
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-yaml", "application/yaml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+// decodeCharset converts the body to UTF-8 using the declared or sniffed charset
+func decodeCharset(body []byte, contentType string) io.Reader {
+	r, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		return bytes.NewReader(body)
+	}
+	return r
+}
@@ -0,0 +1,135 @@
+package webfetch
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetch_BlocksPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+
+	f := New(Options{})
+	_, err := f.Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("expected loopback to be blocked, got %v", err)
+	}
+
+	for _, u := range []string{"file:///etc/passwd", "ftp://example.com/", "http://"} {
+		if _, err := f.Fetch(context.Background(), u); err == nil {
+			t.Errorf("expected %q to be rejected", u)
+		}
+	}
+}
+
+func TestFetch_BlocksRedirectToPrivateAddress(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("metadata"))
+	}))
+	defer internal.Close()
+	public := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer public.Close()
+
+	// Pretend public.test is an external host served by the redirecting
+	// server; every other dial goes through the checked dialer
+	f := New(Options{})
+	transport := f.client.Transport.(*http.Transport)
+	checked := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if strings.HasPrefix(addr, "public.test:") {
+			return (&net.Dialer{}).DialContext(ctx, network, public.Listener.Addr().String())
+		}
+		return checked(ctx, network, addr)
+	}
+
+	if _, err := f.Fetch(context.Background(), "http://public.test/"); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("expected redirect to a private host to be blocked, got %v", err)
+	}
+}
+
+func TestBlockedIP(t *testing.T) {
+	blocked := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1", "64:ff9b::a00:1"}
+	for _, s := range blocked {
+		if !blockedIP(net.ParseIP(s)) {
+			t.Errorf("expected %s to be blocked", s)
+		}
+	}
+	for _, s := range []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"} {
+		if blockedIP(net.ParseIP(s)) {
+			t.Errorf("expected %s to be allowed", s)
+		}
+	}
+}
+
+func TestFetch_ContentTypes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>Doc</title></head><body>
+<nav><a href="/">Home</a></nav><main><h1>Hello</h1><p>See <a href="/other">other</a>.</p></main>
+<footer>Copyright</footer></body></html>`))
+	})
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=iso-8859-1")
+		_, _ = w.Write([]byte("caf\xe9"))
+	})
+	mux.HandleFunc("/pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write(testPDF(t))
+	})
+	mux.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("\x89PNG"))
+	})
+	mux.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := New(Options{AllowPrivate: true, MaxBytes: 4096, Timeout: 200 * time.Millisecond})
+	ctx := context.Background()
+
+	page, err := f.Fetch(ctx, srv.URL+"/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantContent := "# Hello\n\nSee [other](" + srv.URL + "/other)."
+	if page.Title != "Doc" || page.ContentType != "text/html" || page.Content != wantContent {
+		t.Fatalf("unexpected html page %+v", page)
+	}
+
+	if page, err = f.Fetch(ctx, srv.URL+"/latin1"); err != nil || page.Content != "café" {
+		t.Fatalf("expected charset conversion, got %+v, %v", page, err)
+	}
+	if page, err = f.Fetch(ctx, srv.URL+"/pdf"); err != nil || !strings.Contains(page.Content, "Hello, PDF!") {
+		t.Fatalf("expected pdf text, got %+v, %v", page, err)
+	}
+	if _, err = f.Fetch(ctx, srv.URL+"/binary"); err == nil || !strings.Contains(err.Error(), "unsupported content type") {
+		t.Fatalf("expected unsupported content type, got %v", err)
+	}
+	if _, err = f.Fetch(ctx, srv.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+	if _, err = f.Fetch(ctx, srv.URL+"/slow"); err == nil {
+		t.Fatal("expected timeout")
+	}
+
+	small := New(Options{AllowPrivate: true, MaxBytes: 10})
+	if page, err = small.Fetch(ctx, srv.URL+"/big"); err != nil || !page.Truncated || len(page.Content) != 10 {
+		t.Fatalf("expected truncated body, got %+v, %v", page, err)
+	}
+}
@@ -0,0 +1,402 @@
+package webfetch
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skipTags never contain readable content
+var skipTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Iframe: true, atom.Form: true, atom.Button: true,
+	atom.Input: true, atom.Select: true, atom.Textarea: true, atom.Nav: true,
+	atom.Aside: true, atom.Footer: true, atom.Head: true, atom.Dialog: true,
+}
+
+// boilerplateRoles and boilerplateNames mark site chrome around the content
+var (
+	boilerplateRoles = map[string]bool{
+		"navigation": true, "banner": true, "contentinfo": true,
+		"complementary": true, "search": true, "dialog": true,
+	}
+	boilerplateNames = regexp.MustCompile(`(?i)(^|[\s_-])(nav|navbar|menu|sidebar|footer|cookies?|consent|banner|advert|ads|share|social|related|breadcrumbs?|comments?|popup|modal|newsletter)($|[\s_-])`)
+)
+
+// Markdown converts an HTML document to Markdown. Only the main content is
+// kept: a single <article>, <main> or role=main element when present,
+// otherwise the body without navigation, sidebars and footers.
+func Markdown(doc *html.Node, base *url.URL) (title, content string) {
+	title = strings.TrimSpace(collapseSpace(textOf(findFirst(doc, func(n *html.Node) bool { return n.DataAtom == atom.Title }))))
+
+	root := mainContent(doc)
+	w := &mdWriter{base: base, inMain: root != doc && root.DataAtom != atom.Body}
+	w.children(root)
+	content = cleanMarkdown(w.b.String())
+
+	if title == "" {
+		title = strings.TrimSpace(collapseSpace(textOf(findFirst(root, func(n *html.Node) bool { return n.DataAtom == atom.H1 }))))
+	}
+	return title, content
+}
+
+func mainContent(doc *html.Node) *html.Node {
+	if articles := findAll(doc, func(n *html.Node) bool { return n.DataAtom == atom.Article }); len(articles) == 1 {
+		return articles[0]
+	}
+	if n := findFirst(doc, func(n *html.Node) bool {
+		return n.DataAtom == atom.Main || attr(n, "role") == "main"
+	}); n != nil {
+		return n
+	}
+	if n := findFirst(doc, func(n *html.Node) bool { return n.DataAtom == atom.Body }); n != nil {
+		return n
+	}
+	return doc
+}
+
+type mdWriter struct {
+	b      strings.Builder
+	base   *url.URL
+	prefix string // written at the start of every line: quotes and list indentation
+	inMain bool   // headers belong to the content rather than the site
+}
+
+func (w *mdWriter) atLineStart() bool {
+	s := w.b.String()
+	return s == "" || strings.HasSuffix(s, "\n")
+}
+
+func (w *mdWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	if w.atLineStart() {
+		w.b.WriteString(w.prefix)
+	}
+	w.b.WriteString(s)
+}
+
+func (w *mdWriter) text(s string) {
+	s = collapseSpace(s)
+	if s == " " && (w.atLineStart() || strings.HasSuffix(w.b.String(), " ")) {
+		return
+	}
+	if w.atLineStart() {
+		s = strings.TrimLeft(s, " ")
+	}
+	w.write(s)
+}
+
+func (w *mdWriter) newline() {
+	if !w.atLineStart() {
+		w.b.WriteString("\n")
+	}
+}
+
+// block separates blocks with a blank line
+func (w *mdWriter) block() {
+	w.newline()
+	if s := w.b.String(); s != "" && !strings.HasSuffix(s, "\n\n") {
+		w.b.WriteString("\n")
+	}
+}
+
+func (w *mdWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+// inline renders children on their own and returns the text on one line
+func (w *mdWriter) inline(n *html.Node) string {
+	sub := &mdWriter{base: w.base, inMain: w.inMain}
+	sub.children(n)
+	return strings.TrimSpace(collapseSpace(sub.b.String()))
+}
+
+func (w *mdWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		w.children(n)
+		return
+	}
+	if w.skip(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		if text := w.inline(n); text != "" {
+			w.block()
+			w.write(strings.Repeat("#", int(n.Data[1]-'0')) + " " + text)
+			w.block()
+		}
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header,
+		atom.Figure, atom.Figcaption, atom.Dl, atom.Dd, atom.Address, atom.Details, atom.Summary:
+		w.block()
+		w.children(n)
+		w.block()
+	case atom.Dt:
+		w.block()
+		w.write("**" + w.inline(n) + "**")
+		w.newline()
+	case atom.Br:
+		w.b.WriteString("\n")
+	case atom.Hr:
+		w.block()
+		w.write("---")
+		w.block()
+	case atom.Pre:
+		w.codeBlock(n)
+	case atom.Code, atom.Kbd, atom.Samp:
+		if text := textOf(n); strings.TrimSpace(text) != "" {
+			w.write("`" + strings.TrimSpace(text) + "`")
+		}
+	case atom.Strong, atom.B:
+		w.wrap(n, "**")
+	case atom.Em, atom.I:
+		w.wrap(n, "_")
+	case atom.Del, atom.S:
+		w.wrap(n, "~~")
+	case atom.A:
+		w.link(n)
+	case atom.Img:
+		alt := collapseSpace(attr(n, "alt"))
+		if src := w.resolve(attr(n, "src")); strings.TrimSpace(alt) != "" && src != "" {
+			w.write("![" + strings.TrimSpace(alt) + "](" + src + ")")
+		}
+	case atom.Ul, atom.Ol:
+		w.list(n)
+	case atom.Blockquote:
+		w.block()
+		saved := w.prefix
+		w.prefix += "> "
+		w.children(n)
+		w.newline()
+		w.prefix = saved
+		w.block()
+	case atom.Table:
+		w.table(n)
+	default:
+		w.children(n)
+	}
+}
+
+// skip drops non-content elements and site chrome outside the main content
+func (w *mdWriter) skip(n *html.Node) bool {
+	if skipTags[n.DataAtom] || attr(n, "hidden") != "" || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	if boilerplateRoles[attr(n, "role")] {
+		return true
+	}
+	if n.DataAtom == atom.Header && !w.inMain {
+		return true
+	}
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Html || n.DataAtom == atom.Main || n.DataAtom == atom.Article {
+		return false
+	}
+	return boilerplateNames.MatchString(attr(n, "class")) || boilerplateNames.MatchString(attr(n, "id"))
+}
+
+func (w *mdWriter) wrap(n *html.Node, marker string) {
+	if text := w.inline(n); text != "" {
+		w.write(marker + text + marker)
+	}
+}
+
+func (w *mdWriter) link(n *html.Node) {
+	text := w.inline(n)
+	if text == "" {
+		return
+	}
+	href := attr(n, "href")
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		w.write(text)
+		return
+	}
+	w.write("[" + text + "](" + w.resolve(href) + ")")
+}
+
+func (w *mdWriter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if w.base != nil {
+		u = w.base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+func (w *mdWriter) codeBlock(n *html.Node) {
+	lang := ""
+	if code := findFirst(n, func(c *html.Node) bool { return c.DataAtom == atom.Code }); code != nil {
+		for _, class := range strings.Fields(attr(code, "class")) {
+			if l, ok := strings.CutPrefix(class, "language-"); ok {
+				lang = l
+				break
+			}
+		}
+	}
+	w.block()
+	w.write("```" + lang + "\n")
+	body := strings.Trim(textOf(n), "\n")
+	for _, line := range strings.Split(body, "\n") {
+		w.write(line + "\n")
+	}
+	w.write("```")
+	w.block()
+}
+
+func (w *mdWriter) list(n *html.Node) {
+	nested := w.prefix != "" && !strings.HasSuffix(w.prefix, "> ")
+	if nested {
+		w.newline()
+	} else {
+		w.block()
+	}
+	ordered := n.DataAtom == atom.Ol
+	index := 1
+	saved := w.prefix
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li || w.skip(c) {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+		w.newline()
+		w.write(marker)
+		w.prefix = saved + strings.Repeat(" ", len(marker))
+		w.children(c)
+		w.prefix = saved
+		w.newline()
+	}
+	if !nested {
+		w.block()
+	}
+}
+
+func (w *mdWriter) table(n *html.Node) {
+	var rows [][]string
+	for _, tr := range findAll(n, func(c *html.Node) bool { return c.DataAtom == atom.Tr }) {
+		var cells []string
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if c.DataAtom == atom.Td || c.DataAtom == atom.Th {
+				cells = append(cells, strings.ReplaceAll(w.inline(c), "|", `\|`))
+			}
+		}
+		if len(cells) > 0 {
+			rows = append(rows, cells)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r))
+	}
+	w.block()
+	for i, r := range rows {
+		for len(r) < width {
+			r = append(r, "")
+		}
+		w.write("| " + strings.Join(r, " | ") + " |")
+		w.newline()
+		if i == 0 {
+			w.write("|" + strings.Repeat(" --- |", width))
+			w.newline()
+		}
+	}
+	w.block()
+}
+
+var (
+	spacePattern     = regexp.MustCompile(`[\s\p{Zs}]+`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+func collapseSpace(s string) string {
+	return spacePattern.ReplaceAllString(s, " ")
+}
+
+// cleanMarkdown trims trailing spaces and repeated blank lines outside code fences
+func cleanMarkdown(s string) string {
+	lines := strings.Split(s, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimLeft(line, " >"), "```") {
+			inFence = !inFence
+		}
+		if !inFence {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+	}
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func textOf(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (c.DataAtom == atom.Script || c.DataAtom == atom.Style) {
+			continue
+		}
+		b.WriteString(textOf(c))
+	}
+	return b.String()
+}
+
+func findFirst(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && match(n) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findFirst(c, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func findAll(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	if n.Type == html.ElementNode && match(n) {
+		found = append(found, n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		found = append(found, findAll(c, match)...)
+	}
+	return found
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
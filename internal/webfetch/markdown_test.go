@@ -0,0 +1,90 @@
+package webfetch
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func markdownOf(t *testing.T, page string) (string, string) {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := url.Parse("https://example.com/blog/post")
+	return Markdown(doc, base)
+}
+
+func TestMarkdown_MainContent(t *testing.T) {
+	page := `<html><head><title> Post  Title </title><style>p{}</style></head><body>
+<header class="site-header"><a href="/">Site</a></header>
+<div class="sidebar">Popular posts</div>
+<article>
+  <header><h1>Post <em>Title</em></h1></header>
+  <p>First   paragraph with <strong>bold</strong>, <code>code()</code> and a
+     <a href="../about">relative link</a>.<br>Second line.</p>
+  <script>alert(1)</script>
+  <ul><li>One</li><li>Two<ol><li>Nested</li></ol></li></ul>
+  <blockquote><p>Quoted</p></blockquote>
+  <pre><code class="language-go">func main() {
+	println("hi")
+}</code></pre>
+  <table><tr><th>Name</th><th>Value</th></tr><tr><td>a|b</td><td>1</td></tr></table>
+  <img src="/img.png" alt="Diagram"><img src="data:image/png;base64,xx" alt="inline">
+  <div class="share-buttons">Share this</div>
+</article>
+<footer>Copyright</footer>
+</body></html>`
+
+	title, content := markdownOf(t, page)
+	if title != "Post Title" {
+		t.Errorf("unexpected title %q", title)
+	}
+	want := strings.Join([]string{
+		"# Post _Title_",
+		"",
+		"First paragraph with **bold**, `code()` and a [relative link](https://example.com/about).",
+		"Second line.",
+		"",
+		"- One",
+		"- Two",
+		"  1. Nested",
+		"",
+		"> Quoted",
+		"",
+		"```go",
+		"func main() {",
+		"\tprintln(\"hi\")",
+		"}",
+		"```",
+		"",
+		"| Name | Value |",
+		"| --- | --- |",
+		`| a\|b | 1 |`,
+		"",
+		"![Diagram](https://example.com/img.png)",
+	}, "\n")
+	if content != want {
+		t.Fatalf("unexpected markdown:\n%s\n--- want ---\n%s", content, want)
+	}
+}
+
+func TestMarkdown_BodyFallbackDropsChrome(t *testing.T) {
+	page := `<html><body>
+<header><nav>Menu</nav><p>Site tagline</p></header>
+<div id="cookie-banner">We use cookies</div>
+<div class="content"><h2>Section</h2><p>Body text</p></div>
+<div role="complementary">Ads</div>
+</body></html>`
+
+	title, content := markdownOf(t, page)
+	if title != "" {
+		t.Errorf("expected no title, got %q", title)
+	}
+	if content != "## Section\n\nBody text" {
+		t.Fatalf("unexpected markdown %q", content)
+	}
+}
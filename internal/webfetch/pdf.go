@@ -0,0 +1,405 @@
+package webfetch
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+const maxPDFStream = 16 << 20
+
+// PDFText extracts the text drawn by the content streams of a PDF. It is a
+// best-effort reader: uncompressed and Flate streams are supported, and fonts
+// with custom encodings or scanned pages yield no text.
+func PDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", errors.New("not a pdf document")
+	}
+
+	var out strings.Builder
+	for _, stream := range pdfStreams(data) {
+		if text := contentText(stream); strings.TrimSpace(text) != "" {
+			out.WriteString(text)
+			out.WriteString("\n\n")
+		}
+	}
+	text := cleanPDFText(out.String())
+	if text == "" {
+		return "", errors.New("pdf has no extractable text (it may be scanned or use embedded font encodings)")
+	}
+	return text, nil
+}
+
+// pdfStreams returns the decoded body of every stream it can decode
+func pdfStreams(data []byte) [][]byte {
+	var streams [][]byte
+	rest := data
+	offset := 0
+	for {
+		i := bytes.Index(rest, []byte("stream"))
+		if i < 0 {
+			return streams
+		}
+		start := offset + i
+		next := i + len("stream")
+		rest, offset = rest[next:], offset+next
+
+		// Skip "endstream" and words that merely end in "stream"
+		if bytes.HasSuffix(data[:start], []byte("end")) {
+			continue
+		}
+		body := data[start+len("stream"):]
+		switch {
+		case bytes.HasPrefix(body, []byte("\r\n")):
+			body = body[2:]
+		case bytes.HasPrefix(body, []byte("\n")), bytes.HasPrefix(body, []byte("\r")):
+			body = body[1:]
+		default:
+			continue
+		}
+		end := bytes.Index(body, []byte("endstream"))
+		if end < 0 {
+			return streams
+		}
+		body = body[:end]
+
+		dict := data[:start]
+		if obj := bytes.LastIndex(dict, []byte(" obj")); obj >= 0 {
+			dict = dict[obj:]
+		}
+		if decoded, ok := decodeStream(dict, body); ok {
+			streams = append(streams, decoded)
+		}
+	}
+}
+
+// nonContentStreams mark images, embedded fonts and cross-reference data
+var nonContentStreams = [][]byte{
+	[]byte("/Image"), []byte("/Length1"), []byte("/Length2"), []byte("/Type1C"),
+	[]byte("/CIDFontType0C"), []byte("/OpenType"), []byte("/XRef"), []byte("/Metadata"),
+}
+
+func decodeStream(dict, body []byte) ([]byte, bool) {
+	for _, marker := range nonContentStreams {
+		if bytes.Contains(dict, marker) {
+			return nil, false
+		}
+	}
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		return body, true
+	}
+	if !bytes.Contains(dict, []byte("/FlateDecode")) || bytes.Contains(dict, []byte("/DCTDecode")) {
+		return nil, false
+	}
+	r, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+	decoded, err := io.ReadAll(io.LimitReader(r, maxPDFStream))
+	if err != nil && len(decoded) == 0 {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// contentText interprets the text operators of a content stream
+func contentText(stream []byte) string {
+	var (
+		out      strings.Builder
+		operands []any
+		inText   bool
+		lineY    float64
+	)
+	lex := &pdfLexer{data: stream}
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		op, isOp := tok.(pdfOperator)
+		if !isOp {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "BT":
+			inText = true
+		case "ET":
+			inText = false
+			out.WriteString("\n")
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if y, _ := operands[len(operands)-1].(float64); y != 0 {
+					out.WriteString("\n")
+				} else if x, _ := operands[len(operands)-2].(float64); x > 0 {
+					out.WriteString(" ")
+				}
+			}
+		case "T*":
+			out.WriteString("\n")
+		case "Tm":
+			// Start a new line only when the text matrix moves vertically
+			if len(operands) >= 6 {
+				if y, _ := operands[len(operands)-1].(float64); y != lineY {
+					out.WriteString("\n")
+					lineY = y
+				}
+			}
+		case "Tj":
+			if inText && len(operands) > 0 {
+				writePDFString(&out, operands[len(operands)-1])
+			}
+		case "'", "\"":
+			if inText && len(operands) > 0 {
+				out.WriteString("\n")
+				writePDFString(&out, operands[len(operands)-1])
+			}
+		case "TJ":
+			if !inText || len(operands) == 0 {
+				break
+			}
+			items, _ := operands[len(operands)-1].([]any)
+			for _, item := range items {
+				switch v := item.(type) {
+				case float64:
+					// Large negative adjustments move right far enough to be a space
+					if v < -200 {
+						out.WriteString(" ")
+					}
+				default:
+					writePDFString(&out, v)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return out.String()
+}
+
+func writePDFString(out *strings.Builder, v any) {
+	s, ok := v.(pdfString)
+	if !ok {
+		return
+	}
+	text := decodePDFString(s)
+	for _, r := range text {
+		if unicode.IsPrint(r) || r == '\n' || r == '\t' {
+			out.WriteRune(r)
+		}
+	}
+}
+
+// decodePDFString handles UTF-16BE strings with a byte order mark; anything
+// else is treated as Latin-1, close enough to PDFDocEncoding for text
+func decodePDFString(s pdfString) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	runes := make([]rune, len(s))
+	for i, b := range s {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func cleanPDFText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+type (
+	pdfString   []byte
+	pdfOperator string
+)
+
+// pdfLexer splits a content stream into numbers, strings, arrays, names
+// and operators
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *pdfLexer) next() (any, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return l.literalString(), true
+	case c == '<' && l.peek(1) == '<':
+		l.pos += 2
+		return pdfOperator("<<"), true
+	case c == '>' && l.peek(1) == '>':
+		l.pos += 2
+		return pdfOperator(">>"), true
+	case c == '<':
+		return l.hexString(), true
+	case c == '[':
+		l.pos++
+		var items []any
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return items, true
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return items, true
+			}
+			item, ok := l.next()
+			if !ok {
+				return items, true
+			}
+			items = append(items, item)
+		}
+	case c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		// Stray delimiter in a malformed stream; an empty operator clears operands
+		l.pos++
+		return pdfOperator(""), true
+	case c == '/':
+		start := l.pos
+		l.pos++
+		l.word()
+		return string(l.data[start:l.pos]), true
+	default:
+		start := l.pos
+		l.word()
+		if l.pos == start {
+			l.pos++
+		}
+		word := string(l.data[start:l.pos])
+		if f, err := strconv.ParseFloat(word, 64); err == nil {
+			return f, true
+		}
+		return pdfOperator(word), true
+	}
+}
+
+func (l *pdfLexer) peek(n int) byte {
+	if l.pos+n < len(l.data) {
+		return l.data[l.pos+n]
+	}
+	return 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+func (l *pdfLexer) word() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) || strings.IndexByte("()<>[]{}/%", c) >= 0 {
+			return
+		}
+		l.pos++
+	}
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++ // opening paren
+	var s []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return s
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// line continuation
+				if e == '\r' && l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		s = append(s, c)
+	}
+	return s
+}
+
+func (l *pdfLexer) hexString() pdfString {
+	l.pos++ // opening angle bracket
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; strings.IndexByte("0123456789abcdefABCDEF", c) >= 0 {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	s := make(pdfString, len(digits)/2)
+	for i := range s {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		s[i] = byte(v)
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package webfetch
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testPDF builds a two page document: one plain and one Flate content stream
+func testPDF(t *testing.T) []byte {
+	t.Helper()
+	page1 := "BT /F1 12 Tf 72 712 Td (Hello, PDF!) Tj 0 -14 Td [(Wor) -10 (ld) -500 (again)] TJ ET"
+	page2 := "BT /F1 12 Tf 1 0 0 1 72 700 Tm (Escaped \\(parens\\) and \\101) Tj T* <FEFF00e9> Tj ET"
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write([]byte(page2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	b.WriteString("1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	fmt.Fprintf(&b, "4 0 obj << /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(page1), page1)
+	fmt.Fprintf(&b, "5 0 obj << /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+	b.Write(compressed.Bytes())
+	b.WriteString("\nendstream\nendobj\n")
+	b.WriteString("6 0 obj << /Subtype /Image /Length 4 >>\nstream\n(X) Tj\nendstream\nendobj\n")
+	b.WriteString("trailer << /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func TestPDFText(t *testing.T) {
+	text, err := PDFText(testPDF(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Hello, PDF!\nWorld again\n\nEscaped (parens) and A\né"
+	if text != want {
+		t.Fatalf("expected %q, got %q", want, text)
+	}
+
+	if _, err := PDFText([]byte("<html>")); err == nil {
+		t.Fatal("expected non-pdf input to fail")
+	}
+	empty := []byte("%PDF-1.4\n1 0 obj << >> endobj\n%%EOF")
+	if _, err := PDFText(empty); err == nil || !strings.Contains(err.Error(), "no extractable text") {
+		t.Fatalf("expected no text error, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const maxAPIResponse = 4 << 20
+
+// braveSearcher uses the Brave Search web API
+type braveSearcher struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+type braveResponse struct {
+	Web struct {
+		Results []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Description string `json:"description"`
+		} `json:"results"`
+	} `json:"web"`
+}
+
+func (b *braveSearcher) Name() string { return BackendBrave }
+
+func (b *braveSearcher) Search(ctx context.Context, query string, count int) ([]Result, error) {
+	params := url.Values{"q": {query}, "count": {strconv.Itoa(count)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.baseURL+"/web/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", b.apiKey)
+
+	body, err := do(b.client, req, maxAPIResponse)
+	if err != nil {
+		return nil, fmt.Errorf("brave search: %w", err)
+	}
+	var resp braveResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("brave search: invalid response: %w", err)
+	}
+
+	results := make([]Result, 0, len(resp.Web.Results))
+	for _, r := range resp.Web.Results {
+		if len(results) == count {
+			break
+		}
+		results = append(results, Result{Title: cleanText(r.Title), URL: r.URL, Snippet: cleanText(r.Description)})
+	}
+	return results, nil
+}
+
+// searxngSearcher uses the JSON API of a SearXNG instance; the instance
+// must have the json format enabled
+type searxngSearcher struct {
+	baseURL string
+	client  *http.Client
+}
+
+type searxngResponse struct {
+	Results []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+func (s *searxngSearcher) Name() string { return BackendSearXNG }
+
+func (s *searxngSearcher) Search(ctx context.Context, query string, count int) ([]Result, error) {
+	params := url.Values{"q": {query}, "format": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	body, err := do(s.client, req, maxAPIResponse)
+	if err != nil {
+		return nil, fmt.Errorf("searxng search: %w", err)
+	}
+	var resp searxngResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("searxng search: invalid response: %w", err)
+	}
+
+	results := make([]Result, 0, min(len(resp.Results), count))
+	for _, r := range resp.Results {
+		if len(results) == count {
+			break
+		}
+		results = append(results, Result{Title: cleanText(r.Title), URL: r.URL, Snippet: cleanText(r.Content)})
+	}
+	return results, nil
+}
@@ -0,0 +1,128 @@
+package websearch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const maxHTMLResponse = 2 << 20
+
+// duckDuckGoSearcher scrapes the JavaScript-free DuckDuckGo results page.
+// It needs no key but may break when the page markup changes.
+type duckDuckGoSearcher struct {
+	baseURL string
+	client  *http.Client
+}
+
+func (d *duckDuckGoSearcher) Name() string { return BackendDuckDuckGo }
+
+func (d *duckDuckGoSearcher) Search(ctx context.Context, query string, count int) ([]Result, error) {
+	form := url.Values{"q": {query}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.baseURL+"/html/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	body, err := do(d.client, req, maxHTMLResponse)
+	if err != nil {
+		return nil, fmt.Errorf("duckduckgo search: %w", err)
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("duckduckgo search: invalid response: %w", err)
+	}
+	return parseDuckDuckGo(doc, count), nil
+}
+
+// parseDuckDuckGo collects result links and snippets, skipping ads
+func parseDuckDuckGo(doc *html.Node, count int) []Result {
+	var results []Result
+	var walk func(n *html.Node, ad bool)
+	walk = func(n *html.Node, ad bool) {
+		if len(results) == count {
+			return
+		}
+		if n.Type == html.ElementNode {
+			classes := strings.Fields(attr(n, "class"))
+			if hasClass(classes, "result--ad") {
+				ad = true
+			}
+			switch {
+			case ad:
+			case n.Data == "a" && hasClass(classes, "result__a"):
+				if link := resultURL(attr(n, "href")); link != "" {
+					results = append(results, Result{Title: cleanText(textContent(n)), URL: link})
+				}
+				return
+			case hasClass(classes, "result__snippet"):
+				if len(results) > 0 && results[len(results)-1].Snippet == "" {
+					results[len(results)-1].Snippet = cleanText(textContent(n))
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, ad)
+		}
+	}
+	walk(doc, false)
+	return results
+}
+
+// resultURL unwraps DuckDuckGo's redirect links
+func resultURL(href string) string {
+	if strings.HasPrefix(href, "//") {
+		href = "https:" + href
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if target := u.Query().Get("uddg"); target != "" {
+		return target
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(classes []string, name string) bool {
+	for _, c := range classes {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
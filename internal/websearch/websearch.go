@@ -0,0 +1,137 @@
+// Package websearch queries web search engines through interchangeable
+// backends and returns titles, URLs and snippets.
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Backend names
+const (
+	BackendBrave      = "brave"
+	BackendSearXNG    = "searxng"
+	BackendDuckDuckGo = "duckduckgo"
+)
+
+const (
+	defaultTimeout = 15 * time.Second
+	maxErrorBody   = 512
+	userAgent      = "Mozilla/5.0 (compatible; golem/1.0)"
+)
+
+// Result is a single search hit
+type Result struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// Searcher runs queries against one search engine
+type Searcher interface {
+	Name() string
+	Search(ctx context.Context, query string, count int) ([]Result, error)
+}
+
+// Options configure a searcher. BaseURL overrides the engine endpoint and
+// is required for SearXNG, which has no public default instance.
+type Options struct {
+	Backend string
+	APIKey  string
+	BaseURL string
+	Client  *http.Client
+}
+
+// Configured reports whether the options carry what the backend needs, so
+// callers can skip registering search when nothing is set up
+func (o Options) Configured() bool {
+	switch normalizeBackend(o.Backend) {
+	case BackendBrave:
+		return o.APIKey != ""
+	case BackendSearXNG:
+		return o.BaseURL != ""
+	case BackendDuckDuckGo:
+		return true
+	default:
+		return false
+	}
+}
+
+// New creates a searcher for the given options
+func New(opts Options) (Searcher, error) {
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+
+	switch backend := normalizeBackend(opts.Backend); backend {
+	case BackendBrave:
+		if opts.APIKey == "" {
+			return nil, fmt.Errorf("brave search requires an api key")
+		}
+		if baseURL == "" {
+			baseURL = "https://api.search.brave.com/res/v1"
+		}
+		return &braveSearcher{apiKey: opts.APIKey, baseURL: baseURL, client: client}, nil
+	case BackendSearXNG:
+		if baseURL == "" {
+			return nil, fmt.Errorf("searxng search requires a base url")
+		}
+		return &searxngSearcher{baseURL: baseURL, client: client}, nil
+	case BackendDuckDuckGo:
+		if baseURL == "" {
+			baseURL = "https://html.duckduckgo.com"
+		}
+		return &duckDuckGoSearcher{baseURL: baseURL, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown search backend %q", backend)
+	}
+}
+
+func normalizeBackend(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return BackendBrave
+	}
+	return name
+}
+
+// do sends the request and returns the body of a successful response
+func do(client *http.Client, req *http.Request, limit int64) ([]byte, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(body))
+		if len(msg) > maxErrorBody {
+			msg = msg[:maxErrorBody] + "..."
+		}
+		return nil, fmt.Errorf("%s: %s", resp.Status, msg)
+	}
+	return body, nil
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// cleanText strips highlight markup and entities that engines put in snippets
+func cleanText(s string) string {
+	s = html.UnescapeString(tagPattern.ReplaceAllString(s, ""))
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,128 @@
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBrave_Search(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/web/search" || r.Header.Get("X-Subscription-Token") != "key" {
+			http.Error(w, "bad request", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("q") != "golang" || r.URL.Query().Get("count") != "2" {
+			t.Errorf("unexpected query %v", r.URL.Query())
+		}
+		_, _ = w.Write([]byte(`{"web":{"results":[
+			{"title":"The Go <strong>Programming</strong> Language","url":"https://go.dev/","description":"Go is an open source &amp; fast language"},
+			{"title":"Go docs","url":"https://go.dev/doc/","description":"Docs"},
+			{"title":"Extra","url":"https://example.com/","description":"Ignored"}]}}`))
+	}))
+	defer srv.Close()
+
+	s, err := New(Options{Backend: BackendBrave, APIKey: "key", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Search(context.Background(), "golang", 2)
+	if err != nil {
+		t.Fatalf("search error: %v", err)
+	}
+	want := []Result{
+		{Title: "The Go Programming Language", URL: "https://go.dev/", Snippet: "Go is an open source & fast language"},
+		{Title: "Go docs", URL: "https://go.dev/doc/", Snippet: "Docs"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	bad, _ := New(Options{Backend: BackendBrave, APIKey: "wrong", BaseURL: srv.URL})
+	if _, err := bad.Search(context.Background(), "golang", 2); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected status in error, got %v", err)
+	}
+}
+
+func TestSearXNG_Search(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[{"title":"Result","url":"https://example.org/","content":"  some   content "}]}`))
+	}))
+	defer srv.Close()
+
+	s, err := New(Options{Backend: "SearXNG", BaseURL: srv.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Search(context.Background(), "q", 5)
+	if err != nil {
+		t.Fatalf("search error: %v", err)
+	}
+	if len(got) != 1 || got[0].URL != "https://example.org/" || got[0].Snippet != "some content" {
+		t.Fatalf("unexpected results %+v", got)
+	}
+}
+
+func TestDuckDuckGo_Search(t *testing.T) {
+	page := `<html><body>
+<div class="result result--ad"><a class="result__a" href="https://ads.example/">Ad</a>
+  <a class="result__snippet">Buy now</a></div>
+<div class="result results_links"><h2><a class="result__a" href="//duckduckgo.com/l/?uddg=https%3A%2F%2Fgo.dev%2F&amp;rut=x">The <b>Go</b> Language</a></h2>
+  <a class="result__snippet" href="#">Build <b>simple</b> software</a></div>
+<div class="result"><h2><a class="result__a" href="https://pkg.go.dev/">Packages</a></h2></div>
+<div class="result"><h2><a class="result__a" href="https://third.example/">Third</a></h2></div>
+</body></html>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("q") != "go" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	s, err := New(Options{Backend: BackendDuckDuckGo, BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Search(context.Background(), "go", 2)
+	if err != nil {
+		t.Fatalf("search error: %v", err)
+	}
+	want := []Result{
+		{Title: "The Go Language", URL: "https://go.dev/", Snippet: "Build simple software"},
+		{Title: "Packages", URL: "https://pkg.go.dev/"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestOptions_Configured(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want bool
+	}{
+		{Options{}, false},
+		{Options{APIKey: "k"}, true},
+		{Options{Backend: BackendSearXNG}, false},
+		{Options{Backend: BackendSearXNG, BaseURL: "http://localhost:8888"}, true},
+		{Options{Backend: BackendDuckDuckGo}, true},
+		{Options{Backend: "bing", APIKey: "k"}, false},
+	}
+	for _, tc := range tests {
+		if got := tc.opts.Configured(); got != tc.want {
+			t.Errorf("%+v.Configured() = %v, want %v", tc.opts, got, tc.want)
+		}
+	}
+	if _, err := New(Options{Backend: "bing"}); err == nil {
+		t.Fatal("expected unknown backend error")
+	}
+}